@@ -0,0 +1,312 @@
+package ast
+
+import (
+	"cav/golang/types"
+	"fmt"
+	"strings"
+)
+
+type Kind int
+
+const (
+	Label Kind = iota
+	True
+	False
+	Not
+	And
+	Or
+	Implies
+	EX
+	EG
+	EF
+	EU
+	ER
+	AX
+	AG
+	AF
+	AU
+	AR
+	EY
+	EH
+	ES
+	AY
+	AH
+	AS
+	BoundedEF
+	BoundedAF
+	BoundedEG
+	BoundedAG
+	BoundedEU
+	BoundedAU
+	CostEF
+	CostAF
+	CostEG
+	CostAG
+	Call // use of a definition, only exists until definitions have been expanded
+)
+
+var kindNames = map[Kind]string{
+	Label:     "Label",
+	True:      "true",
+	False:     "false",
+	Not:       "NOT",
+	And:       "AND",
+	Or:        "OR",
+	Implies:   "IMPLIES",
+	EX:        "EX",
+	EG:        "EG",
+	EF:        "EF",
+	EU:        "EU",
+	ER:        "ER",
+	AX:        "AX",
+	AG:        "AG",
+	AF:        "AF",
+	AU:        "AU",
+	AR:        "AR",
+	EY:        "EY",
+	EH:        "EH",
+	ES:        "ES",
+	AY:        "AY",
+	AH:        "AH",
+	AS:        "AS",
+	BoundedEF: "EF",
+	BoundedAF: "AF",
+	BoundedEG: "EG",
+	BoundedAG: "AG",
+	BoundedEU: "EU",
+	BoundedAU: "AU",
+	CostEF:    "EF",
+	CostAF:    "AF",
+	CostEG:    "EG",
+	CostAG:    "AG",
+	Call:      "Call",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Arity returns the number of operands a node of this kind has, -1 if it varies
+func (k Kind) Arity() int {
+	switch k {
+	case Label, True, False:
+		return 0
+	case And, Or, Implies, EU, ER, AU, AR, ES, AS, BoundedEU, BoundedAU:
+		return 2
+	case Call:
+		return -1
+	}
+	return 1
+}
+
+// Position is the place in the source a node has been parsed from, the zero value means unknown
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Node is a CTL formula independent of any Kripke structure
+type Node struct {
+	Kind     Kind
+	Name     string    // name of the label or definition, only used by Label and Call nodes
+	Bound    cav.Bound // steps the operator is restricted to, only used by bounded kinds; cost-bounded kinds keep their cost in Upper
+	Operands []*Node
+	Pos      Position
+}
+
+func MakeNode(kind Kind, operands ...*Node) *Node {
+	return &Node{Kind: kind, Operands: operands}
+}
+
+// MakeBounded returns a node of a bounded kind restricted to the given steps
+func MakeBounded(kind Kind, bound cav.Bound, operands ...*Node) *Node {
+	return &Node{Kind: kind, Bound: bound, Operands: operands}
+}
+
+func MakeLabel(name string) *Node {
+	return &Node{Kind: Label, Name: name}
+}
+
+func (n *Node) String() string {
+	switch n.Kind {
+	case Label:
+		return n.Name
+	case True:
+		return "true"
+	case False:
+		return "false"
+	case Not:
+		return "NOT " + n.Operands[0].String()
+	case And, Or, Implies:
+		return fmt.Sprintf("(%s %s %s)", n.Operands[0].String(), n.Kind.String(), n.Operands[1].String())
+	case EX, EG, EF, AX, AG, AF, EY, EH, AY, AH:
+		return n.Kind.String() + " " + n.Operands[0].String()
+	case EU, AU:
+		return fmt.Sprintf("%c[%s U %s]", n.Kind.String()[0], n.Operands[0].String(), n.Operands[1].String())
+	case ER, AR:
+		return fmt.Sprintf("%c[%s R %s]", n.Kind.String()[0], n.Operands[0].String(), n.Operands[1].String())
+	case ES, AS:
+		return fmt.Sprintf("%c[%s S %s]", n.Kind.String()[0], n.Operands[0].String(), n.Operands[1].String())
+	case BoundedEF, BoundedAF, BoundedEG, BoundedAG:
+		return n.Kind.String() + n.Bound.String() + " " + n.Operands[0].String()
+	case BoundedEU, BoundedAU:
+		return fmt.Sprintf("%c[%s U%s %s]", n.Kind.String()[0], n.Operands[0].String(), n.Bound.String(), n.Operands[1].String())
+	case CostEF, CostAF, CostEG, CostAG:
+		return fmt.Sprintf("%s[cost<=%d] %s", n.Kind.String(), n.Bound.Upper, n.Operands[0].String())
+	case Call:
+		operands := make([]string, len(n.Operands))
+		for i, operand := range n.Operands {
+			operands[i] = operand.String()
+		}
+		return fmt.Sprintf("%s(%s)", n.Name, strings.Join(operands, ", "))
+	}
+	return n.Kind.String()
+}
+
+// Clone returns a deep copy of the node
+func (n *Node) Clone() *Node {
+	clone := *n
+	clone.Operands = make([]*Node, len(n.Operands))
+	for i, operand := range n.Operands {
+		clone.Operands[i] = operand.Clone()
+	}
+	return &clone
+}
+
+// Equal reports whether both nodes describe the same formula, ignoring positions
+func (n *Node) Equal(other *Node) bool {
+	if n.Kind != other.Kind || n.Name != other.Name || n.Bound != other.Bound || len(n.Operands) != len(other.Operands) {
+		return false
+	}
+	for i, operand := range n.Operands {
+		if !operand.Equal(other.Operands[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// BindError is returned if a formula refers to a label the Kripke structure does not have
+type BindError struct {
+	Pos  Position
+	Name string
+}
+
+func (e *BindError) Error() string {
+	return fmt.Sprintf("unknown label in formula: %s", e.Name)
+}
+
+// Bind turns the node into a formula which can be checked against the given Kripke structure
+func Bind(n *Node, ks cav.IKripkeStructure) (cav.IFormula, error) {
+	labels := map[string]cav.ILabel{}
+	ks.GetLabels().ForEach(func(label cav.ILabel) {
+		labels[label.String()] = label
+	})
+	return bind(n, ks, labels)
+}
+
+func bind(n *Node, ks cav.IKripkeStructure, labels map[string]cav.ILabel) (cav.IFormula, error) {
+	if n.Kind == Call {
+		return nil, fmt.Errorf("definition has not been expanded: %s", n.String())
+	}
+	if len(n.Operands) != n.Kind.Arity() {
+		return nil, fmt.Errorf("%s expects %d operands, got %d", n.Kind.String(), n.Kind.Arity(), len(n.Operands))
+	}
+
+	operands := make([]cav.IFormula, len(n.Operands))
+	for i, operand := range n.Operands {
+		formula, err := bind(operand, ks, labels)
+		if err != nil {
+			return nil, err
+		}
+		operands[i] = formula
+	}
+
+	switch n.Kind {
+	case Label:
+		label, ok := labels[n.Name]
+		if !ok {
+			return nil, &BindError{n.Pos, n.Name}
+		}
+		return label.MakeLabelFormula(), nil
+	case True:
+		return ks.MakeTrueFormula(), nil
+	case False:
+		return ks.MakeFalseFormula(), nil
+	case Not:
+		return ks.MakeNotFormula(operands[0]), nil
+	case And:
+		return ks.MakeAndFormula(operands[0], operands[1]), nil
+	case Or:
+		return ks.MakeOrFormula(operands[0], operands[1]), nil
+	case Implies:
+		return ks.MakeImpliesFormula(operands[0], operands[1]), nil
+	case EX:
+		return ks.MakeEXFormula(operands[0]), nil
+	case EG:
+		return ks.MakeEGFormula(operands[0]), nil
+	case EF:
+		return ks.MakeEFFormula(operands[0]), nil
+	case EU:
+		return ks.MakeEUFormula(operands[0], operands[1]), nil
+	case ER:
+		return ks.MakeERFormula(operands[0], operands[1]), nil
+	case AX:
+		return ks.MakeAXFormula(operands[0]), nil
+	case AG:
+		return ks.MakeAGFormula(operands[0]), nil
+	case AF:
+		return ks.MakeAFFormula(operands[0]), nil
+	case AU:
+		return ks.MakeAUFormula(operands[0], operands[1]), nil
+	case AR:
+		return ks.MakeARFormula(operands[0], operands[1]), nil
+	case EY:
+		return ks.MakeEYFormula(operands[0]), nil
+	case EH:
+		return ks.MakeEHFormula(operands[0]), nil
+	case ES:
+		return ks.MakeESFormula(operands[0], operands[1]), nil
+	case AY:
+		return ks.MakeAYFormula(operands[0]), nil
+	case AH:
+		return ks.MakeAHFormula(operands[0]), nil
+	case AS:
+		return ks.MakeASFormula(operands[0], operands[1]), nil
+	case BoundedEF:
+		return ks.MakeBoundedEFFormula(n.Bound.Lower, n.Bound.Upper, operands[0]), nil
+	case BoundedAF:
+		return ks.MakeBoundedAFFormula(n.Bound.Lower, n.Bound.Upper, operands[0]), nil
+	case BoundedEG:
+		return ks.MakeBoundedEGFormula(n.Bound.Lower, n.Bound.Upper, operands[0]), nil
+	case BoundedAG:
+		return ks.MakeBoundedAGFormula(n.Bound.Lower, n.Bound.Upper, operands[0]), nil
+	case BoundedEU:
+		return ks.MakeBoundedEUFormula(n.Bound.Lower, n.Bound.Upper, operands[0], operands[1]), nil
+	case BoundedAU:
+		return ks.MakeBoundedAUFormula(n.Bound.Lower, n.Bound.Upper, operands[0], operands[1]), nil
+	case CostEF:
+		return ks.MakeCostEFFormula(n.Bound.Upper, operands[0]), nil
+	case CostAF:
+		return ks.MakeCostAFFormula(n.Bound.Upper, operands[0]), nil
+	case CostEG:
+		return ks.MakeCostEGFormula(n.Bound.Upper, operands[0]), nil
+	case CostAG:
+		return ks.MakeCostAGFormula(n.Bound.Upper, operands[0]), nil
+	}
+	return nil, fmt.Errorf("unknown node kind: %s", n.Kind.String())
+}
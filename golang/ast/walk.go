@@ -0,0 +1,53 @@
+package ast
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the operands of the node with w.
+type Visitor interface {
+	Visit(node *Node) (w Visitor)
+}
+
+// Walk traverses the formula in depth-first order
+func Walk(v Visitor, node *Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+	for _, operand := range node.Operands {
+		Walk(v, operand)
+	}
+}
+
+type inspector func(*Node) bool
+
+func (f inspector) Visit(node *Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect calls f for every node in depth-first order, the operands of a node are skipped if f returns false
+func Inspect(node *Node, f func(*Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// A Rewriter's Rewrite method is invoked for each node by Rewrite after its operands have been rewritten.
+// The returned node replaces the given one.
+type Rewriter interface {
+	Rewrite(node *Node) *Node
+}
+
+type RewriterFunc func(*Node) *Node
+
+func (f RewriterFunc) Rewrite(node *Node) *Node {
+	return f(node)
+}
+
+// Rewrite transforms the formula bottom-up; the given tree is not modified
+func Rewrite(node *Node, r Rewriter) *Node {
+	rewritten := *node
+	rewritten.Operands = make([]*Node, len(node.Operands))
+	for i, operand := range node.Operands {
+		rewritten.Operands[i] = Rewrite(operand, r)
+	}
+	return r.Rewrite(&rewritten)
+}
@@ -0,0 +1,111 @@
+package atl
+
+import (
+	"cav/golang/types"
+	"errors"
+	"fmt"
+)
+
+// ATLFormula is an ATL formula checked on a concurrent game structure
+type ATLFormula struct {
+	kripkeStructure cav.IKripkeStructure
+	formula         *Formula
+	compiled        cav.IFormula
+}
+
+// MakeATLFormula checks that the Kripke structure is a concurrent game structure and that all labels and agents of
+// the formula exist in it, and builds the formula from the fixpoint formulas of the coalition operators
+func MakeATLFormula(ks cav.IKripkeStructure, f *Formula) (*ATLFormula, error) {
+	if !cav.IsGameStructure(ks) {
+		return nil, errors.New("ATL formulas need transitions with moves of agents")
+	}
+	labels := map[string]cav.ILabel{}
+	ks.GetLabels().ForEach(func(label cav.ILabel) {
+		labels[label.String()] = label
+	})
+	for _, name := range f.Labels() {
+		if labels[name] == nil {
+			return nil, fmt.Errorf("unknown label in formula: %s", name)
+		}
+	}
+	agents := map[string]bool{}
+	for _, agent := range ks.GetAgents() {
+		agents[agent] = true
+	}
+	for _, agent := range f.Agents() {
+		if !agents[agent] {
+			return nil, fmt.Errorf("unknown agent in formula: %s", agent)
+		}
+	}
+	if err := checkCoalitions(f); err != nil {
+		return nil, err
+	}
+	return &ATLFormula{ks, f, compile(ks, labels, f)}, nil
+}
+
+func checkCoalitions(f *Formula) error {
+	seen := map[string]bool{}
+	for _, agent := range f.Coalition {
+		if seen[agent] {
+			return fmt.Errorf("duplicate agent in coalition: %s", agent)
+		}
+		seen[agent] = true
+	}
+	for _, sub := range f.Sub {
+		if err := checkCoalitions(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func compile(ks cav.IKripkeStructure, labels map[string]cav.ILabel, f *Formula) cav.IFormula {
+	sub := make([]cav.IFormula, len(f.Sub))
+	for i, s := range f.Sub {
+		sub[i] = compile(ks, labels, s)
+	}
+	switch f.Op {
+	case OpTrue:
+		return ks.MakeTrueFormula()
+	case OpFalse:
+		return ks.MakeFalseFormula()
+	case OpLabel:
+		return labels[f.Name].MakeLabelFormula()
+	case OpNot:
+		return ks.MakeNotFormula(sub[0])
+	case OpAnd:
+		return ks.MakeAndFormula(sub[0], sub[1])
+	case OpOr:
+		return ks.MakeOrFormula(sub[0], sub[1])
+	case OpImplies:
+		return ks.MakeImpliesFormula(sub[0], sub[1])
+	case OpNext:
+		return ks.MakeCoalitionXFormula(f.Coalition, sub[0])
+	case OpGlobal:
+		return ks.MakeCoalitionGFormula(f.Coalition, sub[0])
+	case OpFinally:
+		return ks.MakeCoalitionFFormula(f.Coalition, sub[0])
+	}
+	return ks.MakeCoalitionUFormula(f.Coalition, sub[0], sub[1])
+}
+
+func (f *ATLFormula) Check() cav.ISet[cav.IState] {
+	return f.compiled.Check()
+}
+
+// Strategy returns a winning strategy of the coalition of the outermost strategy quantifier in the states satisfying
+// the formula, or nil if the formula does not start with one
+func (f *ATLFormula) Strategy() *cav.Strategy {
+	if formula, ok := f.compiled.(cav.IStrategyFormula); ok {
+		return formula.Strategy()
+	}
+	return nil
+}
+
+func (f *ATLFormula) GetKripkeStructure() cav.IKripkeStructure {
+	return f.kripkeStructure
+}
+
+func (f *ATLFormula) String() string {
+	return "ATL " + f.formula.String()
+}
@@ -0,0 +1,98 @@
+package atl
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Op int
+
+const (
+	OpTrue Op = iota
+	OpFalse
+	OpLabel
+	OpNot
+	OpAnd
+	OpOr
+	OpImplies
+	OpNext    // <<A>> X φ: the coalition can force the next state to satisfy φ
+	OpGlobal  // <<A>> G φ: the coalition can keep φ true forever
+	OpFinally // <<A>> F φ: the coalition can force φ to become true
+	OpUntil   // <<A>> [φ U ψ]: the coalition can keep φ true until it forces ψ to become true
+)
+
+// Formula is an ATL formula. Name is only used by OpLabel and Coalition, the agents of the strategy quantifier,
+// only by OpNext, OpGlobal, OpFinally and OpUntil.
+type Formula struct {
+	Op        Op
+	Name      string
+	Coalition []string
+	Sub       []*Formula
+}
+
+func MakeFormula(op Op, sub ...*Formula) *Formula {
+	return &Formula{Op: op, Sub: sub}
+}
+
+func MakeLabel(name string) *Formula {
+	return &Formula{Op: OpLabel, Name: name}
+}
+
+// MakeCoalition returns <<coalition>> op sub, op being OpNext, OpGlobal, OpFinally or OpUntil
+func MakeCoalition(op Op, coalition []string, sub ...*Formula) *Formula {
+	return &Formula{Op: op, Coalition: coalition, Sub: sub}
+}
+
+// IsCoalition reports whether the formula starts with a strategy quantifier
+func (f *Formula) IsCoalition() bool {
+	return f.Op == OpNext || f.Op == OpGlobal || f.Op == OpFinally || f.Op == OpUntil
+}
+
+// String returns the formula in the syntax read by Parse, with every binary operator in brackets
+func (f *Formula) String() string {
+	coalition := "<<" + strings.Join(f.Coalition, ", ") + ">>"
+	switch f.Op {
+	case OpTrue:
+		return "true"
+	case OpFalse:
+		return "false"
+	case OpLabel:
+		return f.Name
+	case OpNot:
+		return "NOT " + f.Sub[0].String()
+	case OpAnd:
+		return fmt.Sprintf("(%s AND %s)", f.Sub[0].String(), f.Sub[1].String())
+	case OpOr:
+		return fmt.Sprintf("(%s OR %s)", f.Sub[0].String(), f.Sub[1].String())
+	case OpImplies:
+		return fmt.Sprintf("(%s IMPLIES %s)", f.Sub[0].String(), f.Sub[1].String())
+	case OpNext:
+		return fmt.Sprintf("%s X %s", coalition, f.Sub[0].String())
+	case OpGlobal:
+		return fmt.Sprintf("%s G %s", coalition, f.Sub[0].String())
+	case OpFinally:
+		return fmt.Sprintf("%s F %s", coalition, f.Sub[0].String())
+	}
+	return fmt.Sprintf("%s [%s U %s]", coalition, f.Sub[0].String(), f.Sub[1].String())
+}
+
+// Labels returns the names of all labels occurring in the formula
+func (f *Formula) Labels() []string {
+	if f.Op == OpLabel {
+		return []string{f.Name}
+	}
+	result := make([]string, 0)
+	for _, sub := range f.Sub {
+		result = append(result, sub.Labels()...)
+	}
+	return result
+}
+
+// Agents returns the names of all agents occurring in the coalitions of the formula
+func (f *Formula) Agents() []string {
+	result := append([]string{}, f.Coalition...)
+	for _, sub := range f.Sub {
+		result = append(result, sub.Agents()...)
+	}
+	return result
+}
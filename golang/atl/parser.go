@@ -0,0 +1,236 @@
+package atl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError is returned by Parse, Column being the 1-based byte offset into the parsed text
+type SyntaxError struct {
+	Column  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Message)
+}
+
+type token struct {
+	text   string
+	offset int
+}
+
+var symbols = []string{"<<", ">>", "->", "&&", "||", "(", ")", "[", "]", "!", "&", "|", ","}
+
+var keywords = map[string]string{
+	"->": "IMPLIES", "&&": "AND", "&": "AND", "||": "OR", "|": "OR", "!": "NOT",
+}
+
+func tokenize(s string) []token {
+	tokens := make([]token, 0)
+	for i := 0; i < len(s); {
+		if s[i] == ' ' || s[i] == '\t' {
+			i++
+			continue
+		}
+		symbol := ""
+		for _, candidate := range symbols {
+			if strings.HasPrefix(s[i:], candidate) {
+				symbol = candidate
+				break
+			}
+		}
+		if symbol != "" {
+			text := symbol
+			if keyword, ok := keywords[symbol]; ok {
+				text = keyword
+			}
+			tokens = append(tokens, token{text, i})
+			i += len(symbol)
+			continue
+		}
+		j := i
+		for j < len(s) && !strings.ContainsAny(s[j:j+1], " \t()[]!&|<>,") && !strings.HasPrefix(s[j:], "->") {
+			j++
+		}
+		if j == i {
+			j++
+		}
+		tokens = append(tokens, token{s[i:j], i})
+		i = j
+	}
+	return tokens
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	length int
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos].text
+}
+
+func (p *parser) errorf(s string, ss ...any) error {
+	column := p.length + 1
+	if p.pos < len(p.tokens) {
+		column = p.tokens[p.pos].offset + 1
+	}
+	return &SyntaxError{column, fmt.Sprintf(s, ss...)}
+}
+
+func (p *parser) expect(text string) error {
+	if p.peek() != text {
+		return p.errorf("expected %q", text)
+	}
+	p.pos++
+	return nil
+}
+
+// Parse parses an ATL formula like "<<client, server>> [NOT err U done]". Operators from weakest to strongest
+// binding are IMPLIES (->), OR (|), AND (&) and NOT (!). The strategy quantifier <<A>> names a coalition of agents,
+// possibly none, and takes one of the path formulas X a, G a, F a or [a U b].
+func Parse(s string) (*Formula, error) {
+	p := &parser{tokenize(s), 0, len(s)}
+	f, err := p.parseImplies()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.errorf("unexpected %s", p.peek())
+	}
+	return f, nil
+}
+
+func (p *parser) parseImplies() (*Formula, error) {
+	left, err := p.parseBinary(OpOr, "OR", p.parseAnd)
+	if err != nil || p.peek() != "IMPLIES" {
+		return left, err
+	}
+	p.pos++
+	right, err := p.parseImplies()
+	if err != nil {
+		return nil, err
+	}
+	return MakeFormula(OpImplies, left, right), nil
+}
+
+func (p *parser) parseAnd() (*Formula, error) {
+	return p.parseBinary(OpAnd, "AND", p.parseUnary)
+}
+
+// parseBinary parses a left associative chain of the given operator
+func (p *parser) parseBinary(op Op, keyword string, operand func() (*Formula, error)) (*Formula, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == keyword {
+		p.pos++
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = MakeFormula(op, left, right)
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (*Formula, error) {
+	text := p.peek()
+	switch text {
+	case "":
+		return nil, p.errorf("missing formula")
+	case "NOT":
+		p.pos++
+		sub, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return MakeFormula(OpNot, sub), nil
+	case "<<":
+		return p.parseCoalition()
+	case "(":
+		p.pos++
+		f, err := p.parseImplies()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+	if !isName(text) {
+		return nil, p.errorf("unexpected %s", text)
+	}
+
+	p.pos++
+	switch text {
+	case "true":
+		return MakeFormula(OpTrue), nil
+	case "false":
+		return MakeFormula(OpFalse), nil
+	}
+	return MakeLabel(text), nil
+}
+
+// parseCoalition parses a strategy quantifier with its path formula
+func (p *parser) parseCoalition() (*Formula, error) {
+	p.pos++
+	coalition := make([]string, 0)
+	for p.peek() != ">>" {
+		if len(coalition) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, p.errorf("expected \",\" or \">>\"")
+			}
+		}
+		if !isName(p.peek()) {
+			return nil, p.errorf("expected an agent")
+		}
+		coalition = append(coalition, p.peek())
+		p.pos++
+	}
+	p.pos++
+
+	switch p.peek() {
+	case "X", "G", "F":
+		op := map[string]Op{"X": OpNext, "G": OpGlobal, "F": OpFinally}[p.peek()]
+		p.pos++
+		sub, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return MakeCoalition(op, coalition, sub), nil
+	case "[":
+		p.pos++
+		left, err := p.parseImplies()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("U"); err != nil {
+			return nil, err
+		}
+		right, err := p.parseImplies()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return MakeCoalition(OpUntil, coalition, left, right), nil
+	}
+	return nil, p.errorf("expected \"X\", \"G\", \"F\" or \"[\" after the coalition")
+}
+
+func isName(s string) bool {
+	switch s {
+	case "", "AND", "OR", "IMPLIES", "NOT", "X", "G", "F", "U":
+		return false
+	}
+	return !strings.ContainsAny(s, "()[]!&|<>,")
+}
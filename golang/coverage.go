@@ -0,0 +1,42 @@
+package main
+
+import (
+	"cav/golang/coverage"
+	"cav/golang/parser"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func coverageReport(args []string) int {
+	flags := flag.NewFlagSet("coverage", flag.ContinueOnError)
+	dot := flags.String("dot", "", "also write the model as Graphviz file colored by coverage")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Println("Usage: main coverage [-dot <file>] <file>")
+		return 2
+	}
+
+	ks, formulas, err := parser.ParseFile(flags.Arg(0))
+	if err != nil {
+		fmt.Println("Failed to parse file:")
+		fmt.Println(err)
+		return 1
+	}
+
+	c := coverage.Compute(ks, formulas)
+	c.WriteSummary(os.Stdout)
+
+	if *dot != "" {
+		file, err := os.Create(*dot)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		defer file.Close()
+		c.WriteDOT(file)
+	}
+	return 0
+}
@@ -0,0 +1,146 @@
+package coverage
+
+import (
+	"cav/golang/types"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Coverage records for every label the states in which flipping it changes the verdict of at least one formula
+type Coverage struct {
+	ks      cav.IKripkeStructure
+	labels  []cav.ILabel
+	covered map[cav.ILabel]cav.ISet[cav.IState]
+}
+
+func verdicts(formulas []cav.IFormula) []bool {
+	result := make([]bool, len(formulas))
+	for i, formula := range formulas {
+		result[i] = cav.Holds(formula)
+	}
+	return result
+}
+
+func flip(state cav.IState, label cav.ILabel) {
+	if state.HasLabel(label) {
+		state.RemoveLabel(label)
+	} else {
+		state.AddLabel(label)
+	}
+}
+
+// Compute flips every label in every state of the Kripke structure one after another and rechecks all formulas.
+// The Kripke structure is modified while computing, but restored before returning.
+func Compute(ks cav.IKripkeStructure, formulas []cav.IFormula) *Coverage {
+	c := &Coverage{ks, make([]cav.ILabel, 0), map[cav.ILabel]cav.ISet[cav.IState]{}}
+	ks.GetLabels().ForEach(func(label cav.ILabel) {
+		c.labels = append(c.labels, label)
+	})
+	sort.Slice(c.labels, func(i, j int) bool {
+		return c.labels[i].String() < c.labels[j].String()
+	})
+
+	expected := verdicts(formulas)
+	for _, label := range c.labels {
+		covered := cav.MakeSet[cav.IState]()
+		ks.GetStates().ForEach(func(state cav.IState) {
+			flip(state, label)
+			for i, holds := range verdicts(formulas) {
+				if holds != expected[i] {
+					covered.Add(state)
+					break
+				}
+			}
+			flip(state, label)
+		})
+		c.covered[label] = covered
+	}
+	return c
+}
+
+func (c *Coverage) GetLabels() []cav.ILabel {
+	return c.labels
+}
+
+// GetCovered returns the states in which flipping the label changes the verdict of a formula
+func (c *Coverage) GetCovered(label cav.ILabel) cav.ISet[cav.IState] {
+	return c.covered[label]
+}
+
+// GetUncovered returns the states in which the label can be flipped without any formula noticing
+func (c *Coverage) GetUncovered(label cav.ILabel) cav.ISet[cav.IState] {
+	return c.ks.GetStates().Minus(c.covered[label])
+}
+
+// Ratio returns the fraction of label and state pairs which are covered
+func (c *Coverage) Ratio() float64 {
+	total := len(c.labels) * c.ks.GetStates().Size()
+	if total <= 0 {
+		return 1
+	}
+	covered := 0
+	for _, label := range c.labels {
+		covered += c.covered[label].Size()
+	}
+	return float64(covered) / float64(total)
+}
+
+// WriteSummary writes the covered and uncovered states of every label
+func (c *Coverage) WriteSummary(w io.Writer) {
+	states := c.ks.GetStates().Size()
+	for _, label := range c.labels {
+		covered := c.GetCovered(label)
+		fmt.Fprintf(w, "%s: %d/%d states covered\n", label.String(), covered.Size(), states)
+		fmt.Fprintf(w, "  covered:   %s\n", covered.String())
+		fmt.Fprintf(w, "  uncovered: %s\n", c.GetUncovered(label).String())
+	}
+	fmt.Fprintf(w, "total: %.1f%% covered\n", 100*c.Ratio())
+}
+
+// quote escapes s for use inside a quoted DOT identifier
+func quote(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\\", "\\\\"), "\"", "\\\"")
+}
+
+// WriteDOT writes the Kripke structure in the Graphviz format, coloring states by how many of their labels are covered
+func (c *Coverage) WriteDOT(w io.Writer) {
+	fmt.Fprintln(w, "digraph coverage {")
+	fmt.Fprintln(w, "  node [style=filled];")
+
+	names := cav.SortedNames(c.ks.GetStates())
+	states := map[string]cav.IState{}
+	c.ks.GetStates().ForEach(func(state cav.IState) {
+		states[state.GetName()] = state
+	})
+
+	for _, name := range names {
+		state := states[name]
+		covered := make([]string, 0)
+		uncovered := make([]string, 0)
+		for _, label := range c.labels {
+			if c.covered[label].Contains(state) {
+				covered = append(covered, label.String())
+			} else {
+				uncovered = append(uncovered, label.String())
+			}
+		}
+
+		color := "khaki"
+		if len(uncovered) <= 0 {
+			color = "palegreen"
+		} else if len(covered) <= 0 {
+			color = "lightcoral"
+		}
+		text := fmt.Sprintf("%s\\ncovered: %s\\nuncovered: %s", quote(name), quote(strings.Join(covered, ", ")), quote(strings.Join(uncovered, ", ")))
+		fmt.Fprintf(w, "  \"%s\" [label=\"%s\", fillcolor=%s];\n", quote(name), text, color)
+	}
+
+	for _, name := range names {
+		for _, child := range cav.SortedNames(states[name].GetChildren()) {
+			fmt.Fprintf(w, "  \"%s\" -> \"%s\";\n", quote(name), quote(child))
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
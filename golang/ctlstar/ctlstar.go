@@ -0,0 +1,142 @@
+package ctlstar
+
+import (
+	"cav/golang/ltl"
+	"cav/golang/types"
+	"fmt"
+)
+
+// Formula is a CTL* state formula, using the syntax of ltl.ParseCTLStar.
+// Like LTL formulas, path quantifiers only range over infinite paths.
+type Formula struct {
+	kripkeStructure cav.IKripkeStructure
+	formula         *ltl.Formula
+}
+
+// IsStateFormula reports whether every temporal operator of the formula is below a path quantifier
+func IsStateFormula(f *ltl.Formula) bool {
+	switch f.Op {
+	case ltl.OpX, ltl.OpF, ltl.OpG, ltl.OpU, ltl.OpR:
+		return false
+	case ltl.OpA, ltl.OpE:
+		return true
+	}
+	for _, sub := range f.Sub {
+		if !IsStateFormula(sub) {
+			return false
+		}
+	}
+	return true
+}
+
+// MakeFormula checks that the formula is a state formula whose labels all exist in the Kripke structure
+func MakeFormula(ks cav.IKripkeStructure, f *ltl.Formula) (*Formula, error) {
+	if !IsStateFormula(f) {
+		return nil, fmt.Errorf("temporal operator outside of a path quantifier: %s", f.String())
+	}
+	labels := map[string]bool{}
+	ks.GetLabels().ForEach(func(label cav.ILabel) {
+		labels[label.String()] = true
+	})
+	for _, name := range f.Labels() {
+		if !labels[name] {
+			return nil, fmt.Errorf("unknown label in formula: %s", name)
+		}
+	}
+	return &Formula{ks, f}, nil
+}
+
+// checker evaluates state formulas bottom-up, replacing nested ones by fresh labels while checking a path formula.
+// The fresh labels only live in its valuation, so the Kripke structure is never modified.
+type checker struct {
+	ks        cav.IKripkeStructure
+	labels    map[string]cav.ILabel
+	valuation ltl.Valuation
+	fresh     int
+}
+
+// Check returns the states satisfying the formula. It does not modify the Kripke structure, so formulas on the same
+// structure can be checked concurrently.
+func (f *Formula) Check() cav.ISet[cav.IState] {
+	c := &checker{f.kripkeStructure, map[string]cav.ILabel{}, ltl.Valuation{}, 0}
+	f.kripkeStructure.GetLabels().ForEach(func(label cav.ILabel) {
+		c.labels[label.String()] = label
+	})
+	f.kripkeStructure.GetStates().ForEach(func(state cav.IState) {
+		c.valuation[state] = map[string]bool{}
+	})
+	return c.check(f.formula)
+}
+
+func (c *checker) check(f *ltl.Formula) cav.ISet[cav.IState] {
+	states := c.ks.GetStates()
+	switch f.Op {
+	case ltl.OpTrue:
+		return states.Copy()
+	case ltl.OpFalse:
+		return cav.MakeSet[cav.IState]()
+	case ltl.OpLabel:
+		result := cav.MakeSet[cav.IState]()
+		states.ForEach(func(state cav.IState) {
+			if state.HasLabel(c.labels[f.Label]) {
+				result.Add(state)
+			}
+		})
+		return result
+	case ltl.OpNot:
+		return states.Minus(c.check(f.Sub[0]))
+	case ltl.OpAnd:
+		return c.check(f.Sub[0]).Intersect(c.check(f.Sub[1]))
+	case ltl.OpOr:
+		return c.check(f.Sub[0]).Union(c.check(f.Sub[1]))
+	case ltl.OpImplies:
+		return states.Minus(c.check(f.Sub[0])).Union(c.check(f.Sub[1]))
+	case ltl.OpA:
+		return states.Minus(c.exists(ltl.MakeFormula(ltl.OpNot, f.Sub[0])))
+	case ltl.OpE:
+		return c.exists(f.Sub[0])
+	}
+	panic(fmt.Sprintf("not a state formula: %s", f.String()))
+}
+
+// exists checks E f after replacing the maximal quantified subformulas of f by fresh labels
+func (c *checker) exists(f *ltl.Formula) cav.ISet[cav.IState] {
+	result, err := ltl.ExistsWithValuation(c.ks, c.abstract(f), c.valuation)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+func (c *checker) abstract(f *ltl.Formula) *ltl.Formula {
+	if f.Op == ltl.OpA || f.Op == ltl.OpE {
+		sat := c.check(f)
+		// labels read from a file never contain spaces, so this cannot clash
+		c.fresh++
+		name := fmt.Sprintf("<state formula %d>", c.fresh)
+		for state, labels := range c.valuation {
+			labels[name] = sat.Contains(state)
+		}
+		return ltl.MakeLabel(name)
+	}
+	if len(f.Sub) <= 0 {
+		return f
+	}
+	sub := make([]*ltl.Formula, len(f.Sub))
+	for i, s := range f.Sub {
+		sub[i] = c.abstract(s)
+	}
+	return ltl.MakeFormula(f.Op, sub...)
+}
+
+func (f *Formula) GetFormula() *ltl.Formula {
+	return f.formula
+}
+
+func (f *Formula) GetKripkeStructure() cav.IKripkeStructure {
+	return f.kripkeStructure
+}
+
+func (f *Formula) String() string {
+	return "CTL* " + f.formula.String()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"cav/golang/parser"
+	"cav/golang/types"
+	"flag"
+	"fmt"
+)
+
+func distanceReport(args []string) int {
+	flags := flag.NewFlagSet("distance", flag.ContinueOnError)
+	text := flags.String("formula", "", "formula whose states are the targets instead of those in the file")
+	cost := flags.Bool("cost", false, "print the minimal and maximal cost of reaching the targets instead of the number of steps")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Println("Usage: main distance [-formula <formula>] [-cost] <file>")
+		return 2
+	}
+
+	ks, formulas, err := parser.ParseFile(flags.Arg(0))
+	if err != nil {
+		fmt.Println("Failed to parse file:")
+		fmt.Println(err)
+		return 1
+	}
+	if *text != "" {
+		formula, err := parser.ParseFormula(ks, *text)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		formulas = []cav.IFormula{formula}
+	}
+
+	states := cav.SortedStates(ks.GetStates())
+	for _, formula := range formulas {
+		fmt.Println(formula.String() + ":")
+		if *cost {
+			printCosts(states, formula)
+			continue
+		}
+		distances := cav.Distances(formula)
+		for _, state := range states {
+			if distance, ok := distances[state]; ok {
+				fmt.Printf("  %s: %d\n", state.GetName(), distance)
+			} else {
+				fmt.Printf("  %s: unreachable\n", state.GetName())
+			}
+		}
+	}
+	return 0
+}
+
+// printCosts prints the costs of reaching the states satisfying the formula, the maximum being unbounded
+// if there is a path on which they never hold
+func printCosts(states []cav.IState, formula cav.IFormula) {
+	minCosts, maxCosts := cav.MinCosts(formula), cav.MaxCosts(formula)
+	for _, state := range states {
+		minCost, ok := minCosts[state]
+		if !ok {
+			fmt.Printf("  %s: unreachable\n", state.GetName())
+		} else if maxCost, ok := maxCosts[state]; ok {
+			fmt.Printf("  %s: min %d, max %d\n", state.GetName(), minCost, maxCost)
+		} else {
+			fmt.Printf("  %s: min %d, max unbounded\n", state.GetName(), minCost)
+		}
+	}
+}
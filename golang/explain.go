@@ -0,0 +1,80 @@
+package main
+
+import (
+	"cav/golang/ast"
+	"cav/golang/explain"
+	"cav/golang/parser"
+	"cav/golang/types"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func explainFormulas(args []string) int {
+	flags := flag.NewFlagSet("explain", flag.ContinueOnError)
+	stateName := flags.String("state", "", "state to explain the formulas in")
+	text := flags.String("formula", "", "formula to explain instead of those in the file")
+	asJSON := flags.Bool("json", false, "print the proof trees as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 || *stateName == "" {
+		fmt.Println("Usage: main explain [-json] [-formula <formula>] -state <state> <file>")
+		return 2
+	}
+
+	p := parser.MakeFileParser()
+	ks, _, err := p.ParseFile(flags.Arg(0))
+	if err != nil {
+		fmt.Println("Failed to parse file:")
+		fmt.Println(err)
+		return 1
+	}
+
+	var state cav.IState
+	ks.GetStates().ForEach(func(s cav.IState) {
+		if s.GetName() == *stateName {
+			state = s
+		}
+	})
+	if state == nil {
+		fmt.Println("Unknown state: " + *stateName)
+		return 1
+	}
+
+	nodes := p.GetNodes()
+	if *text != "" {
+		node, err := parser.ParseNode(*text)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		nodes = []*ast.Node{node}
+	}
+
+	proofs := make([]*explain.Proof, 0, len(nodes))
+	for _, node := range nodes {
+		if node == nil {
+			// LTL formulas cannot be explained
+			continue
+		}
+		proof, err := explain.Explain(node, state)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		proofs = append(proofs, proof)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(proofs)
+		return 0
+	}
+	for _, proof := range proofs {
+		proof.WriteText(os.Stdout)
+	}
+	return 0
+}
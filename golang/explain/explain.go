@@ -0,0 +1,684 @@
+package explain
+
+import (
+	"cav/golang/ast"
+	"cav/golang/transform"
+	"cav/golang/types"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Proof justifies why a formula does or does not hold in a state, Children being the justifications it relies on
+type Proof struct {
+	Formula  string   `json:"formula"`
+	State    string   `json:"state"`
+	Holds    bool     `json:"holds"`
+	Evidence string   `json:"evidence"`
+	Children []*Proof `json:"children,omitempty"`
+}
+
+// WriteText writes the proof as a tree indented by two spaces per level
+func (p *Proof) WriteText(w io.Writer) {
+	p.writeText(w, 0)
+}
+
+func (p *Proof) writeText(w io.Writer, depth int) {
+	relation := "|="
+	if !p.Holds {
+		relation = "|/="
+	}
+	fmt.Fprintf(w, "%s%s %s %s: %s\n", strings.Repeat("  ", depth), p.State, relation, p.Formula, p.Evidence)
+	for _, child := range p.Children {
+		child.writeText(w, depth+1)
+	}
+}
+
+type visit struct {
+	node  *ast.Node
+	state cav.IState
+}
+
+type explainer struct {
+	ks      cav.IKripkeStructure
+	sat     map[*ast.Node]cav.ISet[cav.IState]
+	ranks   map[*ast.Node]map[cav.IState]int
+	enf     map[*ast.Node]*ast.Node
+	visited map[visit]bool
+}
+
+// Explain returns the proof tree for the formula in the given state. Only boolean connectives, EX, EU, EG, their
+// past counterparts EY, ES and EH, bounded until and cost-bounded EF and AF are explained directly, all other temporal operators are
+// explained by their existential normal form.
+func Explain(node *ast.Node, state cav.IState) (*Proof, error) {
+	ks := state.GetKripkeStructure()
+	if _, err := ast.Bind(node, ks); err != nil {
+		return nil, err
+	}
+	e := &explainer{
+		ks:      ks,
+		sat:     map[*ast.Node]cav.ISet[cav.IState]{},
+		ranks:   map[*ast.Node]map[cav.IState]int{},
+		enf:     map[*ast.Node]*ast.Node{},
+		visited: map[visit]bool{},
+	}
+	return e.explain(node, state), nil
+}
+
+// check returns the states satisfying the node; all subformulas bind since the whole formula did
+func (e *explainer) check(n *ast.Node) cav.ISet[cav.IState] {
+	if sat, ok := e.sat[n]; ok {
+		return sat
+	}
+	formula, _ := ast.Bind(n, e.ks)
+	sat := formula.Check()
+	e.sat[n] = sat
+	return sat
+}
+
+// layers assigns every state in the least fixpoint computed from start the iteration it has been added in,
+// a state being added once it satisfies allowed and step accepts its successors
+func (e *explainer) layers(start cav.ISet[cav.IState], allowed cav.ISet[cav.IState], step func(state cav.IState, reached map[cav.IState]int) bool) map[cav.IState]int {
+	reached := map[cav.IState]int{}
+	start.ForEach(func(state cav.IState) {
+		reached[state] = 0
+	})
+	for i := 1; ; i++ {
+		added := make([]cav.IState, 0)
+		allowed.ForEach(func(state cav.IState) {
+			if _, ok := reached[state]; !ok && step(state, reached) {
+				added = append(added, state)
+			}
+		})
+		if len(added) <= 0 {
+			return reached
+		}
+		for _, state := range added {
+			reached[state] = i
+		}
+	}
+}
+
+// untilRanks returns the iteration in which each state satisfying E[a U b] has been added to the least fixpoint
+func (e *explainer) untilRanks(n *ast.Node) map[cav.IState]int {
+	if ranks, ok := e.ranks[n]; ok {
+		return ranks
+	}
+	ranks := e.layers(e.check(n.Operands[1]), e.check(n.Operands[0]), func(state cav.IState, reached map[cav.IState]int) bool {
+		found := false
+		state.GetChildren().ForEach(func(child cav.IState) {
+			if _, ok := reached[child]; ok {
+				found = true
+			}
+		})
+		return found
+	})
+	e.ranks[n] = ranks
+	return ranks
+}
+
+// sinceRanks returns the iteration in which each state satisfying E[a S b] has been added to the least fixpoint
+func (e *explainer) sinceRanks(n *ast.Node) map[cav.IState]int {
+	if ranks, ok := e.ranks[n]; ok {
+		return ranks
+	}
+	ranks := e.layers(e.check(n.Operands[1]), e.check(n.Operands[0]), func(state cav.IState, reached map[cav.IState]int) bool {
+		found := false
+		state.GetParents().ForEach(func(parent cav.IState) {
+			if _, ok := reached[parent]; ok {
+				found = true
+			}
+		})
+		return found
+	})
+	e.ranks[n] = ranks
+	return ranks
+}
+
+// historicallyRanks returns the number of steps within which all backward paths of each state violating EH a leave a
+func (e *explainer) historicallyRanks(n *ast.Node) map[cav.IState]int {
+	if ranks, ok := e.ranks[n]; ok {
+		return ranks
+	}
+	a := e.check(n.Operands[0])
+	ranks := e.layers(e.ks.GetStates().Minus(a), a, func(state cav.IState, reached map[cav.IState]int) bool {
+		all := state.GetParents().Size() > 0
+		state.GetParents().ForEach(func(parent cav.IState) {
+			if _, ok := reached[parent]; !ok {
+				all = false
+			}
+		})
+		return all
+	})
+	e.ranks[n] = ranks
+	return ranks
+}
+
+// globallyRanks returns the number of steps within which all paths of each state violating EG a leave a
+func (e *explainer) globallyRanks(n *ast.Node) map[cav.IState]int {
+	if ranks, ok := e.ranks[n]; ok {
+		return ranks
+	}
+	a := e.check(n.Operands[0])
+	start := e.ks.GetStates().Minus(a)
+	a.ForEach(func(state cav.IState) {
+		if state.GetChildren().Size() <= 0 {
+			start.Add(state)
+		}
+	})
+	ranks := e.layers(start, a, func(state cav.IState, reached map[cav.IState]int) bool {
+		all := true
+		state.GetChildren().ForEach(func(child cav.IState) {
+			if _, ok := reached[child]; !ok {
+				all = false
+			}
+		})
+		return all
+	})
+	e.ranks[n] = ranks
+	return ranks
+}
+
+func (e *explainer) explain(n *ast.Node, s cav.IState) *Proof {
+	holds := e.check(n).Contains(s)
+	proof := &Proof{Formula: n.String(), State: s.GetName(), Holds: holds}
+	add := func(n *ast.Node, s cav.IState) {
+		proof.Children = append(proof.Children, e.explain(n, s))
+	}
+
+	switch n.Kind {
+	case ast.Label:
+		if holds {
+			proof.Evidence = fmt.Sprintf("%s has label %s", s.GetName(), n.Name)
+		} else {
+			proof.Evidence = fmt.Sprintf("%s does not have label %s", s.GetName(), n.Name)
+		}
+	case ast.True:
+		proof.Evidence = "true holds everywhere"
+	case ast.False:
+		proof.Evidence = "false holds nowhere"
+	case ast.Not:
+		proof.Evidence = "negation"
+		add(n.Operands[0], s)
+	case ast.And, ast.Or, ast.Implies:
+		e.explainBoolean(proof, n, s, add)
+	case ast.EX:
+		e.explainEX(proof, n, s, add)
+	case ast.EU:
+		e.explainEU(proof, n, s, add)
+	case ast.EG:
+		e.explainEG(proof, n, s, add)
+	case ast.EY:
+		e.explainEY(proof, n, s, add)
+	case ast.ES:
+		e.explainES(proof, n, s, add)
+	case ast.EH:
+		e.explainEH(proof, n, s, add)
+	case ast.BoundedEU, ast.BoundedAU:
+		e.explainBoundedUntil(proof, n, s, add)
+	case ast.CostEF:
+		e.explainCostEF(proof, n, s, add)
+	case ast.CostAF:
+		e.explainCostAF(proof, n, s, add)
+	case ast.CostEG:
+		// EG[cost<=c] a is kept by the existential normal form, so it is explained by its dual
+		dual := ast.MakeNode(ast.Not, ast.MakeBounded(ast.CostAF, n.Bound, ast.MakeNode(ast.Not, n.Operands[0])))
+		proof.Evidence = "equivalent to " + dual.String()
+		add(dual, s)
+	default:
+		enf, ok := e.enf[n]
+		if !ok {
+			enf = transform.ENF(n)
+			e.enf[n] = enf
+		}
+		proof.Evidence = "equivalent to " + enf.String()
+		add(enf, s)
+	}
+	return proof
+}
+
+func (e *explainer) explainBoolean(proof *Proof, n *ast.Node, s cav.IState, add func(*ast.Node, cav.IState)) {
+	a, b := n.Operands[0], n.Operands[1]
+	aHolds := e.check(a).Contains(s)
+
+	switch {
+	case n.Kind == ast.And && proof.Holds, n.Kind == ast.Or && !proof.Holds:
+		proof.Evidence = "both operands"
+		add(a, s)
+		add(b, s)
+	case n.Kind == ast.And:
+		proof.Evidence = "one operand does not hold"
+		if aHolds {
+			add(b, s)
+		} else {
+			add(a, s)
+		}
+	case n.Kind == ast.Or:
+		proof.Evidence = "one operand holds"
+		if aHolds {
+			add(a, s)
+		} else {
+			add(b, s)
+		}
+	case proof.Holds && !aHolds:
+		proof.Evidence = "the premise does not hold"
+		add(a, s)
+	case proof.Holds:
+		proof.Evidence = "the conclusion holds"
+		add(b, s)
+	default:
+		proof.Evidence = "the premise holds but the conclusion does not"
+		add(a, s)
+		add(b, s)
+	}
+}
+
+func (e *explainer) explainEX(proof *Proof, n *ast.Node, s cav.IState, add func(*ast.Node, cav.IState)) {
+	a := n.Operands[0]
+	children := cav.SortedStates(s.GetChildren())
+	if proof.Holds {
+		for _, child := range children {
+			if e.check(a).Contains(child) {
+				proof.Evidence = fmt.Sprintf("successor %s satisfies %s", child.GetName(), a.String())
+				add(a, child)
+				return
+			}
+		}
+	}
+	if len(children) <= 0 {
+		proof.Evidence = "no successors"
+		return
+	}
+	proof.Evidence = fmt.Sprintf("no successor satisfies %s", a.String())
+	for _, child := range children {
+		add(a, child)
+	}
+}
+
+func (e *explainer) explainEU(proof *Proof, n *ast.Node, s cav.IState, add func(*ast.Node, cav.IState)) {
+	a, b := n.Operands[0], n.Operands[1]
+
+	if proof.Holds {
+		ranks := e.untilRanks(n)
+		rank := ranks[s]
+		if rank == 0 {
+			proof.Evidence = fmt.Sprintf("iteration 0: %s holds", b.String())
+			add(b, s)
+			return
+		}
+		for _, child := range cav.SortedStates(s.GetChildren()) {
+			if childRank, ok := ranks[child]; ok && childRank < rank {
+				proof.Evidence = fmt.Sprintf("iteration %d: %s holds and successor %s has been reached in iteration %d", rank, a.String(), child.GetName(), childRank)
+				add(a, s)
+				add(n, child)
+				return
+			}
+		}
+	}
+
+	// neither b holds nor can it be reached through a, possibly because all paths stay in a forever
+	e.visited[visit{n, s}] = true
+	add(b, s)
+	if !e.check(a).Contains(s) {
+		proof.Evidence = fmt.Sprintf("neither %s nor %s holds", a.String(), b.String())
+		add(a, s)
+		return
+	}
+	children := cav.SortedStates(s.GetChildren())
+	if len(children) <= 0 {
+		proof.Evidence = fmt.Sprintf("%s does not hold and there are no successors", b.String())
+		return
+	}
+	proof.Evidence = fmt.Sprintf("%s does not hold and no successor satisfies %s", b.String(), n.String())
+	for _, child := range children {
+		if e.visited[visit{n, child}] {
+			proof.Children = append(proof.Children, &Proof{n.String(), child.GetName(), false, "already explained", nil})
+		} else {
+			add(n, child)
+		}
+	}
+}
+
+func (e *explainer) explainEG(proof *Proof, n *ast.Node, s cav.IState, add func(*ast.Node, cav.IState)) {
+	a := n.Operands[0]
+
+	if proof.Holds {
+		// every state satisfying EG a has a successor satisfying it, so this ends in a cycle
+		sat := e.check(n)
+		path := make([]cav.IState, 0)
+		index := map[cav.IState]int{}
+		for state := s; ; {
+			if _, ok := index[state]; ok {
+				names := make([]string, 0, len(path)+1)
+				for _, p := range path {
+					names = append(names, p.GetName())
+				}
+				names = append(names, state.GetName())
+				proof.Evidence = fmt.Sprintf("%s holds along the path %s which loops back to %s", a.String(), strings.Join(names, " -> "), state.GetName())
+				break
+			}
+			index[state] = len(path)
+			path = append(path, state)
+			for _, child := range cav.SortedStates(state.GetChildren()) {
+				if sat.Contains(child) {
+					state = child
+					break
+				}
+			}
+		}
+		for _, state := range path {
+			add(a, state)
+		}
+		return
+	}
+
+	rank := e.globallyRanks(n)[s]
+	switch {
+	case !e.check(a).Contains(s):
+		proof.Evidence = fmt.Sprintf("%s does not hold", a.String())
+		add(a, s)
+	case s.GetChildren().Size() <= 0:
+		proof.Evidence = "there are no successors, so there is no infinite path"
+	default:
+		proof.Evidence = fmt.Sprintf("every path leaves %s within %d steps", a.String(), rank)
+		for _, child := range cav.SortedStates(s.GetChildren()) {
+			add(n, child)
+		}
+	}
+}
+
+func (e *explainer) explainEY(proof *Proof, n *ast.Node, s cav.IState, add func(*ast.Node, cav.IState)) {
+	a := n.Operands[0]
+	parents := cav.SortedStates(s.GetParents())
+	if proof.Holds {
+		for _, parent := range parents {
+			if e.check(a).Contains(parent) {
+				proof.Evidence = fmt.Sprintf("predecessor %s satisfies %s", parent.GetName(), a.String())
+				add(a, parent)
+				return
+			}
+		}
+	}
+	if len(parents) <= 0 {
+		proof.Evidence = "no predecessors"
+		return
+	}
+	proof.Evidence = fmt.Sprintf("no predecessor satisfies %s", a.String())
+	for _, parent := range parents {
+		add(a, parent)
+	}
+}
+
+func (e *explainer) explainES(proof *Proof, n *ast.Node, s cav.IState, add func(*ast.Node, cav.IState)) {
+	a, b := n.Operands[0], n.Operands[1]
+
+	if proof.Holds {
+		ranks := e.sinceRanks(n)
+		rank := ranks[s]
+		if rank == 0 {
+			proof.Evidence = fmt.Sprintf("iteration 0: %s holds", b.String())
+			add(b, s)
+			return
+		}
+		for _, parent := range cav.SortedStates(s.GetParents()) {
+			if parentRank, ok := ranks[parent]; ok && parentRank < rank {
+				proof.Evidence = fmt.Sprintf("iteration %d: %s holds and predecessor %s has been reached in iteration %d", rank, a.String(), parent.GetName(), parentRank)
+				add(a, s)
+				add(n, parent)
+				return
+			}
+		}
+	}
+
+	e.visited[visit{n, s}] = true
+	add(b, s)
+	if !e.check(a).Contains(s) {
+		proof.Evidence = fmt.Sprintf("neither %s nor %s holds", a.String(), b.String())
+		add(a, s)
+		return
+	}
+	parents := cav.SortedStates(s.GetParents())
+	if len(parents) <= 0 {
+		proof.Evidence = fmt.Sprintf("%s does not hold and there are no predecessors", b.String())
+		return
+	}
+	proof.Evidence = fmt.Sprintf("%s does not hold and no predecessor satisfies %s", b.String(), n.String())
+	for _, parent := range parents {
+		if e.visited[visit{n, parent}] {
+			proof.Children = append(proof.Children, &Proof{n.String(), parent.GetName(), false, "already explained", nil})
+		} else {
+			add(n, parent)
+		}
+	}
+}
+
+func (e *explainer) explainEH(proof *Proof, n *ast.Node, s cav.IState, add func(*ast.Node, cav.IState)) {
+	a := n.Operands[0]
+
+	if proof.Holds {
+		// every state satisfying EH a has a predecessor satisfying it or none at all
+		sat := e.check(n)
+		path := make([]cav.IState, 0)
+		index := map[cav.IState]int{}
+		for state := s; ; {
+			if _, ok := index[state]; ok {
+				proof.Evidence = fmt.Sprintf("%s holds along the backward path %s which loops back to %s", a.String(), joinNames(path, state), state.GetName())
+				break
+			}
+			index[state] = len(path)
+			path = append(path, state)
+			var next cav.IState
+			for _, parent := range cav.SortedStates(state.GetParents()) {
+				if sat.Contains(parent) {
+					next = parent
+					break
+				}
+			}
+			if next == nil {
+				proof.Evidence = fmt.Sprintf("%s holds along the backward path %s which ends in %s without predecessors", a.String(), joinNames(path[:len(path)-1], state), state.GetName())
+				break
+			}
+			state = next
+		}
+		for _, state := range path {
+			add(a, state)
+		}
+		return
+	}
+
+	rank := e.historicallyRanks(n)[s]
+	if !e.check(a).Contains(s) {
+		proof.Evidence = fmt.Sprintf("%s does not hold", a.String())
+		add(a, s)
+		return
+	}
+	proof.Evidence = fmt.Sprintf("every backward path leaves %s within %d steps", a.String(), rank)
+	for _, parent := range cav.SortedStates(s.GetParents()) {
+		add(n, parent)
+	}
+}
+
+func joinNames(path []cav.IState, last cav.IState) string {
+	names := make([]string, 0, len(path)+1)
+	for _, state := range path {
+		names = append(names, state.GetName())
+	}
+	return strings.Join(append(names, last.GetName()), " -> ")
+}
+
+// explainBoundedUntil explains E[a U[l,u] b] and A[a U[l,u] b] step by step, the successors being explained
+// with the bound shifted by one step
+func (e *explainer) explainBoundedUntil(proof *Proof, n *ast.Node, s cav.IState, add func(*ast.Node, cav.IState)) {
+	a, b := n.Operands[0], n.Operands[1]
+	// huge bounds are explained by the smallest equivalent one, as the explanation takes a step per step of the bound
+	formula, _ := ast.Bind(n, e.ks)
+	if bound := formula.(cav.IBoundedUntilFormula).ReducedBound(); bound != n.Bound {
+		reduced := ast.MakeBounded(n.Kind, bound, a, b)
+		proof.Evidence = "equivalent to " + reduced.String()
+		add(reduced, s)
+		return
+	}
+	next := ast.MakeBounded(n.Kind, n.Bound.Shift(), a, b)
+	children := cav.SortedStates(s.GetChildren())
+
+	switch {
+	case n.Bound.Lower == 0 && e.check(b).Contains(s):
+		proof.Evidence = fmt.Sprintf("%s holds", b.String())
+		add(b, s)
+		return
+	case n.Bound.Upper == 0:
+		proof.Evidence = fmt.Sprintf("%s does not hold and no steps are left", b.String())
+		add(b, s)
+		return
+	case !e.check(a).Contains(s):
+		proof.Evidence = fmt.Sprintf("%s does not hold", a.String())
+		if n.Bound.Lower == 0 {
+			add(b, s)
+		}
+		add(a, s)
+		return
+	case len(children) <= 0:
+		if proof.Holds {
+			proof.Evidence = fmt.Sprintf("%s holds and there are no successors", a.String())
+		} else {
+			proof.Evidence = fmt.Sprintf("%s holds but there are no successors", a.String())
+		}
+		add(a, s)
+		return
+	}
+
+	for _, child := range children {
+		// only the successor deciding an existential formula which holds or a universal one which does not is shown
+		if (n.Kind == ast.BoundedEU) == proof.Holds && e.check(next).Contains(child) == proof.Holds {
+			if proof.Holds {
+				proof.Evidence = fmt.Sprintf("%s holds and successor %s satisfies %s", a.String(), child.GetName(), next.String())
+			} else {
+				proof.Evidence = fmt.Sprintf("%s holds but successor %s does not satisfy %s", a.String(), child.GetName(), next.String())
+			}
+			add(a, s)
+			add(next, child)
+			return
+		}
+	}
+	if proof.Holds {
+		proof.Evidence = fmt.Sprintf("%s holds and all successors satisfy %s", a.String(), next.String())
+	} else {
+		proof.Evidence = fmt.Sprintf("%s holds but no successor satisfies %s", a.String(), next.String())
+	}
+	add(a, s)
+	for _, child := range children {
+		add(next, child)
+	}
+}
+
+// joinWeighted names the states of the path with the weight of each transition, like "s1 -3-> s2"
+func joinWeighted(path []cav.IState) string {
+	var b strings.Builder
+	for i, state := range path {
+		if i > 0 {
+			fmt.Fprintf(&b, " -%d-> ", path[i-1].GetWeight(state))
+		}
+		b.WriteString(state.GetName())
+	}
+	return b.String()
+}
+
+// explainCostEF explains EF[cost<=c] b by a cheapest path to b
+func (e *explainer) explainCostEF(proof *Proof, n *ast.Node, s cav.IState, add func(*ast.Node, cav.IState)) {
+	b := n.Operands[0]
+	formula, _ := ast.Bind(b, e.ks)
+	costs := cav.MinCosts(formula)
+	cost, ok := costs[s]
+	if !ok {
+		proof.Evidence = fmt.Sprintf("no state satisfying %s is reachable", b.String())
+		return
+	}
+	if !proof.Holds {
+		proof.Evidence = fmt.Sprintf("the cheapest path to %s costs %d", b.String(), cost)
+		return
+	}
+
+	// search the transitions on cheapest paths breadth first, so zero weight cycles are not followed
+	sat := e.check(b)
+	previous := map[cav.IState]cav.IState{s: nil}
+	queue := []cav.IState{s}
+	goal := s
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		if sat.Contains(state) {
+			goal = state
+			break
+		}
+		for _, child := range cav.SortedStates(state.GetChildren()) {
+			if _, seen := previous[child]; !seen && costs[state] == state.GetWeight(child)+costs[child] {
+				if _, reachable := costs[child]; reachable {
+					previous[child] = state
+					queue = append(queue, child)
+				}
+			}
+		}
+	}
+	path := []cav.IState{goal}
+	for state := previous[goal]; state != nil; state = previous[state] {
+		path = append([]cav.IState{state}, path...)
+	}
+	proof.Evidence = fmt.Sprintf("the path %s reaches %s at a cost of %d", joinWeighted(path), b.String(), cost)
+	add(b, goal)
+}
+
+// explainCostAF explains AF[cost<=c] b by the highest cost of reaching b, or a path avoiding b too long or forever
+func (e *explainer) explainCostAF(proof *Proof, n *ast.Node, s cav.IState, add func(*ast.Node, cav.IState)) {
+	b := n.Operands[0]
+	formula, _ := ast.Bind(b, e.ks)
+	costs := cav.MaxCosts(formula)
+	goal := e.check(b)
+	if goal.Contains(s) {
+		proof.Evidence = fmt.Sprintf("%s holds", b.String())
+		add(b, s)
+		return
+	}
+	if cost, ok := costs[s]; ok && proof.Holds {
+		proof.Evidence = fmt.Sprintf("every path reaches %s or ends at a cost of at most %d", b.String(), cost)
+		return
+	}
+
+	path := []cav.IState{s}
+	if _, ok := costs[s]; !ok {
+		// there is an infinite path avoiding b, so a state on it is repeated
+		index := map[cav.IState]int{}
+		for state := s; ; {
+			index[state] = len(path) - 1
+			for _, child := range cav.SortedStates(state.GetChildren()) {
+				if _, bounded := costs[child]; !bounded {
+					state = child
+					break
+				}
+			}
+			path = append(path, state)
+			if _, repeated := index[state]; repeated {
+				proof.Evidence = fmt.Sprintf("the path %s loops back to %s without reaching %s", joinWeighted(path), state.GetName(), b.String())
+				return
+			}
+		}
+	}
+
+	// follow the most expensive transitions until b holds or the path ends
+	state := s
+	for !goal.Contains(state) && state.GetChildren().Size() > 0 {
+		var next cav.IState
+		for _, child := range cav.SortedStates(state.GetChildren()) {
+			if next == nil || state.GetWeight(child)+costs[child] > state.GetWeight(next)+costs[next] {
+				next = child
+			}
+		}
+		state = next
+		path = append(path, state)
+	}
+	if goal.Contains(state) {
+		proof.Evidence = fmt.Sprintf("the path %s only reaches %s at a cost of %d", joinWeighted(path), b.String(), costs[s])
+		add(b, state)
+	} else {
+		proof.Evidence = fmt.Sprintf("the path %s ends at a cost of %d without reaching %s", joinWeighted(path), costs[s], b.String())
+	}
+}
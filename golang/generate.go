@@ -0,0 +1,59 @@
+package main
+
+import (
+	"cav/golang/generator"
+	"cav/golang/parser"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+)
+
+func generate(args []string) int {
+	o := generator.DefaultOptions()
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	flags.IntVar(&o.States, "states", o.States, "number of states")
+	flags.IntVar(&o.MinDegree, "min-degree", o.MinDegree, "minimal number of successors per state")
+	flags.IntVar(&o.MaxDegree, "max-degree", o.MaxDegree, "maximal number of successors per state")
+	flags.StringVar(&o.Distribution, "distribution", o.Distribution, "out-degree distribution: uniform or geometric")
+	flags.IntVar(&o.Labels, "labels", o.Labels, "number of labels")
+	flags.Float64Var(&o.LabelDensity, "density", o.LabelDensity, "probability that a label holds in a state")
+	flags.IntVar(&o.SCCs, "sccs", o.SCCs, "number of strongly connected components, 0 for any")
+	flags.Int64Var(&o.Seed, "seed", o.Seed, "random seed")
+	formulas := flags.Int("formulas", 5, "number of formulas")
+	depth := flags.Int("depth", 3, "nesting depth of the formulas")
+	output := flags.String("o", "", "output file, defaults to stdout")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	ks, err := generator.RandomKripkeStructure(o)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	// formulas use their own stream so that changing their number does not change the structure
+	r := rand.New(rand.NewSource(o.Seed))
+	flas := make([]string, *formulas)
+	for i := range flas {
+		flas[i] = generator.RandomFormula(r, *depth, o.LabelNames())
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		out, err = os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer out.Close()
+	}
+	fmt.Fprintf(out, "// generated with: generate -states %d -min-degree %d -max-degree %d -distribution %s -labels %d -density %v -sccs %d -seed %d -formulas %d -depth %d\n\n",
+		o.States, o.MinDegree, o.MaxDegree, o.Distribution, o.Labels, o.LabelDensity, o.SCCs, o.Seed, *formulas, *depth)
+	if err := parser.Write(out, ks, flas...); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
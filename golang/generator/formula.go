@@ -0,0 +1,158 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// names of the operators of generated formulas, labels have no operator
+const (
+	OpTrue    = "true"
+	OpFalse   = "false"
+	OpNot     = "NOT"
+	OpAnd     = "AND"
+	OpOr      = "OR"
+	OpImplies = "IMPLIES"
+	OpEX      = "EX"
+	OpEG      = "EG"
+	OpEF      = "EF"
+	OpEU      = "EU"
+	OpER      = "ER"
+	OpAX      = "AX"
+	OpAG      = "AG"
+	OpAF      = "AF"
+	OpAU      = "AU"
+	OpAR      = "AR"
+	OpEY      = "EY"
+	OpEH      = "EH"
+	OpES      = "ES"
+	OpAY      = "AY"
+	OpAH      = "AH"
+	OpAS      = "AS"
+	OpX       = "X"
+	OpF       = "F"
+	OpG       = "G"
+	OpU       = "U"
+	OpR       = "R"
+)
+
+// Syntax maps the operators a formula may use to their format, with one %s per operand
+type Syntax map[string]string
+
+// CTLSyntax is the native syntax of the CTL operators
+var CTLSyntax = Syntax{
+	OpNot: "NOT %s", OpAnd: "%s AND %s", OpOr: "%s OR %s",
+	OpEX: "EX %s", OpEG: "EG %s", OpEF: "EF %s", OpEU: "E[%s U %s]", OpER: "E[%s R %s]",
+	OpAX: "AX %s", OpAG: "AG %s", OpAF: "AF %s", OpAU: "A[%s U %s]", OpAR: "A[%s R %s]",
+}
+
+// CTLStarSyntax writes the CTL operators as CTL* formulas, a path quantifier followed by a temporal operator
+var CTLStarSyntax = Syntax{
+	OpNot: "NOT %s", OpAnd: "%s AND %s", OpOr: "%s OR %s",
+	OpEX: "E X %s", OpEG: "E G %s", OpEF: "E F %s", OpEU: "E (%s U %s)", OpER: "E (%s R %s)",
+	OpAX: "A X %s", OpAG: "A G %s", OpAF: "A F %s", OpAU: "A (%s U %s)", OpAR: "A (%s R %s)",
+}
+
+// PastSyntax mixes the past operators with EX
+var PastSyntax = Syntax{
+	OpNot: "NOT %s", OpAnd: "%s AND %s", OpEX: "EX %s",
+	OpEY: "EY %s", OpEH: "EH %s", OpES: "E[%s S %s]", OpAY: "AY %s", OpAH: "AH %s", OpAS: "A[%s S %s]",
+}
+
+// LTLSyntax is the syntax of the LTL operators
+var LTLSyntax = Syntax{
+	OpNot: "NOT %s", OpAnd: "%s AND %s", OpOr: "%s OR %s", OpImplies: "%s IMPLIES %s",
+	OpX: "X %s", OpF: "F %s", OpG: "G %s", OpU: "%s U %s", OpR: "%s R %s",
+}
+
+// With returns a copy of the syntax with the given operator added
+func (s Syntax) With(op string, format string) Syntax {
+	result := Syntax{op: format}
+	for key, value := range s {
+		result[key] = value
+	}
+	return result
+}
+
+// Arity returns the number of operands of the operator
+func (s Syntax) Arity(op string) int {
+	return strings.Count(s[op], "%s")
+}
+
+// Formula is a generated syntax tree, Label being set if there is no operator
+type Formula struct {
+	Op    string
+	Label string
+	Sub   []*Formula
+}
+
+// Format writes the formula in the given syntax, which has to contain all of its operators
+func (f *Formula) Format(syntax Syntax) string {
+	switch {
+	case f.Op == "":
+		return f.Label
+	case f.Op == OpTrue || f.Op == OpFalse:
+		return f.Op
+	}
+	operands := make([]any, len(f.Sub))
+	for i, sub := range f.Sub {
+		operands[i] = parenthesize(sub.Format(syntax))
+	}
+	return fmt.Sprintf(syntax[f.Op], operands...)
+}
+
+// FormulaOptions controls the shape of generated formulas
+type FormulaOptions struct {
+	Depth     int    // exact nesting depth
+	Syntax    Syntax // operators to choose from
+	Labels    []string
+	Constants bool // true and false may occur besides the labels
+}
+
+// Generate returns a formula with exactly the given nesting depth, the same random numbers always result in the same formula
+func Generate(r *rand.Rand, o FormulaOptions) *Formula {
+	ops := make([]string, 0, len(o.Syntax))
+	for op := range o.Syntax {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	return generate(r, &o, ops, o.Depth)
+}
+
+func generate(r *rand.Rand, o *FormulaOptions, ops []string, depth int) *Formula {
+	if depth <= 0 || len(ops) <= 0 {
+		if len(o.Labels) <= 0 || o.Constants && r.Intn(8) == 0 {
+			return &Formula{Op: []string{OpTrue, OpFalse}[r.Intn(2)]}
+		}
+		return &Formula{Label: o.Labels[r.Intn(len(o.Labels))]}
+	}
+
+	op := ops[r.Intn(len(ops))]
+	if o.Syntax.Arity(op) == 1 {
+		return &Formula{Op: op, Sub: []*Formula{generate(r, o, ops, depth-1)}}
+	}
+
+	// only one operand needs the full depth
+	left := generate(r, o, ops, depth-1)
+	right := generate(r, o, ops, r.Intn(depth))
+	if r.Intn(2) == 0 {
+		left, right = right, left
+	}
+	return &Formula{Op: op, Sub: []*Formula{left, right}}
+}
+
+// RandomFormula generates a CTL formula in the native syntax with exactly the given nesting depth
+func RandomFormula(r *rand.Rand, depth int, labels []string) string {
+	return Generate(r, FormulaOptions{depth, CTLSyntax, labels, true}).Format(CTLSyntax)
+}
+
+func parenthesize(s string) string {
+	for _, c := range s {
+		if c == ' ' {
+			return "(" + s + ")"
+		}
+	}
+	return s
+}
@@ -0,0 +1,161 @@
+package generator
+
+import (
+	"cav/golang/types"
+	"fmt"
+	"math/rand"
+)
+
+const (
+	DegreeUniform   = "uniform"   // out-degrees are drawn uniformly from [MinDegree, MaxDegree]
+	DegreeGeometric = "geometric" // small out-degrees are more likely, halving with every additional successor
+)
+
+// Options controls the shape of generated Kripke structures
+type Options struct {
+	States       int
+	MinDegree    int
+	MaxDegree    int
+	Distribution string
+	Labels       int
+	LabelDensity float64  // probability that a label holds in a state
+	Names        []string // names of the labels, p0, p1, ... if empty
+	SCCs         int      // exact number of strongly connected components, 0 leaves it to chance
+	Seed         int64
+}
+
+func DefaultOptions() Options {
+	return Options{
+		States:       10,
+		MinDegree:    1,
+		MaxDegree:    3,
+		Distribution: DegreeUniform,
+		Labels:       3,
+		LabelDensity: 0.5,
+		SCCs:         0,
+		Seed:         1,
+	}
+}
+
+func (o *Options) validate() error {
+	if o.States <= 0 {
+		return fmt.Errorf("at least one state is required, got %d", o.States)
+	}
+	if o.MinDegree < 0 || o.MaxDegree < o.MinDegree {
+		return fmt.Errorf("invalid out-degree range [%d, %d]", o.MinDegree, o.MaxDegree)
+	}
+	if o.MaxDegree > o.States {
+		return fmt.Errorf("out-degree %d exceeds the number of states %d", o.MaxDegree, o.States)
+	}
+	if o.Distribution != DegreeUniform && o.Distribution != DegreeGeometric {
+		return fmt.Errorf("unknown out-degree distribution: %s", o.Distribution)
+	}
+	if o.Labels < 0 || o.LabelDensity < 0 || o.LabelDensity > 1 {
+		return fmt.Errorf("invalid label options: %d labels with density %v", o.Labels, o.LabelDensity)
+	}
+	if len(o.Names) > 0 && len(o.Names) != o.Labels {
+		return fmt.Errorf("expected %d label names, got %d", o.Labels, len(o.Names))
+	}
+	if o.SCCs < 0 || o.SCCs > o.States {
+		return fmt.Errorf("cannot split %d states into %d strongly connected components", o.States, o.SCCs)
+	}
+	if o.SCCs > 0 && o.MaxDegree < 1 {
+		return fmt.Errorf("strongly connected components require an out-degree of at least 1")
+	}
+	return nil
+}
+
+func (o *Options) degree(r *rand.Rand) int {
+	if o.Distribution == DegreeGeometric {
+		d := o.MinDegree
+		for d < o.MaxDegree && r.Intn(2) == 0 {
+			d++
+		}
+		return d
+	}
+	return o.MinDegree + r.Intn(o.MaxDegree-o.MinDegree+1)
+}
+
+// LabelNames returns the names of the labels generated for the given options
+func (o *Options) LabelNames() []string {
+	if len(o.Names) > 0 {
+		return o.Names
+	}
+	names := make([]string, o.Labels)
+	for i := range names {
+		names[i] = fmt.Sprintf("p%d", i)
+	}
+	return names
+}
+
+// RandomKripkeStructure generates a Kripke structure, the same options always result in the same structure
+func RandomKripkeStructure(o Options) (cav.IKripkeStructure, error) {
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	r := rand.New(rand.NewSource(o.Seed))
+
+	ks := cav.MakeKripkeStructure()
+	states := make([]cav.IState, o.States)
+	for i := range states {
+		states[i] = ks.NewState(fmt.Sprintf("s%d", i))
+	}
+
+	// component[i] is the SCC of state i; edges only go to the same or a later component,
+	// so a cycle through every component makes them strongly connected without merging any two
+	component := make([]int, o.States)
+	successors := make([]map[int]bool, o.States)
+	for i := range successors {
+		successors[i] = map[int]bool{}
+	}
+	if o.SCCs > 0 {
+		perm := r.Perm(o.States)
+		// the first SCCs states of the permutation start one component each, the rest is distributed randomly
+		members := make([][]int, o.SCCs)
+		for i, s := range perm {
+			c := i
+			if i >= o.SCCs {
+				c = r.Intn(o.SCCs)
+			}
+			component[s] = c
+			members[c] = append(members[c], s)
+		}
+		for _, m := range members {
+			for i, s := range m {
+				successors[s][m[(i+1)%len(m)]] = true
+			}
+		}
+	}
+
+	for i := range states {
+		candidates := make([]int, 0, o.States)
+		for j := range states {
+			if component[j] >= component[i] {
+				candidates = append(candidates, j)
+			}
+		}
+		degree := min(o.degree(r), len(candidates))
+		for len(successors[i]) < degree {
+			successors[i][candidates[r.Intn(len(candidates))]] = true
+		}
+	}
+
+	// add the transitions in a fixed order to keep the generation deterministic
+	for i, state := range states {
+		for j := range states {
+			if successors[i][j] {
+				state.AddChildren(states[j])
+			}
+		}
+	}
+
+	for _, name := range o.LabelNames() {
+		label := ks.NewLabel(name)
+		for _, state := range states {
+			if r.Float64() < o.LabelDensity {
+				state.AddLabel(label)
+			}
+		}
+	}
+	return ks, nil
+}
@@ -0,0 +1,432 @@
+package lsp
+
+import (
+	"bufio"
+	"cav/golang/parser"
+	"cav/golang/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  any              `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+type CompletionItem struct {
+	Label  string `json:"label"`
+	Kind   int    `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type Hover struct {
+	Contents string `json:"contents"`
+	Range    Range  `json:"range"`
+}
+
+type textDocumentParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+	Position Position `json:"position"`
+}
+
+const (
+	severityError       = 1
+	completionKindFunc  = 3
+	completionKindVar   = 6
+	completionKindConst = 21
+	completionKindKw    = 14
+	errorMethodNotFound = -32601
+)
+
+var formulaKeywords = []string{"true", "false", "NOT", "AND", "OR", "IMPLIES", "EX", "EG", "EF", "E[", "AX", "AG", "AF", "A[", "U", "R", "EY", "EH", "AY", "AH", "S"}
+
+type document struct {
+	text     string
+	ks       cav.IKripkeStructure
+	formulas []cav.IFormula
+	symbols  parser.Symbols
+}
+
+// Server is a language server for the Kripke structure file format speaking LSP over a stream
+type Server struct {
+	reader    *bufio.Reader
+	writer    io.Writer
+	documents map[string]*document
+	shutdown  bool
+}
+
+func (s *Server) readMessage() (*message, error) {
+	length := -1
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		if value, ok := strings.CutPrefix(line, "Content-Length:"); ok {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %s", value)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.reader, body); err != nil {
+		return nil, err
+	}
+	msg := &message{}
+	if err := json.Unmarshal(body, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (s *Server) writeMessage(msg *message) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.writer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+func (s *Server) reply(id *json.RawMessage, result any) error {
+	if result == nil {
+		// a null result still has to be sent explicitly
+		result = json.RawMessage("null")
+	}
+	return s.writeMessage(&message{ID: id, Result: result})
+}
+
+func (s *Server) notify(method string, params any) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.writeMessage(&message{Method: method, Params: raw})
+}
+
+// Run serves requests until the client sends "exit" or the input ends
+func (s *Server) Run() error {
+	for {
+		msg, err := s.readMessage()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		var params textDocumentParams
+		if len(msg.Params) > 0 {
+			json.Unmarshal(msg.Params, &params)
+		}
+		uri := params.TextDocument.URI
+
+		switch msg.Method {
+		case "initialize":
+			err = s.reply(msg.ID, map[string]any{
+				"capabilities": map[string]any{
+					"textDocumentSync": map[string]any{
+						"openClose": true,
+						"change":    1,
+						"save":      map[string]any{"includeText": true},
+					},
+					"definitionProvider": true,
+					"hoverProvider":      true,
+					"completionProvider": map[string]any{
+						"triggerCharacters": []string{" ", ",", "(", "["},
+					},
+				},
+				"serverInfo": map[string]any{"name": "cav-lsp"},
+			})
+		case "initialized":
+		case "shutdown":
+			s.shutdown = true
+			err = s.reply(msg.ID, nil)
+		case "exit":
+			if !s.shutdown {
+				return fmt.Errorf("exit without shutdown")
+			}
+			return nil
+		case "textDocument/didOpen":
+			s.documents[uri] = &document{text: params.TextDocument.Text}
+			err = s.publishDiagnostics(uri)
+		case "textDocument/didChange":
+			if doc, ok := s.documents[uri]; ok && len(params.ContentChanges) > 0 {
+				doc.text = params.ContentChanges[len(params.ContentChanges)-1].Text
+			}
+			err = s.publishDiagnostics(uri)
+		case "textDocument/didSave":
+			if doc, ok := s.documents[uri]; ok && params.TextDocument.Text != "" {
+				doc.text = params.TextDocument.Text
+			}
+			err = s.publishDiagnostics(uri)
+		case "textDocument/didClose":
+			delete(s.documents, uri)
+		case "textDocument/definition":
+			err = s.reply(msg.ID, s.definition(uri, params.Position))
+		case "textDocument/completion":
+			err = s.reply(msg.ID, s.completion(uri, params.Position))
+		case "textDocument/hover":
+			err = s.reply(msg.ID, s.hover(uri, params.Position))
+		default:
+			if msg.ID != nil {
+				err = s.writeMessage(&message{ID: msg.ID, Error: &responseError{errorMethodNotFound, "method not supported: " + msg.Method}})
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// publishDiagnostics parses the text of the document with the FileParser and reports its error, if any.
+// Includes are resolved relative to the directory of the document.
+func (s *Server) publishDiagnostics(uri string) error {
+	doc, ok := s.documents[uri]
+	if !ok {
+		return nil
+	}
+
+	diagnostics := make([]Diagnostic, 0)
+	path, err := uriToPath(uri)
+	if err == nil {
+		p := parser.MakeFileParser()
+		doc.ks, doc.formulas, err = p.ParseStringAt(doc.text, path)
+		doc.symbols = p.GetSymbols()
+	}
+	if err != nil {
+		doc.formulas = nil
+		line := 0
+		var parseErr *parser.ParseError
+		message := err.Error()
+		if errors.As(err, &parseErr) && parseErr.File == "" {
+			line = max(parseErr.Line-1, 0)
+			message = parseErr.Message
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    lineRange(doc.text, line),
+			Severity: severityError,
+			Source:   "cav",
+			Message:  message,
+		})
+	}
+
+	return s.notify("textDocument/publishDiagnostics", map[string]any{
+		"uri":         uri,
+		"diagnostics": diagnostics,
+	})
+}
+
+func (s *Server) definition(uri string, position Position) []Location {
+	doc, ok := s.documents[uri]
+	if !ok {
+		return nil
+	}
+	word, _ := wordAt(doc.text, position)
+	declaration, ok := doc.symbols.States[word]
+	if !ok {
+		declaration, ok = doc.symbols.Labels[word]
+	}
+	if !ok {
+		declaration, ok = doc.symbols.Definitions[word]
+	}
+	if !ok {
+		return nil
+	}
+	if declaration.File != "" {
+		uri = pathToURI(declaration.File)
+	}
+	line, start := declaration.Line-1, declaration.Column-1
+	return []Location{{uri, Range{Position{line, start}, Position{line, start + len(word)}}}}
+}
+
+func (s *Server) completion(uri string, position Position) []CompletionItem {
+	doc, ok := s.documents[uri]
+	if !ok {
+		return nil
+	}
+
+	items := make([]CompletionItem, 0)
+	addStates := func() {
+		for name := range doc.symbols.States {
+			items = append(items, CompletionItem{Label: name, Kind: completionKindVar, Detail: "state"})
+		}
+	}
+
+	switch sectionAt(doc.text, position.Line) {
+	case "initial", "transitions", "rewards":
+		addStates()
+	case "labels":
+		// states are only expected behind the label name
+		line := lineAt(doc.text, position.Line)
+		if strings.Contains(line[:min(position.Character, len(line))], ":") {
+			addStates()
+		}
+	case "definitions", "formulas":
+		for name := range doc.symbols.Labels {
+			items = append(items, CompletionItem{Label: name, Kind: completionKindConst, Detail: "label"})
+		}
+		for name := range doc.symbols.Definitions {
+			items = append(items, CompletionItem{Label: name, Kind: completionKindFunc, Detail: "definition"})
+		}
+		for _, keyword := range formulaKeywords {
+			items = append(items, CompletionItem{Label: keyword, Kind: completionKindKw})
+		}
+	}
+	return items
+}
+
+func (s *Server) hover(uri string, position Position) *Hover {
+	doc, ok := s.documents[uri]
+	if !ok {
+		return nil
+	}
+
+	for i, declaration := range doc.symbols.Formulas {
+		if declaration.File == "" && declaration.Line-1 == position.Line && i < len(doc.formulas) {
+			formula := doc.formulas[i]
+			return &Hover{
+				Contents: fmt.Sprintf("%s: %s", formula.String(), formula.Check().String()),
+				Range:    lineRange(doc.text, position.Line),
+			}
+		}
+	}
+
+	word, r := wordAt(doc.text, position)
+	if _, ok := doc.symbols.Labels[word]; ok && doc.ks != nil {
+		var label cav.ILabel
+		doc.ks.GetLabels().ForEach(func(l cav.ILabel) {
+			if l.String() == word {
+				label = l
+			}
+		})
+		if label != nil {
+			return &Hover{
+				Contents: fmt.Sprintf("%s: %s", word, label.MakeLabelFormula().Check().String()),
+				Range:    r,
+			}
+		}
+	}
+	return nil
+}
+
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported uri scheme: %s", u.Scheme)
+	}
+	return u.Path, nil
+}
+
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+func lineAt(text string, line int) string {
+	lines := strings.Split(text, "\n")
+	if line < 0 || line >= len(lines) {
+		return ""
+	}
+	return strings.TrimRight(lines[line], "\r")
+}
+
+func lineRange(text string, line int) Range {
+	return Range{Position{line, 0}, Position{line, len(lineAt(text, line))}}
+}
+
+func isWordChar(c byte) bool {
+	return c != ' ' && c != '\t' && c != ',' && c != ':' && c != '(' && c != ')' && c != '[' && c != ']'
+}
+
+// wordAt returns the state or label name under the cursor
+func wordAt(text string, position Position) (string, Range) {
+	line := lineAt(text, position.Line)
+	line = strings.SplitN(line, "//", 2)[0]
+	start := min(position.Character, len(line))
+	end := start
+	for start > 0 && isWordChar(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isWordChar(line[end]) {
+		end++
+	}
+	return line[start:end], Range{Position{position.Line, start}, Position{position.Line, end}}
+}
+
+// sectionAt returns the section keyword the given line belongs to
+func sectionAt(text string, line int) string {
+	section := ""
+	for i := 0; i <= line; i++ {
+		l := strings.TrimSpace(strings.SplitN(lineAt(text, i), "//", 2)[0])
+		switch l {
+		case "states", "initial", "agents", "transitions", "labels", "rewards", "definitions", "formulas":
+			if i < line {
+				section = l
+			}
+		}
+	}
+	return section
+}
+
+func MakeServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		reader:    bufio.NewReader(in),
+		writer:    out,
+		documents: map[string]*document{},
+	}
+}
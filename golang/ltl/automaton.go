@@ -0,0 +1,223 @@
+package ltl
+
+import "sort"
+
+// node is a state of the generalized Büchi automaton built by the tableau construction of
+// Gerth, Peled, Vardi and Wolper. Old holds the formulas the node promises to satisfy now,
+// next those it promises for its successors.
+type node struct {
+	id       int
+	incoming map[int]bool
+	new      []*Formula
+	old      map[string]*Formula
+	next     map[string]*Formula
+}
+
+// initialID is the id of the virtual predecessor of all initial nodes
+const initialID = 0
+
+func (n *node) split() *node {
+	clone := &node{
+		incoming: map[int]bool{},
+		new:      append([]*Formula{}, n.new...),
+		old:      map[string]*Formula{},
+		next:     map[string]*Formula{},
+	}
+	for id := range n.incoming {
+		clone.incoming[id] = true
+	}
+	for key, f := range n.old {
+		clone.old[key] = f
+	}
+	for key, f := range n.next {
+		clone.next[key] = f
+	}
+	return clone
+}
+
+func sameKeys(a map[string]*Formula, b map[string]*Formula) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key := range a {
+		if _, ok := b[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Automaton is a generalized Büchi automaton whose states constrain the labels of the state they read
+type Automaton struct {
+	nodes []*node
+	// accepting holds one set of node indices per until subformula, every set has to be visited infinitely often
+	accepting []map[int]bool
+	// successors holds the indices of the successors of every node
+	successors [][]int
+	initial    []int
+}
+
+type builder struct {
+	nodes  []*node
+	nextID int
+}
+
+func (b *builder) expand(n *node) {
+	if len(n.new) <= 0 {
+		for _, other := range b.nodes {
+			if sameKeys(other.old, n.old) && sameKeys(other.next, n.next) {
+				for id := range n.incoming {
+					other.incoming[id] = true
+				}
+				return
+			}
+		}
+		b.nextID++
+		n.id = b.nextID
+		b.nodes = append(b.nodes, n)
+
+		successor := &node{incoming: map[int]bool{n.id: true}, old: map[string]*Formula{}, next: map[string]*Formula{}}
+		for _, f := range n.next {
+			successor.new = append(successor.new, f)
+		}
+		// map iteration order is random, but the resulting automaton must not be
+		sort.Slice(successor.new, func(i, j int) bool {
+			return successor.new[i].String() < successor.new[j].String()
+		})
+		b.expand(successor)
+		return
+	}
+
+	f := n.new[len(n.new)-1]
+	n.new = n.new[:len(n.new)-1]
+	key := f.String()
+	if _, ok := n.old[key]; ok {
+		b.expand(n)
+		return
+	}
+
+	switch f.Op {
+	case OpFalse:
+		// contradiction, the node is dropped
+	case OpTrue, OpLabel, OpNot:
+		if _, ok := n.old[negation(f).String()]; ok {
+			return
+		}
+		n.old[key] = f
+		b.expand(n)
+	case OpAnd:
+		n.old[key] = f
+		n.new = append(n.new, f.Sub[0], f.Sub[1])
+		b.expand(n)
+	case OpX:
+		n.old[key] = f
+		n.next[f.Sub[0].String()] = f.Sub[0]
+		b.expand(n)
+	case OpOr, OpU, OpR:
+		n.old[key] = f
+		other := n.split()
+		a, c := f.Sub[0], f.Sub[1]
+		switch f.Op {
+		case OpOr:
+			n.new = append(n.new, a)
+			other.new = append(other.new, c)
+		case OpU:
+			// a U c holds if c holds now or a holds now and a U c from the next state on
+			n.new = append(n.new, a)
+			n.next[key] = f
+			other.new = append(other.new, c)
+		case OpR:
+			// a R c holds if both hold now or c holds now and a R c from the next state on
+			n.new = append(n.new, a, c)
+			other.new = append(other.new, c)
+			other.next[key] = f
+		}
+		b.expand(n)
+		b.expand(other)
+	}
+}
+
+func negation(f *Formula) *Formula {
+	switch f.Op {
+	case OpTrue:
+		return MakeFormula(OpFalse)
+	case OpNot:
+		return f.Sub[0]
+	}
+	return MakeFormula(OpNot, f)
+}
+
+func untils(f *Formula, result map[string]*Formula) {
+	if f.Op == OpU {
+		result[f.String()] = f
+	}
+	for _, sub := range f.Sub {
+		untils(sub, result)
+	}
+}
+
+// MakeAutomaton translates the formula into a generalized Büchi automaton accepting exactly the paths satisfying it
+func MakeAutomaton(f *Formula) *Automaton {
+	f = NNF(f)
+	b := &builder{}
+	b.expand(&node{
+		incoming: map[int]bool{initialID: true},
+		new:      []*Formula{f},
+		old:      map[string]*Formula{},
+		next:     map[string]*Formula{},
+	})
+
+	a := &Automaton{nodes: b.nodes, successors: make([][]int, len(b.nodes)), initial: make([]int, 0)}
+	index := map[int]int{}
+	for i, n := range b.nodes {
+		index[n.id] = i
+	}
+	for i, n := range b.nodes {
+		ids := make([]int, 0, len(n.incoming))
+		for id := range n.incoming {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		for _, id := range ids {
+			if id == initialID {
+				a.initial = append(a.initial, i)
+			} else {
+				a.successors[index[id]] = append(a.successors[index[id]], i)
+			}
+		}
+	}
+
+	all := map[string]*Formula{}
+	untils(f, all)
+	keys := make([]string, 0, len(all))
+	for key := range all {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		u := all[key]
+		set := map[int]bool{}
+		for i, n := range b.nodes {
+			_, promised := n.old[key]
+			_, fulfilled := n.old[u.Sub[1].String()]
+			if !promised || fulfilled {
+				set[i] = true
+			}
+		}
+		a.accepting = append(a.accepting, set)
+	}
+	if len(a.accepting) <= 0 {
+		// without until subformulas every infinite run is accepting
+		set := map[int]bool{}
+		for i := range b.nodes {
+			set[i] = true
+		}
+		a.accepting = append(a.accepting, set)
+	}
+	return a
+}
+
+// Size returns the number of states of the automaton
+func (a *Automaton) Size() int {
+	return len(a.nodes)
+}
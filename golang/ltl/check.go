@@ -0,0 +1,367 @@
+package ltl
+
+import (
+	"cav/golang/types"
+	"fmt"
+	"strings"
+)
+
+// Lasso is an infinite path: Prefix followed by Cycle repeated forever
+type Lasso struct {
+	Prefix []cav.IState
+	Cycle  []cav.IState
+}
+
+func (l *Lasso) String() string {
+	names := func(states []cav.IState) []string {
+		result := make([]string, len(states))
+		for i, state := range states {
+			result[i] = state.GetName()
+		}
+		return result
+	}
+	cycle := "(" + strings.Join(names(l.Cycle), " -> ") + ")^ω"
+	if len(l.Prefix) <= 0 {
+		return cycle
+	}
+	return strings.Join(names(l.Prefix), " -> ") + " -> " + cycle
+}
+
+// vertex is a state of the product of a Kripke structure and the degeneralized automaton,
+// counter being the index of the acceptance set which has to be visited next
+type vertex struct {
+	state   cav.IState
+	node    int
+	counter int
+}
+
+type product struct {
+	automaton *Automaton
+	hasLabel  func(state cav.IState, name string) bool
+	vertices  []vertex
+	index     map[vertex]int
+	edges     [][]int
+	initial   map[cav.IState][]int
+}
+
+func (p *product) compatible(node int, state cav.IState) bool {
+	for _, f := range p.automaton.nodes[node].old {
+		switch {
+		case f.Op == OpLabel && !p.hasLabel(state, f.Label):
+			return false
+		case f.Op == OpNot && p.hasLabel(state, f.Sub[0].Label):
+			return false
+		}
+	}
+	return true
+}
+
+func (p *product) accepting(v vertex) bool {
+	return v.counter == 0 && p.automaton.accepting[0][v.node]
+}
+
+func (p *product) add(v vertex) (int, bool) {
+	if i, ok := p.index[v]; ok {
+		return i, false
+	}
+	p.index[v] = len(p.vertices)
+	p.vertices = append(p.vertices, v)
+	p.edges = append(p.edges, nil)
+	return len(p.vertices) - 1, true
+}
+
+// makeProduct builds the part of the product reachable from the given states of the Kripke structure
+func makeProduct(automaton *Automaton, hasLabel func(cav.IState, string) bool, states []cav.IState) *product {
+	p := &product{automaton, hasLabel, make([]vertex, 0), map[vertex]int{}, make([][]int, 0), map[cav.IState][]int{}}
+	queue := make([]int, 0)
+	for _, state := range states {
+		for _, node := range automaton.initial {
+			if p.compatible(node, state) {
+				i, added := p.add(vertex{state, node, 0})
+				p.initial[state] = append(p.initial[state], i)
+				if added {
+					queue = append(queue, i)
+				}
+			}
+		}
+	}
+
+	k := len(automaton.accepting)
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		v := p.vertices[i]
+		counter := v.counter
+		if automaton.accepting[counter][v.node] {
+			counter = (counter + 1) % k
+		}
+		for _, child := range cav.SortedStates(v.state.GetChildren()) {
+			for _, node := range automaton.successors[v.node] {
+				if p.compatible(node, child) {
+					j, added := p.add(vertex{child, node, counter})
+					p.edges[i] = append(p.edges[i], j)
+					if added {
+						queue = append(queue, j)
+					}
+				}
+			}
+		}
+	}
+	return p
+}
+
+// sccs returns the strongly connected component of every vertex using Tarjan's algorithm
+func (p *product) sccs() []int {
+	n := len(p.vertices)
+	component := make([]int, n)
+	lowlink := make([]int, n)
+	order := make([]int, n)
+	onStack := make([]bool, n)
+	stack := make([]int, 0)
+	counter := 0
+	components := 0
+	for i := range order {
+		order[i] = -1
+	}
+
+	var visit func(v int)
+	visit = func(v int) {
+		order[v] = counter
+		lowlink[v] = counter
+		counter++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range p.edges[v] {
+			if order[w] < 0 {
+				visit(w)
+				lowlink[v] = min(lowlink[v], lowlink[w])
+			} else if onStack[w] {
+				lowlink[v] = min(lowlink[v], order[w])
+			}
+		}
+		if lowlink[v] == order[v] {
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component[w] = components
+				if w == v {
+					break
+				}
+			}
+			components++
+		}
+	}
+	for v := 0; v < n; v++ {
+		if order[v] < 0 {
+			visit(v)
+		}
+	}
+	return component
+}
+
+// fair returns for every vertex whether an accepting cycle can be reached from it,
+// and for every accepting vertex on a cycle whether it is one
+func (p *product) fair() ([]bool, []bool) {
+	component := p.sccs()
+	size := map[int]int{}
+	for _, c := range component {
+		size[c]++
+	}
+
+	onCycle := make([]bool, len(p.vertices))
+	fair := make([]bool, len(p.vertices))
+	predecessors := make([][]int, len(p.vertices))
+	queue := make([]int, 0)
+	for v, edges := range p.edges {
+		for _, w := range edges {
+			predecessors[w] = append(predecessors[w], v)
+			if component[v] == component[w] && (size[component[v]] > 1 || v == w) && p.accepting(p.vertices[v]) {
+				onCycle[v] = true
+			}
+		}
+	}
+	for v := range onCycle {
+		if onCycle[v] {
+			fair[v] = true
+			queue = append(queue, v)
+		}
+	}
+	for len(queue) > 0 {
+		w := queue[0]
+		queue = queue[1:]
+		for _, v := range predecessors[w] {
+			if !fair[v] {
+				fair[v] = true
+				queue = append(queue, v)
+			}
+		}
+	}
+	return fair, onCycle
+}
+
+// path returns the shortest path of vertices from one of the sources to a vertex accepted by target,
+// not including the source itself if it is accepted right away and skipSources is set
+func (p *product) path(sources []int, target func(int) bool, skipSources bool) []int {
+	parent := map[int]int{}
+	queue := make([]int, 0)
+	for _, source := range sources {
+		parent[source] = -1
+		queue = append(queue, source)
+	}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		if target(v) && !(skipSources && parent[v] == -1) {
+			result := make([]int, 0)
+			for ; v >= 0; v = parent[v] {
+				result = append([]int{v}, result...)
+			}
+			return result
+		}
+		for _, w := range p.edges[v] {
+			if _, ok := parent[w]; !ok {
+				parent[w] = v
+				queue = append(queue, w)
+			}
+		}
+	}
+	return nil
+}
+
+// lasso returns an accepting run starting in the given state, if there is one
+func (p *product) lasso(state cav.IState, onCycle []bool) *Lasso {
+	prefix := p.path(p.initial[state], func(v int) bool {
+		return onCycle[v]
+	}, false)
+	if prefix == nil {
+		return nil
+	}
+	start := prefix[len(prefix)-1]
+	cycle := p.path(p.edges[start], func(v int) bool {
+		return v == start
+	}, false)
+
+	result := &Lasso{make([]cav.IState, 0), make([]cav.IState, 0)}
+	for _, v := range prefix[:len(prefix)-1] {
+		result.Prefix = append(result.Prefix, p.vertices[v].state)
+	}
+	result.Cycle = append(result.Cycle, p.vertices[start].state)
+	for _, v := range cycle[:len(cycle)-1] {
+		result.Cycle = append(result.Cycle, p.vertices[v].state)
+	}
+	return result
+}
+
+// Valuation gives the states labels by name which do not belong to the Kripke structure, like the state
+// subformulas of CTL*. A name is known once some state has an entry for it, even a false one.
+type Valuation map[cav.IState]map[string]bool
+
+// resolveLabels returns whether a state has a label of the Kripke structure or of the valuation, which may be nil
+func resolveLabels(ks cav.IKripkeStructure, f *Formula, valuation Valuation) (func(cav.IState, string) bool, error) {
+	if f.HasPathQuantifier() {
+		return nil, fmt.Errorf("path quantifiers are only allowed in CTL* formulas: %s", f.String())
+	}
+	labels := map[string]cav.ILabel{}
+	ks.GetLabels().ForEach(func(label cav.ILabel) {
+		labels[label.String()] = label
+	})
+	extra := map[string]bool{}
+	for _, names := range valuation {
+		for name := range names {
+			extra[name] = true
+		}
+	}
+	for _, name := range f.Labels() {
+		if _, ok := labels[name]; !ok && !extra[name] {
+			return nil, fmt.Errorf("unknown label in formula: %s", name)
+		}
+	}
+	return func(state cav.IState, name string) bool {
+		if label, ok := labels[name]; ok {
+			return state.HasLabel(label)
+		}
+		return valuation[state][name]
+	}, nil
+}
+
+// Exists returns the states from which an infinite path satisfying the formula starts.
+// Paths ending in a state without successors are not considered.
+func Exists(ks cav.IKripkeStructure, f *Formula) (cav.ISet[cav.IState], error) {
+	return ExistsWithValuation(ks, f, nil)
+}
+
+// ExistsWithValuation is Exists for a formula which may also refer to the labels of the valuation
+func ExistsWithValuation(ks cav.IKripkeStructure, f *Formula, valuation Valuation) (cav.ISet[cav.IState], error) {
+	hasLabel, err := resolveLabels(ks, f, valuation)
+	if err != nil {
+		return nil, err
+	}
+	p := makeProduct(MakeAutomaton(f), hasLabel, cav.SortedStates(ks.GetStates()))
+	fair, _ := p.fair()
+
+	result := cav.MakeSet[cav.IState]()
+	for state, vertices := range p.initial {
+		for _, v := range vertices {
+			if fair[v] {
+				result.Add(state)
+			}
+		}
+	}
+	return result, nil
+}
+
+// Counterexample returns an infinite path from one of the given states violating the formula, nil if there is none
+func Counterexample(ks cav.IKripkeStructure, f *Formula, states cav.ISet[cav.IState]) (*Lasso, error) {
+	hasLabel, err := resolveLabels(ks, f, nil)
+	if err != nil {
+		return nil, err
+	}
+	negated := MakeFormula(OpNot, f)
+	sorted := cav.SortedStates(states)
+	p := makeProduct(MakeAutomaton(negated), hasLabel, sorted)
+	_, onCycle := p.fair()
+	for _, state := range sorted {
+		if lasso := p.lasso(state, onCycle); lasso != nil {
+			return lasso, nil
+		}
+	}
+	return nil, nil
+}
+
+// LTLFormula is an LTL formula used as a state formula, holding in states all of whose infinite paths satisfy it
+type LTLFormula struct {
+	kripkeStructure cav.IKripkeStructure
+	formula         *Formula
+}
+
+// MakeLTLFormula checks that all labels of the formula exist in the Kripke structure
+func MakeLTLFormula(ks cav.IKripkeStructure, f *Formula) (*LTLFormula, error) {
+	if _, err := resolveLabels(ks, f, nil); err != nil {
+		return nil, err
+	}
+	return &LTLFormula{ks, f}, nil
+}
+
+func (f *LTLFormula) Check() cav.ISet[cav.IState] {
+	violating, _ := Exists(f.kripkeStructure, MakeFormula(OpNot, f.formula))
+	return f.kripkeStructure.GetStates().Minus(violating)
+}
+
+// Counterexample returns a path from an initial state violating the formula, nil if the formula holds
+func (f *LTLFormula) Counterexample() *Lasso {
+	lasso, _ := Counterexample(f.kripkeStructure, f.formula, f.kripkeStructure.GetInitialStates())
+	return lasso
+}
+
+func (f *LTLFormula) GetFormula() *Formula {
+	return f.formula
+}
+
+func (f *LTLFormula) GetKripkeStructure() cav.IKripkeStructure {
+	return f.kripkeStructure
+}
+
+func (f *LTLFormula) String() string {
+	return "LTL " + f.formula.String()
+}
@@ -0,0 +1,173 @@
+package ltl
+
+import "fmt"
+
+type Op int
+
+const (
+	OpTrue Op = iota
+	OpFalse
+	OpLabel
+	OpNot
+	OpAnd
+	OpOr
+	OpImplies
+	OpX
+	OpF
+	OpG
+	OpU
+	OpR
+	OpA // path quantifiers are only allowed in CTL* formulas
+	OpE
+)
+
+// Formula is an LTL path formula, Sub holding its operands
+type Formula struct {
+	Op    Op
+	Label string // only used by OpLabel
+	Sub   []*Formula
+}
+
+func MakeFormula(op Op, sub ...*Formula) *Formula {
+	return &Formula{Op: op, Sub: sub}
+}
+
+func MakeLabel(name string) *Formula {
+	return &Formula{Op: OpLabel, Label: name}
+}
+
+// String returns the formula in the syntax read by Parse, with every binary operator in brackets
+func (f *Formula) String() string {
+	switch f.Op {
+	case OpTrue:
+		return "true"
+	case OpFalse:
+		return "false"
+	case OpLabel:
+		return f.Label
+	case OpNot:
+		return "NOT " + f.Sub[0].String()
+	case OpAnd:
+		return fmt.Sprintf("(%s AND %s)", f.Sub[0].String(), f.Sub[1].String())
+	case OpOr:
+		return fmt.Sprintf("(%s OR %s)", f.Sub[0].String(), f.Sub[1].String())
+	case OpImplies:
+		return fmt.Sprintf("(%s IMPLIES %s)", f.Sub[0].String(), f.Sub[1].String())
+	case OpX:
+		return "X " + f.Sub[0].String()
+	case OpF:
+		return "F " + f.Sub[0].String()
+	case OpG:
+		return "G " + f.Sub[0].String()
+	case OpU:
+		return fmt.Sprintf("(%s U %s)", f.Sub[0].String(), f.Sub[1].String())
+	case OpR:
+		return fmt.Sprintf("(%s R %s)", f.Sub[0].String(), f.Sub[1].String())
+	case OpA:
+		return "A " + f.Sub[0].String()
+	case OpE:
+		return "E " + f.Sub[0].String()
+	}
+	return fmt.Sprintf("Op(%d)", int(f.Op))
+}
+
+// HasPathQuantifier reports whether the formula contains A or E and thus is no LTL formula
+func (f *Formula) HasPathQuantifier() bool {
+	if f.Op == OpA || f.Op == OpE {
+		return true
+	}
+	for _, sub := range f.Sub {
+		if sub.HasPathQuantifier() {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCTL reports whether the formula is a CTL formula, every path quantifier being followed by a single temporal
+// operator whose operands are state formulas again
+func (f *Formula) IsCTL() bool {
+	switch f.Op {
+	case OpX, OpF, OpG, OpU, OpR:
+		return false
+	case OpA, OpE:
+		path := f.Sub[0]
+		switch path.Op {
+		case OpX, OpF, OpG, OpU, OpR:
+			for _, sub := range path.Sub {
+				if !sub.IsCTL() {
+					return false
+				}
+			}
+			return true
+		}
+		return false
+	}
+	for _, sub := range f.Sub {
+		if !sub.IsCTL() {
+			return false
+		}
+	}
+	return true
+}
+
+// Labels returns the names of all labels the formula refers to
+func (f *Formula) Labels() []string {
+	if f.Op == OpLabel {
+		return []string{f.Label}
+	}
+	result := make([]string, 0)
+	for _, sub := range f.Sub {
+		result = append(result, sub.Labels()...)
+	}
+	return result
+}
+
+// NNF returns an equivalent formula built from true, false, (negated) labels, AND, OR, X, U and R only.
+// Since only infinite paths are considered, NOT X a equals X NOT a.
+func NNF(f *Formula) *Formula {
+	return nnf(f, false)
+}
+
+func nnf(f *Formula, negated bool) *Formula {
+	dual := func(op Op, dual Op, sub ...*Formula) *Formula {
+		if negated {
+			op = dual
+		}
+		return MakeFormula(op, sub...)
+	}
+	sub := func(i int) *Formula {
+		return nnf(f.Sub[i], negated)
+	}
+
+	switch f.Op {
+	case OpTrue:
+		return dual(OpTrue, OpFalse)
+	case OpFalse:
+		return dual(OpFalse, OpTrue)
+	case OpLabel:
+		if negated {
+			return MakeFormula(OpNot, MakeLabel(f.Label))
+		}
+		return MakeLabel(f.Label)
+	case OpNot:
+		return nnf(f.Sub[0], !negated)
+	case OpAnd:
+		return dual(OpAnd, OpOr, sub(0), sub(1))
+	case OpOr:
+		return dual(OpOr, OpAnd, sub(0), sub(1))
+	case OpImplies:
+		return dual(OpOr, OpAnd, nnf(f.Sub[0], !negated), sub(1))
+	case OpX:
+		return MakeFormula(OpX, sub(0))
+	case OpF:
+		return dual(OpU, OpR, nnf(MakeFormula(OpTrue), negated), sub(0))
+	case OpG:
+		return dual(OpR, OpU, nnf(MakeFormula(OpFalse), negated), sub(0))
+	case OpU:
+		return dual(OpU, OpR, sub(0), sub(1))
+	case OpR:
+		return dual(OpR, OpU, sub(0), sub(1))
+	}
+	panic(fmt.Sprintf("unknown operator: %d", int(f.Op)))
+}
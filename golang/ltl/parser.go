@@ -0,0 +1,216 @@
+package ltl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError is returned by Parse, Column being the 1-based byte offset into the parsed text
+type SyntaxError struct {
+	Column  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Message)
+}
+
+type token struct {
+	text   string
+	offset int
+}
+
+var symbols = []string{"->", "&&", "||", "(", ")", "[", "]", "!", "&", "|"}
+
+var keywords = map[string]string{
+	"->": "IMPLIES", "&&": "AND", "&": "AND", "||": "OR", "|": "OR", "!": "NOT", "[": "(", "]": ")",
+}
+
+// isOperatorWord reports whether a word like "GF" or "AG" is a sequence of prefix operators of CTL*
+func isOperatorWord(s string) bool {
+	return strings.Trim(s, "AEXFG") == ""
+}
+
+// tokenize splits s into tokens, and words of prefix operators into single operators if quantifiers is set
+func tokenize(s string, quantifiers bool) []token {
+	tokens := make([]token, 0)
+	for i := 0; i < len(s); {
+		if s[i] == ' ' || s[i] == '\t' {
+			i++
+			continue
+		}
+		symbol := ""
+		for _, candidate := range symbols {
+			if strings.HasPrefix(s[i:], candidate) {
+				symbol = candidate
+				break
+			}
+		}
+		if symbol != "" {
+			text := symbol
+			if keyword, ok := keywords[symbol]; ok {
+				text = keyword
+			}
+			tokens = append(tokens, token{text, i})
+			i += len(symbol)
+			continue
+		}
+		j := i
+		for j < len(s) && !strings.ContainsAny(s[j:j+1], " \t()[]!&|") && !strings.HasPrefix(s[j:], "->") {
+			j++
+		}
+		if quantifiers && isOperatorWord(s[i:j]) {
+			for k := i; k < j; k++ {
+				tokens = append(tokens, token{s[k : k+1], k})
+			}
+		} else {
+			tokens = append(tokens, token{s[i:j], i})
+		}
+		i = j
+	}
+	return tokens
+}
+
+type parser struct {
+	tokens      []token
+	pos         int
+	length      int
+	quantifiers bool
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos].text
+}
+
+func (p *parser) errorf(s string, ss ...any) error {
+	column := p.length + 1
+	if p.pos < len(p.tokens) {
+		column = p.tokens[p.pos].offset + 1
+	}
+	return &SyntaxError{column, fmt.Sprintf(s, ss...)}
+}
+
+// Parse parses an LTL formula like "G (req -> F grant)". Operators from weakest to strongest binding are
+// IMPLIES (->), OR (|), AND (&), the right associative U and R, and the prefix operators NOT (!), X, F and G.
+// Square brackets may be used instead of round ones.
+func Parse(s string) (*Formula, error) {
+	return parse(s, false)
+}
+
+// ParseCTLStar parses a CTL* formula like "A (GF p -> GF q)", which is an LTL formula with the path quantifiers
+// A and E as further prefix operators. Prefix operators may be written together like "GF" or "AG", so labels
+// consisting only of the letters A, E, X, F and G cannot be used.
+func ParseCTLStar(s string) (*Formula, error) {
+	return parse(s, true)
+}
+
+func parse(s string, quantifiers bool) (*Formula, error) {
+	p := &parser{tokenize(s, quantifiers), 0, len(s), quantifiers}
+	f, err := p.parseImplies()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.errorf("unexpected %s", p.peek())
+	}
+	return f, nil
+}
+
+func (p *parser) parseImplies() (*Formula, error) {
+	left, err := p.parseBinary(OpOr, "OR", p.parseAnd)
+	if err != nil || p.peek() != "IMPLIES" {
+		return left, err
+	}
+	p.pos++
+	right, err := p.parseImplies()
+	if err != nil {
+		return nil, err
+	}
+	return MakeFormula(OpImplies, left, right), nil
+}
+
+func (p *parser) parseAnd() (*Formula, error) {
+	return p.parseBinary(OpAnd, "AND", p.parseUntil)
+}
+
+// parseBinary parses a left associative chain of the given operator
+func (p *parser) parseBinary(op Op, keyword string, operand func() (*Formula, error)) (*Formula, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == keyword {
+		p.pos++
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = MakeFormula(op, left, right)
+	}
+	return left, nil
+}
+
+func (p *parser) parseUntil() (*Formula, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	op := OpU
+	switch p.peek() {
+	case "U":
+	case "R":
+		op = OpR
+	default:
+		return left, nil
+	}
+	p.pos++
+	right, err := p.parseUntil()
+	if err != nil {
+		return nil, err
+	}
+	return MakeFormula(op, left, right), nil
+}
+
+var unaryOps = map[string]Op{"NOT": OpNot, "X": OpX, "F": OpF, "G": OpG, "A": OpA, "E": OpE}
+
+func (p *parser) parseUnary() (*Formula, error) {
+	if op, ok := unaryOps[p.peek()]; ok && (p.quantifiers || (op != OpA && op != OpE)) {
+		p.pos++
+		sub, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return MakeFormula(op, sub), nil
+	}
+
+	text := p.peek()
+	switch text {
+	case "":
+		return nil, p.errorf("missing formula")
+	case "(":
+		p.pos++
+		f, err := p.parseImplies()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, p.errorf("expected \")\"")
+		}
+		p.pos++
+		return f, nil
+	case ")", "AND", "OR", "IMPLIES", "U", "R":
+		return nil, p.errorf("unexpected %s", text)
+	}
+
+	p.pos++
+	switch text {
+	case "true":
+		return MakeFormula(OpTrue), nil
+	case "false":
+		return MakeFormula(OpFalse), nil
+	}
+	return MakeLabel(text), nil
+}
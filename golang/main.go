@@ -1,28 +1,87 @@
 package main
 
 import (
+	"cav/golang/atl"
+	"cav/golang/lsp"
+	"cav/golang/ltl"
 	"cav/golang/parser"
+	"cav/golang/pctl"
+	"cav/golang/types"
+	"flag"
 	"fmt"
+	"math"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 func main() {
+	if len(os.Args) >= 2 {
+		switch os.Args[1] {
+		case "serve":
+			os.Exit(serve(os.Args[2:]))
+		case "test":
+			os.Exit(regression(os.Args[2:]))
+		case "generate":
+			os.Exit(generate(os.Args[2:]))
+		case "normalize":
+			os.Exit(normalize(os.Args[2:]))
+		case "coverage":
+			os.Exit(coverageReport(os.Args[2:]))
+		case "explain":
+			os.Exit(explainFormulas(os.Args[2:]))
+		case "trace":
+			os.Exit(traceFixpoints(os.Args[2:]))
+		case "distance":
+			os.Exit(distanceReport(os.Args[2:]))
+		case "lsp":
+			// stdout belongs to the language client, so nothing else may be printed
+			if err := lsp.MakeServer(os.Stdin, os.Stdout).Run(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	for _, arg := range os.Args {
 		fmt.Print(" " + arg)
 	}
 	fmt.Println()
 
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: main <file>")
+	flags := flag.NewFlagSet("main", flag.ContinueOnError)
+	checkVacuity := flags.Bool("vacuity", false, "report label occurrences which do not affect formulas that hold")
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		os.Exit(2)
+	}
+
+	if flags.NArg() < 1 {
+		fmt.Println("Usage: main [--vacuity] <file>")
+		fmt.Println("       main serve [-addr <address>] [-cache <size>]")
+		fmt.Println("       main lsp")
+		fmt.Println("       main test <file>...")
+		fmt.Println("       main generate [-states <n>] [-sccs <n>] [-seed <n>] ...")
+		fmt.Println("       main normalize [-form nnf|enf|simplify|canonical] <file>")
+		fmt.Println("       main coverage [-dot <file>] <file>")
+		fmt.Println("       main explain [-json] [-formula <formula>] -state <state> <file>")
+		fmt.Println("       main trace [-formula <formula>] [-dot <directory>] <file>")
+		fmt.Println("       main distance [-formula <formula>] [-cost] <file>")
 		os.Exit(1)
 	}
 
-	file := os.Args[1]
+	file := flags.Arg(0)
 
 	wd, _ := os.Getwd()
 	fmt.Println("Working directory: " + wd)
 
-	_, flas, err := parser.PARSER.ParseFile(fmt.Sprintf("%s/%s", wd, file))
+	if !filepath.IsAbs(file) {
+		file = fmt.Sprintf("%s/%s", wd, file)
+	}
+
+	p := parser.MakeFileParser()
+	ks, flas, err := p.ParseFile(file)
 	if err != nil {
 		fmt.Println("Failed to parse file:")
 		fmt.Println(err)
@@ -30,8 +89,107 @@ func main() {
 	}
 
 	fmt.Println("Formula Results:")
-	for _, fla := range flas {
+	for i, fla := range flas {
 		fmt.Println(fla.String() + ":")
 		fmt.Println(fla.Check().String())
+		if formula, ok := fla.(*ltl.LTLFormula); ok {
+			if lasso := formula.Counterexample(); lasso != nil {
+				fmt.Println("Counterexample: " + lasso.String())
+			}
+		}
+		if formula, ok := fla.(*pctl.PCTLFormula); ok {
+			printValues("Probabilities", formula.Probabilities(), ks)
+			printValues("Expected rewards", formula.Rewards(), ks)
+			printScheduler(formula.Scheduler())
+			printConvergence(formula.Convergence())
+		}
+		if formula, ok := fla.(*atl.ATLFormula); ok {
+			printStrategy(formula.Strategy())
+		}
+		if *checkVacuity {
+			printVacuity(p.GetNodes()[i], ks)
+		}
+	}
+}
+
+// printValues prints the value computed for each state sorted by name, followed by the expected value for a
+// uniform distribution over the initial states
+func printValues(heading string, values map[cav.IState]float64, ks cav.IKripkeStructure) {
+	if values == nil {
+		return
+	}
+	states := make([]cav.IState, 0, len(values))
+	for state := range values {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].GetName() < states[j].GetName()
+	})
+	fmt.Println(heading + ":")
+	for _, state := range states {
+		fmt.Printf("  %s: %s\n", state.GetName(), formatValue(values[state]))
+	}
+	sum := 0.0
+	ks.GetInitialStates().ForEach(func(state cav.IState) {
+		sum += values[state]
+	})
+	fmt.Println("Initial distribution: " + formatValue(sum/float64(ks.GetInitialStates().Size())))
+}
+
+// printScheduler prints the action the scheduler picks in each state sorted by name
+func printScheduler(scheduler map[cav.IState]string) {
+	if scheduler == nil {
+		return
+	}
+	states := make([]cav.IState, 0, len(scheduler))
+	for state := range scheduler {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].GetName() < states[j].GetName()
+	})
+	fmt.Println("Scheduler:")
+	for _, state := range states {
+		fmt.Printf("  %s: %s\n", state.GetName(), scheduler[state])
+	}
+}
+
+// printStrategy prints the actions the agents of the coalition choose in each state sorted by name
+func printStrategy(strategy *cav.Strategy) {
+	if strategy == nil || len(strategy.Agents) <= 0 || len(strategy.Actions) <= 0 {
+		return
+	}
+	states := make([]cav.IState, 0, len(strategy.Actions))
+	for state := range strategy.Actions {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].GetName() < states[j].GetName()
+	})
+	fmt.Println("Strategy:")
+	for _, state := range states {
+		choices := make([]string, len(strategy.Agents))
+		for i, agent := range strategy.Agents {
+			choices[i] = agent + "=" + strategy.Actions[state][i]
+		}
+		fmt.Printf("  %s: %s\n", state.GetName(), strings.Join(choices, ", "))
+	}
+}
+
+func printConvergence(convergence *pctl.Convergence) {
+	if convergence == nil {
+		return
+	}
+	if !convergence.Converged {
+		fmt.Printf("Did not converge after %d iterations, last change %g\n", convergence.Iterations, convergence.Change)
+		return
+	}
+	fmt.Printf("Converged after %d iterations, last change %g\n", convergence.Iterations, convergence.Change)
+}
+
+func formatValue(value float64) string {
+	if math.IsInf(value, 1) {
+		return "infinity"
 	}
+	return strconv.FormatFloat(value, 'g', 6, 64)
 }
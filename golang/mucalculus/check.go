@@ -0,0 +1,153 @@
+package mucalculus
+
+import (
+	"cav/golang/types"
+	"fmt"
+)
+
+// MuFormula is a closed mu-calculus formula used as a state formula
+type MuFormula struct {
+	kripkeStructure cav.IKripkeStructure
+	formula         *Formula
+	pnf             *Formula
+}
+
+// MakeMuFormula checks that all labels of the formula exist in the Kripke structure and that it is monotone in its variables
+func MakeMuFormula(ks cav.IKripkeStructure, f *Formula) (*MuFormula, error) {
+	labels := map[string]bool{}
+	ks.GetLabels().ForEach(func(label cav.ILabel) {
+		labels[label.String()] = true
+	})
+	for _, name := range f.Labels() {
+		if !labels[name] {
+			return nil, fmt.Errorf("unknown label in formula: %s", name)
+		}
+	}
+	pnf, err := PNF(f)
+	if err != nil {
+		return nil, err
+	}
+	return &MuFormula{ks, f, pnf}, nil
+}
+
+// evaluator implements the algorithm of Emerson and Lei: the approximation of a fixpoint is kept across the
+// iterations of enclosing fixpoints of the same kind, and only restarted when an enclosing fixpoint of the other
+// kind has changed. This needs O(n^d) iterations for alternation depth d instead of O(n^k) for k nested fixpoints.
+type evaluator struct {
+	ks      cav.IKripkeStructure
+	labels  map[string]cav.ILabel
+	binders map[*Formula]*Formula
+	approx  map[*Formula]cav.ISet[cav.IState]
+}
+
+func makeEvaluator(ks cav.IKripkeStructure, f *Formula) *evaluator {
+	e := &evaluator{ks, map[string]cav.ILabel{}, map[*Formula]*Formula{}, map[*Formula]cav.ISet[cav.IState]{}}
+	ks.GetLabels().ForEach(func(label cav.ILabel) {
+		e.labels[label.String()] = label
+	})
+	e.bind(f, map[string]*Formula{})
+	e.reset(f, OpMu)
+	e.reset(f, OpNu)
+	return e
+}
+
+// bind maps every variable to the fixpoint binding it
+func (e *evaluator) bind(f *Formula, scope map[string]*Formula) {
+	switch f.Op {
+	case OpVar:
+		e.binders[f] = scope[f.Name]
+	case OpMu, OpNu:
+		outer := scope[f.Name]
+		scope[f.Name] = f
+		e.bind(f.Sub[0], scope)
+		scope[f.Name] = outer
+	default:
+		for _, sub := range f.Sub {
+			e.bind(sub, scope)
+		}
+	}
+}
+
+// reset restarts all fixpoints of the given kind within f, least ones from no state and greatest ones from all states
+func (e *evaluator) reset(f *Formula, op Op) {
+	if f.Op == op {
+		if op == OpMu {
+			e.approx[f] = cav.MakeSet[cav.IState]()
+		} else {
+			e.approx[f] = e.ks.GetStates().Copy()
+		}
+	}
+	for _, sub := range f.Sub {
+		e.reset(sub, op)
+	}
+}
+
+func (e *evaluator) eval(f *Formula) cav.ISet[cav.IState] {
+	states := e.ks.GetStates()
+	switch f.Op {
+	case OpTrue:
+		return states.Copy()
+	case OpFalse:
+		return cav.MakeSet[cav.IState]()
+	case OpLabel:
+		result := cav.MakeSet[cav.IState]()
+		states.ForEach(func(state cav.IState) {
+			if state.HasLabel(e.labels[f.Name]) {
+				result.Add(state)
+			}
+		})
+		return result
+	case OpVar:
+		return e.approx[e.binders[f]]
+	case OpNot:
+		return states.Minus(e.eval(f.Sub[0]))
+	case OpAnd:
+		return e.eval(f.Sub[0]).Intersect(e.eval(f.Sub[1]))
+	case OpOr:
+		return e.eval(f.Sub[0]).Union(e.eval(f.Sub[1]))
+	case OpDiamond, OpBox:
+		sub := e.eval(f.Sub[0])
+		result := cav.MakeSet[cav.IState]()
+		states.ForEach(func(state cav.IState) {
+			some, all := false, true
+			state.GetChildren().ForEach(func(child cav.IState) {
+				if sub.Contains(child) {
+					some = true
+				} else {
+					all = false
+				}
+			})
+			if (f.Op == OpDiamond && some) || (f.Op == OpBox && all) {
+				result.Add(state)
+			}
+		})
+		return result
+	case OpMu, OpNu:
+		other := OpMu + OpNu - f.Op
+		for {
+			next := e.eval(f.Sub[0])
+			if next.Equals(e.approx[f]) {
+				return next
+			}
+			e.approx[f] = next
+			e.reset(f.Sub[0], other)
+		}
+	}
+	panic(fmt.Sprintf("not in positive normal form: %s", f.String()))
+}
+
+func (f *MuFormula) Check() cav.ISet[cav.IState] {
+	return makeEvaluator(f.kripkeStructure, f.pnf).eval(f.pnf)
+}
+
+func (f *MuFormula) GetFormula() *Formula {
+	return f.formula
+}
+
+func (f *MuFormula) GetKripkeStructure() cav.IKripkeStructure {
+	return f.kripkeStructure
+}
+
+func (f *MuFormula) String() string {
+	return "MU " + f.formula.String()
+}
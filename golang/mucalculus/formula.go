@@ -0,0 +1,166 @@
+package mucalculus
+
+import "fmt"
+
+type Op int
+
+const (
+	OpTrue Op = iota
+	OpFalse
+	OpLabel
+	OpVar
+	OpNot
+	OpAnd
+	OpOr
+	OpImplies
+	OpDiamond // <> φ: some successor satisfies φ
+	OpBox     // [] φ: all successors satisfy φ
+	OpMu
+	OpNu
+)
+
+// Formula is a modal mu-calculus formula, Name being the label or variable of OpLabel, OpVar, OpMu and OpNu
+type Formula struct {
+	Op   Op
+	Name string
+	Sub  []*Formula
+}
+
+func MakeFormula(op Op, sub ...*Formula) *Formula {
+	return &Formula{Op: op, Sub: sub}
+}
+
+func MakeLabel(name string) *Formula {
+	return &Formula{Op: OpLabel, Name: name}
+}
+
+func MakeVar(name string) *Formula {
+	return &Formula{Op: OpVar, Name: name}
+}
+
+// MakeFixpoint returns mu name. body or nu name. body, op being OpMu or OpNu
+func MakeFixpoint(op Op, name string, body *Formula) *Formula {
+	return &Formula{Op: op, Name: name, Sub: []*Formula{body}}
+}
+
+// String returns the formula in the syntax read by Parse, with every binary operator and fixpoint in brackets
+func (f *Formula) String() string {
+	switch f.Op {
+	case OpTrue:
+		return "true"
+	case OpFalse:
+		return "false"
+	case OpLabel, OpVar:
+		return f.Name
+	case OpNot:
+		return "NOT " + f.Sub[0].String()
+	case OpAnd:
+		return fmt.Sprintf("(%s AND %s)", f.Sub[0].String(), f.Sub[1].String())
+	case OpOr:
+		return fmt.Sprintf("(%s OR %s)", f.Sub[0].String(), f.Sub[1].String())
+	case OpImplies:
+		return fmt.Sprintf("(%s IMPLIES %s)", f.Sub[0].String(), f.Sub[1].String())
+	case OpDiamond:
+		return "<> " + f.Sub[0].String()
+	case OpBox:
+		return "[] " + f.Sub[0].String()
+	case OpMu:
+		return fmt.Sprintf("(mu %s. %s)", f.Name, f.Sub[0].String())
+	case OpNu:
+		return fmt.Sprintf("(nu %s. %s)", f.Name, f.Sub[0].String())
+	}
+	return fmt.Sprintf("Op(%d)", int(f.Op))
+}
+
+// Labels returns the names of all labels the formula refers to
+func (f *Formula) Labels() []string {
+	if f.Op == OpLabel {
+		return []string{f.Name}
+	}
+	result := make([]string, 0)
+	for _, sub := range f.Sub {
+		result = append(result, sub.Labels()...)
+	}
+	return result
+}
+
+// PNF returns an equivalent formula in positive normal form, negating labels only.
+// An error is returned if a variable occurs below an odd number of negations inside its fixpoint.
+func PNF(f *Formula) (*Formula, error) {
+	return pnf(f, false, map[string]bool{})
+}
+
+// pnf negates f if negated is set, polarity holding whether the fixpoint binding each variable has been negated
+func pnf(f *Formula, negated bool, polarity map[string]bool) (*Formula, error) {
+	dual := func(op Op, dual Op, sub ...*Formula) *Formula {
+		if negated {
+			op = dual
+		}
+		return MakeFormula(op, sub...)
+	}
+	operands := func(negations ...bool) ([]*Formula, error) {
+		result := make([]*Formula, len(f.Sub))
+		for i, sub := range f.Sub {
+			var err error
+			if result[i], err = pnf(sub, negations[i], polarity); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
+	switch f.Op {
+	case OpTrue:
+		return dual(OpTrue, OpFalse), nil
+	case OpFalse:
+		return dual(OpFalse, OpTrue), nil
+	case OpLabel:
+		if negated {
+			return MakeFormula(OpNot, MakeLabel(f.Name)), nil
+		}
+		return MakeLabel(f.Name), nil
+	case OpVar:
+		if polarity[f.Name] != negated {
+			return nil, fmt.Errorf("variable %s occurs below an odd number of negations", f.Name)
+		}
+		return MakeVar(f.Name), nil
+	case OpNot:
+		return pnf(f.Sub[0], !negated, polarity)
+	case OpAnd, OpOr, OpImplies, OpDiamond, OpBox:
+		negations := []bool{negated, negated}
+		if f.Op == OpImplies {
+			negations[0] = !negated
+		}
+		sub, err := operands(negations...)
+		if err != nil {
+			return nil, err
+		}
+		switch f.Op {
+		case OpAnd:
+			return dual(OpAnd, OpOr, sub...), nil
+		case OpOr, OpImplies:
+			return dual(OpOr, OpAnd, sub...), nil
+		case OpDiamond:
+			return dual(OpDiamond, OpBox, sub...), nil
+		}
+		return dual(OpBox, OpDiamond, sub...), nil
+	case OpMu, OpNu:
+		outer, shadowed := polarity[f.Name]
+		polarity[f.Name] = negated
+		body, err := pnf(f.Sub[0], negated, polarity)
+		if shadowed {
+			polarity[f.Name] = outer
+		} else {
+			delete(polarity, f.Name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		op := f.Op
+		if negated {
+			op = OpMu + OpNu - f.Op
+		}
+		return MakeFixpoint(op, f.Name, body), nil
+	}
+	panic(fmt.Sprintf("unknown operator: %d", int(f.Op)))
+}
@@ -0,0 +1,222 @@
+package mucalculus
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError is returned by Parse, Column being the 1-based byte offset into the parsed text
+type SyntaxError struct {
+	Column  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Message)
+}
+
+type token struct {
+	text   string
+	offset int
+}
+
+var symbols = []string{"<>", "[]", "->", "&&", "||", "(", ")", "!", "&", "|", "."}
+
+var keywords = map[string]string{
+	"->": "IMPLIES", "&&": "AND", "&": "AND", "||": "OR", "|": "OR", "!": "NOT",
+}
+
+func tokenize(s string) []token {
+	tokens := make([]token, 0)
+	for i := 0; i < len(s); {
+		if s[i] == ' ' || s[i] == '\t' {
+			i++
+			continue
+		}
+		symbol := ""
+		for _, candidate := range symbols {
+			if strings.HasPrefix(s[i:], candidate) {
+				symbol = candidate
+				break
+			}
+		}
+		if symbol != "" {
+			text := symbol
+			if keyword, ok := keywords[symbol]; ok {
+				text = keyword
+			}
+			tokens = append(tokens, token{text, i})
+			i += len(symbol)
+			continue
+		}
+		j := i
+		for j < len(s) && !strings.ContainsAny(s[j:j+1], " \t()!&|.<[") && !strings.HasPrefix(s[j:], "->") {
+			j++
+		}
+		if j == i {
+			j++
+		}
+		tokens = append(tokens, token{s[i:j], i})
+		i = j
+	}
+	return tokens
+}
+
+type parser struct {
+	tokens    []token
+	pos       int
+	length    int
+	variables map[string]int
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos].text
+}
+
+func (p *parser) errorf(s string, ss ...any) error {
+	column := p.length + 1
+	if p.pos < len(p.tokens) {
+		column = p.tokens[p.pos].offset + 1
+	}
+	return &SyntaxError{column, fmt.Sprintf(s, ss...)}
+}
+
+// Parse parses a mu-calculus formula like "nu X. p AND [] X". Operators from weakest to strongest binding are
+// the fixpoints mu and nu, whose body extends as far to the right as possible, IMPLIES (->), OR (|), AND (&),
+// and the prefix operators NOT (!), <> and []. Names bound by an enclosing fixpoint are variables, all others labels.
+func Parse(s string) (*Formula, error) {
+	p := &parser{tokenize(s), 0, len(s), map[string]int{}}
+	f, err := p.parseFixpoint()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.errorf("unexpected %s", p.peek())
+	}
+	return f, nil
+}
+
+func (p *parser) parseFixpoint() (*Formula, error) {
+	op := OpMu
+	switch p.peek() {
+	case "mu":
+	case "nu":
+		op = OpNu
+	default:
+		return p.parseImplies()
+	}
+	p.pos++
+	name := p.peek()
+	if !isName(name) {
+		return nil, p.errorf("expected a variable")
+	}
+	p.pos++
+	if p.peek() != "." {
+		return nil, p.errorf("expected \".\"")
+	}
+	p.pos++
+	p.variables[name]++
+	body, err := p.parseFixpoint()
+	p.variables[name]--
+	if err != nil {
+		return nil, err
+	}
+	return MakeFixpoint(op, name, body), nil
+}
+
+func (p *parser) parseImplies() (*Formula, error) {
+	left, err := p.parseBinary(OpOr, "OR", p.parseAnd)
+	if err != nil || p.peek() != "IMPLIES" {
+		return left, err
+	}
+	p.pos++
+	right, err := p.parseOperand(p.parseImplies)
+	if err != nil {
+		return nil, err
+	}
+	return MakeFormula(OpImplies, left, right), nil
+}
+
+func (p *parser) parseAnd() (*Formula, error) {
+	return p.parseBinary(OpAnd, "AND", p.parseUnary)
+}
+
+// parseOperand parses a right operand, which may also be a fixpoint extending to the end
+func (p *parser) parseOperand(operand func() (*Formula, error)) (*Formula, error) {
+	if p.peek() == "mu" || p.peek() == "nu" {
+		return p.parseFixpoint()
+	}
+	return operand()
+}
+
+// parseBinary parses a left associative chain of the given operator
+func (p *parser) parseBinary(op Op, keyword string, operand func() (*Formula, error)) (*Formula, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == keyword {
+		p.pos++
+		right, err := p.parseOperand(operand)
+		if err != nil {
+			return nil, err
+		}
+		left = MakeFormula(op, left, right)
+	}
+	return left, nil
+}
+
+var unaryOps = map[string]Op{"NOT": OpNot, "<>": OpDiamond, "[]": OpBox}
+
+func (p *parser) parseUnary() (*Formula, error) {
+	if op, ok := unaryOps[p.peek()]; ok {
+		p.pos++
+		sub, err := p.parseOperand(p.parseUnary)
+		if err != nil {
+			return nil, err
+		}
+		return MakeFormula(op, sub), nil
+	}
+
+	text := p.peek()
+	switch text {
+	case "":
+		return nil, p.errorf("missing formula")
+	case "(":
+		p.pos++
+		f, err := p.parseFixpoint()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, p.errorf("expected \")\"")
+		}
+		p.pos++
+		return f, nil
+	}
+	if !isName(text) {
+		return nil, p.errorf("unexpected %s", text)
+	}
+
+	p.pos++
+	switch {
+	case text == "true":
+		return MakeFormula(OpTrue), nil
+	case text == "false":
+		return MakeFormula(OpFalse), nil
+	case p.variables[text] > 0:
+		return MakeVar(text), nil
+	}
+	return MakeLabel(text), nil
+}
+
+func isName(s string) bool {
+	switch s {
+	case "", "mu", "nu", "AND", "OR", "IMPLIES", "NOT":
+		return false
+	}
+	return !strings.ContainsAny(s, "()!&|.<>[]")
+}
@@ -0,0 +1,158 @@
+package mucalculus
+
+import (
+	"cav/golang/ast"
+	"cav/golang/types"
+	"fmt"
+)
+
+// maxUnrolling is the largest upper bound of a bounded operator that is unrolled, as the translation grows linearly with it
+const maxUnrolling = 1000
+
+type translator struct {
+	labels map[string]bool
+	next   int
+}
+
+// variable returns a fresh variable which does not clash with any label of the translated formula
+func (t *translator) variable() string {
+	for {
+		t.next++
+		name := fmt.Sprintf("Z%d", t.next)
+		if !t.labels[name] {
+			return name
+		}
+	}
+}
+
+// FromCTL translates a CTL formula into an equivalent mu-calculus formula. The universal operators are translated
+// using the same equivalences as the Kripke structure, so both agree in states without successors as well.
+// Bounded operators are unrolled step by step, while past and cost-bounded operators are unsupported, since the
+// mu-calculus has neither converse modalities nor weights.
+func FromCTL(n *ast.Node) (*Formula, error) {
+	t := &translator{labels: map[string]bool{}}
+	ast.Inspect(n, func(n *ast.Node) bool {
+		if n.Kind == ast.Label {
+			t.labels[n.Name] = true
+		}
+		return true
+	})
+	return t.translate(n)
+}
+
+func (t *translator) translate(n *ast.Node) (*Formula, error) {
+	operands := make([]*Formula, len(n.Operands))
+	for i, operand := range n.Operands {
+		var err error
+		if operands[i], err = t.translate(operand); err != nil {
+			return nil, err
+		}
+	}
+	not := func(f *Formula) *Formula {
+		return MakeFormula(OpNot, f)
+	}
+	// eu returns mu Z. b OR (a AND <> Z)
+	eu := func(a *Formula, b *Formula) *Formula {
+		z := t.variable()
+		return MakeFixpoint(OpMu, z, MakeFormula(OpOr, b, MakeFormula(OpAnd, a, MakeFormula(OpDiamond, MakeVar(z)))))
+	}
+	// eg returns nu Z. a AND <> Z
+	eg := func(a *Formula) *Formula {
+		z := t.variable()
+		return MakeFixpoint(OpNu, z, MakeFormula(OpAnd, a, MakeFormula(OpDiamond, MakeVar(z))))
+	}
+
+	switch n.Kind {
+	case ast.Label:
+		return MakeLabel(n.Name), nil
+	case ast.True:
+		return MakeFormula(OpTrue), nil
+	case ast.False:
+		return MakeFormula(OpFalse), nil
+	case ast.Not:
+		return not(operands[0]), nil
+	case ast.And:
+		return MakeFormula(OpAnd, operands...), nil
+	case ast.Or:
+		return MakeFormula(OpOr, operands...), nil
+	case ast.Implies:
+		return MakeFormula(OpImplies, operands...), nil
+	case ast.EX:
+		return MakeFormula(OpDiamond, operands[0]), nil
+	case ast.EG:
+		return eg(operands[0]), nil
+	case ast.EF:
+		return eu(MakeFormula(OpTrue), operands[0]), nil
+	case ast.EU:
+		return eu(operands[0], operands[1]), nil
+	case ast.ER:
+		// NOT A[NOT a U NOT b]
+		a, b := not(operands[0]), not(operands[1])
+		return not(MakeFormula(OpAnd, not(eu(not(b), MakeFormula(OpAnd, not(a), not(b)))), not(eg(not(b))))), nil
+	case ast.AX:
+		return MakeFormula(OpBox, operands[0]), nil
+	case ast.AG:
+		return not(eu(MakeFormula(OpTrue), not(operands[0]))), nil
+	case ast.AF:
+		return not(eg(not(operands[0]))), nil
+	case ast.AU:
+		a, b := operands[0], operands[1]
+		return MakeFormula(OpAnd, not(eu(not(b), MakeFormula(OpAnd, not(a), not(b)))), not(eg(not(b)))), nil
+	case ast.AR:
+		return not(eu(not(operands[0]), not(operands[1]))), nil
+	case ast.EY, ast.EH, ast.ES, ast.AY, ast.AH, ast.AS:
+		return nil, fmt.Errorf("unsupported operator %s: the mu-calculus has no past modalities", n.Kind.String())
+	case ast.CostEF, ast.CostAF, ast.CostEG, ast.CostAG:
+		return nil, fmt.Errorf("unsupported operator %s[cost<=%d]: the mu-calculus has no weights", n.Kind.String(), n.Bound.Upper)
+	}
+	if n.Bound.Upper > maxUnrolling {
+		return nil, fmt.Errorf("unsupported operator %s%s: only bounds up to %d are unrolled", n.Kind.String(), n.Bound.String(), maxUnrolling)
+	}
+	// every copy of an operand gets its own fixpoint variables
+	operand := func(i int) func() *Formula {
+		return func() *Formula {
+			f, _ := t.translate(n.Operands[i])
+			return f
+		}
+	}
+	notOperand := func(i int) func() *Formula {
+		return func() *Formula {
+			return not(operand(i)())
+		}
+	}
+	switch n.Kind {
+	case ast.BoundedEF:
+		return boundedUntil(n.Bound, OpDiamond, constant(OpTrue), operand(0)), nil
+	case ast.BoundedAF:
+		return boundedUntil(n.Bound, OpBox, constant(OpTrue), operand(0)), nil
+	case ast.BoundedEG:
+		return not(boundedUntil(n.Bound, OpBox, constant(OpTrue), notOperand(0))), nil
+	case ast.BoundedAG:
+		return not(boundedUntil(n.Bound, OpDiamond, constant(OpTrue), notOperand(0))), nil
+	case ast.BoundedEU:
+		return boundedUntil(n.Bound, OpDiamond, operand(0), operand(1)), nil
+	case ast.BoundedAU:
+		return boundedUntil(n.Bound, OpBox, operand(0), operand(1)), nil
+	}
+	return nil, fmt.Errorf("cannot translate %s to the mu-calculus", n.String())
+}
+
+func constant(op Op) func() *Formula {
+	return func() *Formula {
+		return MakeFormula(op)
+	}
+}
+
+// boundedUntil unrolls a U b within the bound like the Kripke structure checks it, stepping to some successor with
+// OpDiamond and to all successors with OpBox: b OR (a AND next) up to the upper bound, then a AND next down to the
+// lower bound
+func boundedUntil(bound cav.Bound, modality Op, a func() *Formula, b func() *Formula) *Formula {
+	z := b()
+	for i := bound.Lower; i < bound.Upper; i++ {
+		z = MakeFormula(OpOr, b(), MakeFormula(OpAnd, a(), MakeFormula(modality, z)))
+	}
+	for i := 0; i < bound.Lower; i++ {
+		z = MakeFormula(OpAnd, a(), MakeFormula(modality, z))
+	}
+	return z
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"cav/golang/ast"
+	"cav/golang/parser"
+	"cav/golang/transform"
+	"flag"
+	"fmt"
+)
+
+var normalForms = map[string]func(*ast.Node) *ast.Node{
+	"nnf": transform.NNF,
+	"enf": transform.ENF,
+	"simplify": func(n *ast.Node) *ast.Node {
+		return transform.Simplify(n)
+	},
+	// simplifying the NNF also catches contradictions hidden behind negations
+	"canonical": func(n *ast.Node) *ast.Node {
+		return transform.Simplify(transform.NNF(transform.Simplify(n)))
+	},
+}
+
+func normalize(args []string) int {
+	flags := flag.NewFlagSet("normalize", flag.ContinueOnError)
+	form := flags.String("form", "canonical", "normal form: nnf, enf, simplify or canonical")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	transformation, ok := normalForms[*form]
+	if !ok || flags.NArg() != 1 {
+		fmt.Println("Usage: main normalize [-form nnf|enf|simplify|canonical] <file>")
+		return 2
+	}
+
+	p := parser.MakeFileParser()
+	_, formulas, err := p.ParseFile(flags.Arg(0))
+	if err != nil {
+		fmt.Println("Failed to parse file:")
+		fmt.Println(err)
+		return 1
+	}
+	for i, node := range p.GetNodes() {
+		if node == nil {
+			// LTL formulas are left as they are
+			fmt.Println(formulas[i].String())
+			continue
+		}
+		fmt.Println(transformation(node).String())
+	}
+	return 0
+}
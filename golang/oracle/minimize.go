@@ -0,0 +1,125 @@
+package oracle
+
+// Minimize greedily shrinks the model and the formula as long as failing keeps returning true
+func Minimize(m *Model, f *Formula, failing func(*Model, *Formula) bool) (*Model, *Formula) {
+	for changed := true; changed; {
+		changed = false
+
+		for _, candidate := range smallerFormulas(f) {
+			if failing(m, candidate) {
+				f = candidate
+				changed = true
+				break
+			}
+		}
+		if changed {
+			continue
+		}
+
+		for _, candidate := range smallerModels(m) {
+			if candidate.isTotal() && failing(candidate, f) {
+				m = candidate
+				changed = true
+				break
+			}
+		}
+	}
+	return m, f
+}
+
+// smallerFormulas returns all formulas in which one subformula has been replaced by one of its operands or a constant
+func smallerFormulas(f *Formula) []*Formula {
+	result := make([]*Formula, 0)
+	if f.Op != OpTrue && f.Op != OpFalse {
+		result = append(result, &Formula{Op: OpTrue}, &Formula{Op: OpFalse})
+	}
+	result = append(result, f.Sub...)
+	for i, sub := range f.Sub {
+		for _, smaller := range smallerFormulas(sub) {
+			copied := &Formula{Op: f.Op, Label: f.Label, Sub: append([]*Formula{}, f.Sub...)}
+			copied.Sub[i] = smaller
+			result = append(result, copied)
+		}
+	}
+	return result
+}
+
+// smallerModels returns all models with one state, transition or label assignment less,
+// states which lose their only successor get a self loop instead
+func smallerModels(m *Model) []*Model {
+	result := make([]*Model, 0)
+	n := len(m.Edges)
+
+	if n > 1 {
+		for removed := 0; removed < n; removed++ {
+			result = append(result, m.withoutState(removed))
+		}
+	}
+
+	for i, children := range m.Edges {
+		for j := range children {
+			copied := m.copy()
+			copied.Edges[i] = append(append([]int{}, children[:j]...), children[j+1:]...)
+			result = append(result, copied)
+		}
+	}
+
+	for _, name := range sortedKeys(m.Labels) {
+		for i, holds := range m.Labels[name] {
+			if holds {
+				copied := m.copy()
+				copied.Labels[name][i] = false
+				result = append(result, copied)
+			}
+		}
+	}
+	return result
+}
+
+func (m *Model) copy() *Model {
+	copied := &Model{
+		Edges:  make([][]int, len(m.Edges)),
+		Labels: map[string][]bool{},
+	}
+	for i, children := range m.Edges {
+		copied.Edges[i] = append([]int{}, children...)
+	}
+	for name, holds := range m.Labels {
+		copied.Labels[name] = append([]bool{}, holds...)
+	}
+	return copied
+}
+
+func (m *Model) withoutState(removed int) *Model {
+	rename := func(i int) int {
+		if i > removed {
+			return i - 1
+		}
+		return i
+	}
+
+	result := &Model{
+		Edges:  make([][]int, 0, len(m.Edges)-1),
+		Labels: map[string][]bool{},
+	}
+	for i, children := range m.Edges {
+		if i == removed {
+			continue
+		}
+		edges := make([]int, 0, len(children))
+		for _, child := range children {
+			if child != removed {
+				edges = append(edges, rename(child))
+			}
+		}
+		if len(edges) <= 0 {
+			// keep the model total
+			edges = append(edges, rename(i))
+		}
+		result.Edges = append(result.Edges, edges)
+	}
+	for name, holds := range m.Labels {
+		result.Labels[name] = append(append([]bool{}, holds[:removed]...), holds[removed+1:]...)
+	}
+	return result
+}
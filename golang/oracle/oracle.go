@@ -0,0 +1,376 @@
+package oracle
+
+import (
+	"cav/golang/types"
+	"fmt"
+	"strings"
+)
+
+type Op int
+
+const (
+	OpLabel Op = iota
+	OpTrue
+	OpFalse
+	OpNot
+	OpAnd
+	OpOr
+	OpEX
+	OpEG
+	OpEF
+	OpEU
+	OpER
+	OpAX
+	OpAG
+	OpAF
+	OpAU
+	OpAR
+)
+
+// Formula is a plain CTL syntax tree which the oracle evaluates independently of formula.go
+type Formula struct {
+	Op    Op
+	Label string
+	Sub   []*Formula
+}
+
+// Model is an explicit Kripke structure with states 0..len(Edges)-1
+type Model struct {
+	Edges  [][]int
+	Labels map[string][]bool
+}
+
+func (f *Formula) String() string {
+	switch f.Op {
+	case OpLabel:
+		return f.Label
+	case OpTrue:
+		return "true"
+	case OpFalse:
+		return "false"
+	case OpNot:
+		return fmt.Sprintf("NOT (%s)", f.Sub[0].String())
+	case OpAnd:
+		return fmt.Sprintf("(%s) AND (%s)", f.Sub[0].String(), f.Sub[1].String())
+	case OpOr:
+		return fmt.Sprintf("(%s) OR (%s)", f.Sub[0].String(), f.Sub[1].String())
+	case OpEX:
+		return fmt.Sprintf("EX(%s)", f.Sub[0].String())
+	case OpEG:
+		return fmt.Sprintf("EG(%s)", f.Sub[0].String())
+	case OpEF:
+		return fmt.Sprintf("EF(%s)", f.Sub[0].String())
+	case OpEU:
+		return fmt.Sprintf("E[(%s) U (%s)]", f.Sub[0].String(), f.Sub[1].String())
+	case OpER:
+		return fmt.Sprintf("E[(%s) R (%s)]", f.Sub[0].String(), f.Sub[1].String())
+	case OpAX:
+		return fmt.Sprintf("AX(%s)", f.Sub[0].String())
+	case OpAG:
+		return fmt.Sprintf("AG(%s)", f.Sub[0].String())
+	case OpAF:
+		return fmt.Sprintf("AF(%s)", f.Sub[0].String())
+	case OpAU:
+		return fmt.Sprintf("A[(%s) U (%s)]", f.Sub[0].String(), f.Sub[1].String())
+	case OpAR:
+		return fmt.Sprintf("A[(%s) R (%s)]", f.Sub[0].String(), f.Sub[1].String())
+	}
+	return "?"
+}
+
+// Build constructs the equivalent IFormula using the constructors of the Kripke structure
+func (f *Formula) Build(ks cav.IKripkeStructure, labels map[string]cav.ILabel) cav.IFormula {
+	sub := make([]cav.IFormula, len(f.Sub))
+	for i, s := range f.Sub {
+		sub[i] = s.Build(ks, labels)
+	}
+	switch f.Op {
+	case OpLabel:
+		return labels[f.Label].MakeLabelFormula()
+	case OpTrue:
+		return ks.MakeTrueFormula()
+	case OpFalse:
+		return ks.MakeFalseFormula()
+	case OpNot:
+		return ks.MakeNotFormula(sub[0])
+	case OpAnd:
+		return ks.MakeAndFormula(sub[0], sub[1])
+	case OpOr:
+		return ks.MakeOrFormula(sub[0], sub[1])
+	case OpEX:
+		return ks.MakeEXFormula(sub[0])
+	case OpEG:
+		return ks.MakeEGFormula(sub[0])
+	case OpEF:
+		return ks.MakeEFFormula(sub[0])
+	case OpEU:
+		return ks.MakeEUFormula(sub[0], sub[1])
+	case OpER:
+		return ks.MakeERFormula(sub[0], sub[1])
+	case OpAX:
+		return ks.MakeAXFormula(sub[0])
+	case OpAG:
+		return ks.MakeAGFormula(sub[0])
+	case OpAF:
+		return ks.MakeAFFormula(sub[0])
+	case OpAU:
+		return ks.MakeAUFormula(sub[0], sub[1])
+	case OpAR:
+		return ks.MakeARFormula(sub[0], sub[1])
+	}
+	return nil
+}
+
+func stateName(i int) string {
+	return fmt.Sprintf("s%d", i)
+}
+
+// Build constructs the equivalent Kripke structure, state i being named "s<i>"
+func (m *Model) Build() (cav.IKripkeStructure, []cav.IState, map[string]cav.ILabel) {
+	ks := cav.MakeKripkeStructure()
+	states := make([]cav.IState, len(m.Edges))
+	for i := range m.Edges {
+		states[i] = ks.NewState(stateName(i))
+	}
+	for i, children := range m.Edges {
+		for _, child := range children {
+			states[i].AddChildren(states[child])
+		}
+	}
+	labels := map[string]cav.ILabel{}
+	for name, holds := range m.Labels {
+		labels[name] = ks.NewLabel(name)
+		for i, h := range holds {
+			if h {
+				states[i].AddLabel(labels[name])
+			}
+		}
+	}
+	return ks, states, labels
+}
+
+// String returns the model in the native file format, followed by the given formulas
+func (m *Model) String(formulas ...*Formula) string {
+	var sb strings.Builder
+	sb.WriteString("states\n")
+	for i := range m.Edges {
+		sb.WriteString(stateName(i) + "\n")
+	}
+	sb.WriteString("\ntransitions\n")
+	for i, children := range m.Edges {
+		for _, child := range children {
+			sb.WriteString(stateName(i) + " -> " + stateName(child) + "\n")
+		}
+	}
+	sb.WriteString("\nlabels\n")
+	for _, name := range sortedKeys(m.Labels) {
+		names := make([]string, 0)
+		for i, h := range m.Labels[name] {
+			if h {
+				names = append(names, stateName(i))
+			}
+		}
+		// labels without any state cannot be written down, the parser requires at least one
+		if len(names) > 0 {
+			sb.WriteString(name + ": " + strings.Join(names, ", ") + "\n")
+		}
+	}
+	sb.WriteString("\nformulas\n")
+	for _, f := range formulas {
+		sb.WriteString(f.String() + "\n")
+	}
+	return sb.String()
+}
+
+func (m *Model) isTotal() bool {
+	for _, children := range m.Edges {
+		if len(children) <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Evaluate decides for every state whether it satisfies the formula by enumerating paths explicitly
+func Evaluate(m *Model, f *Formula) ([]bool, error) {
+	if !m.isTotal() {
+		return nil, fmt.Errorf("the oracle only supports models in which every state has a successor")
+	}
+	return evaluate(m, f), nil
+}
+
+func evaluate(m *Model, f *Formula) []bool {
+	n := len(m.Edges)
+	sub := make([][]bool, len(f.Sub))
+	for i, s := range f.Sub {
+		sub[i] = evaluate(m, s)
+	}
+
+	result := make([]bool, n)
+	for s := 0; s < n; s++ {
+		switch f.Op {
+		case OpLabel:
+			if holds, ok := m.Labels[f.Label]; ok {
+				result[s] = holds[s]
+			}
+		case OpTrue:
+			result[s] = true
+		case OpFalse:
+			result[s] = false
+		case OpNot:
+			result[s] = !sub[0][s]
+		case OpAnd:
+			result[s] = sub[0][s] && sub[1][s]
+		case OpOr:
+			result[s] = sub[0][s] || sub[1][s]
+		case OpEX, OpEG, OpEF, OpEU, OpER:
+			result[s] = false
+			forEachLasso(m, s, func(path []int, loop int) {
+				if !result[s] && pathHolds(f.Op, sub, path, loop) {
+					result[s] = true
+				}
+			})
+		case OpAX, OpAG, OpAF, OpAU, OpAR:
+			op := f.Op - OpAX + OpEX
+			result[s] = true
+			forEachLasso(m, s, func(path []int, loop int) {
+				if result[s] && !pathHolds(op, sub, path, loop) {
+					result[s] = false
+				}
+			})
+		}
+	}
+	return result
+}
+
+// forEachLasso calls f for every path s = path[0], ..., path[k] of pairwise distinct states
+// where path[k] has a transition back to path[loop]; together these represent every infinite path up to
+// the point where it starts repeating itself, which suffices for CTL path formulas over state subformulas
+func forEachLasso(m *Model, s int, f func(path []int, loop int)) {
+	path := []int{s}
+	onPath := make([]int, len(m.Edges))
+	for i := range onPath {
+		onPath[i] = -1
+	}
+	onPath[s] = 0
+
+	var dfs func()
+	dfs = func() {
+		last := path[len(path)-1]
+		for _, child := range m.Edges[last] {
+			if onPath[child] >= 0 {
+				f(path, onPath[child])
+				continue
+			}
+			onPath[child] = len(path)
+			path = append(path, child)
+			dfs()
+			path = path[:len(path)-1]
+			onPath[child] = -1
+		}
+	}
+	dfs()
+}
+
+// pathHolds evaluates the path formula of the given existential operator on a lasso
+func pathHolds(op Op, sub [][]bool, path []int, loop int) bool {
+	switch op {
+	case OpEX:
+		if len(path) > 1 {
+			return sub[0][path[1]]
+		}
+		return sub[0][path[loop]]
+	case OpEG:
+		for _, s := range path {
+			if !sub[0][s] {
+				return false
+			}
+		}
+		return true
+	case OpEF:
+		for _, s := range path {
+			if sub[0][s] {
+				return true
+			}
+		}
+		return false
+	case OpEU:
+		for _, s := range path {
+			if sub[1][s] {
+				return true
+			}
+			if !sub[0][s] {
+				return false
+			}
+		}
+		return false
+	case OpER:
+		for _, s := range path {
+			if !sub[1][s] {
+				return false
+			}
+			if sub[0][s] {
+				return true
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Disagreement compares the oracle with Check() and returns the states in which they differ
+func Disagreement(m *Model, f *Formula) ([]int, error) {
+	expected, err := Evaluate(m, f)
+	if err != nil {
+		return nil, err
+	}
+
+	ks, states, labels := m.Build()
+	got := f.Build(ks, labels).Check()
+
+	differing := make([]int, 0)
+	for i, state := range states {
+		if expected[i] != got.Contains(state) {
+			differing = append(differing, i)
+		}
+	}
+	return differing, nil
+}
+
+// Disagrees reports whether the oracle and Check() differ in any state
+func Disagrees(m *Model, f *Formula) bool {
+	differing, err := Disagreement(m, f)
+	return err == nil && len(differing) > 0
+}
+
+// Counterexample describes a model and formula for which Check() and the oracle disagree
+type Counterexample struct {
+	Model    *Model
+	Formula  *Formula
+	Expected []string
+	Got      []string
+}
+
+func (c *Counterexample) String() string {
+	return fmt.Sprintf("%s: expected {%s} but Check() returned {%s}\n%s", c.Formula.String(), strings.Join(c.Expected, ", "), strings.Join(c.Got, ", "), c.Model.String(c.Formula))
+}
+
+// MakeCounterexample minimizes a disagreeing model and formula and evaluates both engines on the result
+func MakeCounterexample(m *Model, f *Formula) *Counterexample {
+	m, f = Minimize(m, f, Disagrees)
+
+	expected, _ := Evaluate(m, f)
+	ks, states, labels := m.Build()
+	got := f.Build(ks, labels).Check()
+
+	c := &Counterexample{m, f, make([]string, 0), make([]string, 0)}
+	for i, state := range states {
+		if expected[i] {
+			c.Expected = append(c.Expected, stateName(i))
+		}
+		if got.Contains(state) {
+			c.Got = append(c.Got, stateName(i))
+		}
+	}
+	return c
+}
@@ -0,0 +1,93 @@
+package oracle
+
+import (
+	"cav/golang/generator"
+	"cav/golang/types"
+	"math/rand"
+	"sort"
+)
+
+// ops maps the operators of generator.CTLSyntax to the operators of the oracle
+var ops = map[string]Op{
+	generator.OpTrue: OpTrue, generator.OpFalse: OpFalse, generator.OpNot: OpNot, generator.OpAnd: OpAnd, generator.OpOr: OpOr,
+	generator.OpEX: OpEX, generator.OpEG: OpEG, generator.OpEF: OpEF, generator.OpEU: OpEU, generator.OpER: OpER,
+	generator.OpAX: OpAX, generator.OpAG: OpAG, generator.OpAF: OpAF, generator.OpAU: OpAU, generator.OpAR: OpAR,
+}
+
+// RandomModel returns a total model with 1 to maxStates states, each having 1 to maxDegree successors
+func RandomModel(r *rand.Rand, maxStates int, maxDegree int, labels []string) *Model {
+	o := generator.DefaultOptions()
+	o.States = 1 + r.Intn(maxStates)
+	o.MinDegree = 1
+	o.MaxDegree = min(maxDegree, o.States)
+	o.Labels = len(labels)
+	o.Names = labels
+	o.Seed = r.Int63()
+	ks, err := generator.RandomKripkeStructure(o)
+	if err != nil {
+		// the options are valid for any positive maxStates and maxDegree
+		panic(err)
+	}
+	return makeModel(ks, labels)
+}
+
+// makeModel converts a generated Kripke structure, whose states are named like the states of a model
+func makeModel(ks cav.IKripkeStructure, labels []string) *Model {
+	index := map[string]int{}
+	for i := 0; i < ks.GetStates().Size(); i++ {
+		index[stateName(i)] = i
+	}
+	m := &Model{
+		Edges:  make([][]int, len(index)),
+		Labels: map[string][]bool{},
+	}
+	for _, label := range labels {
+		m.Labels[label] = make([]bool, len(index))
+	}
+	ks.GetStates().ForEach(func(state cav.IState) {
+		i := index[state.GetName()]
+		state.GetChildren().ForEach(func(child cav.IState) {
+			m.Edges[i] = append(m.Edges[i], index[child.GetName()])
+		})
+		sort.Ints(m.Edges[i])
+		state.GetLabels().ForEach(func(label cav.ILabel) {
+			m.Labels[label.String()][i] = true
+		})
+	})
+	return m
+}
+
+// RandomFormula returns a formula of exactly the given depth over the given labels
+func RandomFormula(r *rand.Rand, depth int, labels []string) *Formula {
+	return makeFormula(generator.Generate(r, generator.FormulaOptions{Depth: depth, Syntax: generator.CTLSyntax, Labels: labels, Constants: true}))
+}
+
+func makeFormula(g *generator.Formula) *Formula {
+	if g.Op == "" {
+		return &Formula{Op: OpLabel, Label: g.Label}
+	}
+	f := &Formula{Op: ops[g.Op]}
+	for _, sub := range g.Sub {
+		f.Sub = append(f.Sub, makeFormula(sub))
+	}
+	return f
+}
+
+func (op Op) Arity() int {
+	switch op {
+	case OpLabel, OpTrue, OpFalse:
+		return 0
+	case OpAnd, OpOr, OpEU, OpER, OpAU, OpAR:
+		return 2
+	}
+	return 1
+}
+
+func sortedKeys(m map[string][]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
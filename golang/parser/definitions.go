@@ -0,0 +1,208 @@
+package parser
+
+import (
+	"cav/golang/ast"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// definition is a named, possibly parameterized formula from the definitions section which is expanded wherever it is used
+type definition struct {
+	name   string
+	params []string
+	body   *ast.Node
+	pos    ast.Position
+}
+
+func isIdentifier(s string) bool {
+	return len(s) > 0 && !strings.ContainsAny(s, " ()[],:=")
+}
+
+func (p *FileParser) errorAt(pos ast.Position, s string, ss ...any) error {
+	return &ParseError{Line: pos.Line, Column: pos.Column, Message: fmt.Sprintf(s, ss...), File: p.includedFile()}
+}
+
+// parseCall parses the use of a parameterized definition, args being the text between its brackets
+func (p *FileParser) parseCall(name string, args string, offset int) (*ast.Node, error) {
+	node := &ast.Node{Kind: ast.Call, Name: name, Operands: make([]*ast.Node, 0)}
+	if strings.Trim(args, " ") == "" {
+		return node, nil
+	}
+	for {
+		i, err := p.findTopLevel(args, func(i int) bool {
+			return args[i] == ','
+		})
+		if err != nil {
+			return nil, err
+		}
+		arg, err := p.parseNode(args[:i], offset)
+		if err != nil {
+			return nil, err
+		}
+		node.Operands = append(node.Operands, arg)
+		if i >= len(args) {
+			return node, nil
+		}
+		args, offset = args[i+1:], offset+i+1
+	}
+}
+
+// parseDefinition parses a line like "safe := NOT (crit1 AND crit2)" or "response(a, b) := AG (a IMPLIES AF b)"
+func (p *FileParser) parseDefinition(line string) error {
+	i := strings.Index(line, ":=")
+	if i < 0 {
+		return p.errorf("invalid definition, expected \":=\" but got: %s", line)
+	}
+
+	head, headOffset := trim(line[:i], 0)
+	name := head
+	params := make([]string, 0)
+	if j := strings.IndexByte(head, '('); j >= 0 {
+		if head[len(head)-1] != ')' {
+			return p.errorf("invalid parameter list in definition: %s", head)
+		}
+		name = strings.TrimRight(head[:j], " ")
+		if list := strings.Trim(head[j+1:len(head)-1], " "); list != "" {
+			for _, param := range strings.Split(list, ",") {
+				param = strings.Trim(param, " ")
+				if !isIdentifier(param) {
+					return p.errorf("invalid parameter in definition %s: %q", name, param)
+				}
+				for _, other := range params {
+					if other == param {
+						return p.errorf("duplicate parameter in definition %s: %s", name, param)
+					}
+				}
+				params = append(params, param)
+			}
+		}
+	}
+
+	if !isIdentifier(name) {
+		return p.errorf("invalid definition name: %q", name)
+	}
+	if _, ok := p.labelsMap[name]; ok {
+		return p.errorf("definition has the same name as a label: %s", name)
+	}
+	if _, ok := p.definitions[name]; ok {
+		return p.errorf("duplicate definition: %s", name)
+	}
+
+	body, err := p.parseNode(line[i+2:], i+2)
+	if err != nil {
+		return err
+	}
+	p.definitions[name] = &definition{name, params, body, p.position(headOffset)}
+	p.symbols.Definitions[name] = p.declaration(headOffset)
+	return nil
+}
+
+// checkDefinitions expands every definition once, so that recursion and undefined names are reported
+// even for definitions which are never used
+func (p *FileParser) checkDefinitions() error {
+	definitions := make([]*definition, 0, len(p.definitions))
+	for _, d := range p.definitions {
+		definitions = append(definitions, d)
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].pos.Line < definitions[j].pos.Line
+	})
+
+	for _, d := range definitions {
+		env := map[string]*ast.Node{}
+		for _, param := range d.params {
+			env[param] = ast.MakeLabel(param)
+		}
+		expanded, err := p.expandNode(d.body, env, []string{d.name})
+		if err != nil {
+			return err
+		}
+
+		var undefined *ast.Node
+		ast.Inspect(expanded, func(n *ast.Node) bool {
+			if n.Kind == ast.Label && undefined == nil {
+				if _, ok := env[n.Name]; !ok {
+					if _, ok := p.labelsMap[n.Name]; !ok {
+						undefined = n
+					}
+				}
+			}
+			return undefined == nil
+		})
+		if undefined != nil {
+			return p.errorAt(undefined.Pos, "undefined name in definition %s: %s", d.name, undefined.Name)
+		}
+	}
+	return nil
+}
+
+// expand replaces all uses of definitions in the node by their bodies
+func (p *FileParser) expand(n *ast.Node) (*ast.Node, error) {
+	return p.expandNode(n, nil, nil)
+}
+
+// expandNode expands n, env mapping the parameters of the definition being expanded to their arguments
+// and stack holding the definitions currently being expanded
+func (p *FileParser) expandNode(n *ast.Node, env map[string]*ast.Node, stack []string) (*ast.Node, error) {
+	switch n.Kind {
+	case ast.Label:
+		if arg, ok := env[n.Name]; ok {
+			return arg.Clone(), nil
+		}
+		if _, ok := p.definitions[n.Name]; ok {
+			return p.instantiate(n, nil, stack)
+		}
+		return n.Clone(), nil
+	case ast.Call:
+		args := make([]*ast.Node, len(n.Operands))
+		for i, operand := range n.Operands {
+			arg, err := p.expandNode(operand, env, stack)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = arg
+		}
+		return p.instantiate(n, args, stack)
+	}
+
+	expanded := *n
+	expanded.Operands = make([]*ast.Node, len(n.Operands))
+	for i, operand := range n.Operands {
+		operand, err := p.expandNode(operand, env, stack)
+		if err != nil {
+			return nil, err
+		}
+		expanded.Operands[i] = operand
+	}
+	return &expanded, nil
+}
+
+// instantiate expands the body of the definition used by the node with the given arguments
+func (p *FileParser) instantiate(use *ast.Node, args []*ast.Node, stack []string) (*ast.Node, error) {
+	d, ok := p.definitions[use.Name]
+	if !ok {
+		return nil, p.errorAt(use.Pos, "undefined name: %s", use.Name)
+	}
+	for i, name := range stack {
+		if name == d.name {
+			cycle := append(append([]string{}, stack[i:]...), d.name)
+			return nil, p.errorAt(use.Pos, "recursive definition: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	if len(args) != len(d.params) {
+		return nil, p.errorAt(use.Pos, "%s expects %d arguments, got %d", d.name, len(d.params), len(args))
+	}
+
+	env := map[string]*ast.Node{}
+	for i, param := range d.params {
+		env[param] = args[i]
+	}
+	expanded, err := p.expandNode(d.body, env, append(append([]string{}, stack...), d.name))
+	if err != nil {
+		return nil, err
+	}
+	// the expansion takes the place of the use
+	expanded.Pos = use.Pos
+	return expanded, nil
+}
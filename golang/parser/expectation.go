@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"cav/golang/ast"
+	"cav/golang/types"
+	"fmt"
+	"strings"
+)
+
+type ExpectationKind int
+
+const (
+	ExpectStates ExpectationKind = iota // EG p == {s2, s7}
+	ExpectHolds                         // AG p : holds
+	ExpectFails                         // EF q : fails
+)
+
+// Expectation is an annotated expected result of a formula in the formulas section
+type Expectation struct {
+	Formula cav.IFormula
+	Node    *ast.Node
+	Line    int
+	File    string // the included file the expectation is in, empty for the parsed file itself
+	Kind    ExpectationKind
+	States  cav.ISet[cav.IState]
+}
+
+// Verify checks the formula and returns a description of the difference to the expected result, if any
+func (e *Expectation) Verify() (bool, string) {
+	result := e.Formula.Check()
+	ks := e.Formula.GetKripkeStructure()
+
+	switch e.Kind {
+	case ExpectStates:
+		missing := e.States.Minus(result)
+		unexpected := result.Minus(e.States)
+		if missing.Size() <= 0 && unexpected.Size() <= 0 {
+			return true, ""
+		}
+		return false, fmt.Sprintf("expected %s but got %s\n  - missing:    %s\n  + unexpected: %s", e.States.String(), result.String(), missing.String(), unexpected.String())
+	case ExpectHolds:
+		violating := ks.GetInitialStates().Minus(result)
+		if violating.Size() <= 0 {
+			return true, ""
+		}
+		return false, fmt.Sprintf("expected to hold, but fails in %s", violating.String())
+	case ExpectFails:
+		if !cav.Holds(e.Formula) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("expected to fail, but holds in all of %s", ks.GetInitialStates().String())
+	}
+	return false, fmt.Sprintf("unknown expectation kind: %d", e.Kind)
+}
+
+// parseAnnotatedFormula parses a formula which may be followed by an expected result
+func (p *FileParser) parseAnnotatedFormula(s string) (*ast.Node, cav.IFormula, error) {
+	if i := strings.Index(s, "=="); i >= 0 {
+		node, formula, err := p.parseFormula(s[:i])
+		if err != nil {
+			return nil, nil, err
+		}
+		set := strings.Trim(s[i+2:], " ")
+		if !strings.HasPrefix(set, "{") || !strings.HasSuffix(set, "}") {
+			return nil, nil, p.errorf("invalid expected result, expected a set of states like {s1, s2}, but got: %s", set)
+		}
+		states := cav.MakeSet[cav.IState]()
+		for _, stateName := range strings.Split(set[1:len(set)-1], ",") {
+			stateName = strings.Trim(stateName, " ")
+			if len(stateName) <= 0 {
+				continue
+			}
+			state, ok := p.statesMap[stateName]
+			if !ok {
+				return nil, nil, p.errorf("unknown state in expected result: %s", stateName)
+			}
+			states.Add(state)
+		}
+		p.expected = append(p.expected, Expectation{formula, node, p.lineNr, p.includedFile(), ExpectStates, states})
+		return node, formula, nil
+	}
+
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		node, formula, err := p.parseFormula(s[:i])
+		if err != nil {
+			return nil, nil, err
+		}
+		verdict := strings.Trim(s[i+1:], " ")
+		switch verdict {
+		case "holds":
+			p.expected = append(p.expected, Expectation{formula, node, p.lineNr, p.includedFile(), ExpectHolds, nil})
+		case "fails":
+			p.expected = append(p.expected, Expectation{formula, node, p.lineNr, p.includedFile(), ExpectFails, nil})
+		default:
+			return nil, nil, p.errorf("invalid expected verdict, expected \"holds\" or \"fails\", but got: %s", verdict)
+		}
+		return node, formula, nil
+	}
+
+	return p.parseFormula(s)
+}
@@ -2,255 +2,685 @@ package parser
 
 import (
 	"bufio"
+	"cav/golang/ast"
+	"cav/golang/atl"
+	"cav/golang/ctlstar"
+	"cav/golang/ltl"
+	"cav/golang/mucalculus"
+	"cav/golang/pctl"
 	"cav/golang/types"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
 type IFileParser interface {
 	ParseFile(path string) (cav.IKripkeStructure, []cav.IFormula, error)
+	Parse(r io.Reader) (cav.IKripkeStructure, []cav.IFormula, error)
+	ParseString(s string) (cav.IKripkeStructure, []cav.IFormula, error)
+	ParseFormula(ks cav.IKripkeStructure, s string) (cav.IFormula, error)
+}
+
+// ParseError is returned for malformed input and carries the line it occurred in, and the column if known.
+// File is only set if the error occurred in an included file.
+type ParseError struct {
+	Line    int
+	Column  int
+	Message string
+	File    string
+}
+
+func (e *ParseError) Error() string {
+	position := fmt.Sprintf("%d", e.Line)
+	if e.Column > 0 {
+		position = fmt.Sprintf("%d:%d", e.Line, e.Column)
+	}
+	if e.File != "" {
+		position = e.File + ":" + position
+	}
+	return fmt.Sprintf("%s: %s", position, e.Message)
+}
+
+// Declaration is the position of a name or formula in the input, File is only set if it is in an included file.
+type Declaration struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// Symbols records where states, labels, definitions and formulas have been declared
+type Symbols struct {
+	States      map[string]Declaration
+	Labels      map[string]Declaration
+	Definitions map[string]Declaration
+	Formulas    []Declaration
+}
+
+// source is a file being read by the parser, the innermost one being the current source
+type source struct {
+	scanner *bufio.Scanner
+	file    io.Closer // nil for readers passed to Parse
+	path    string    // absolute path, empty for readers passed to Parse
+	dir     string    // directory includes are resolved against
+	lineNr  int
 }
 
 type FileParser struct {
-	scanner   *bufio.Scanner
-	line      string
-	lineNr    int
-	ks        cav.IKripkeStructure
-	formulas  []cav.IFormula
-	statesMap map[string]cav.IState
-	labelsMap map[string]cav.ILabel
+	scanner     *bufio.Scanner
+	sources     []*source
+	line        string
+	lineNr      int
+	column      int
+	ks          cav.IKripkeStructure
+	formulas    []cav.IFormula
+	statesMap   map[string]cav.IState
+	labelsMap   map[string]cav.ILabel
+	rewards     map[string]bool // states and transitions which have been given a reward
+	symbols     Symbols
+	expected    []Expectation
+	nodes       []*ast.Node
+	definitions map[string]*definition
 }
 
 func (p *FileParser) nextLine() error {
-	for p.scanner.Scan() {
-		p.lineNr++
-		line := p.scanner.Text()
-		line = strings.SplitN(line, "//", 2)[0]
-		line = strings.Replace(line, "\t", " ", -1)
-		line = strings.Trim(line, " ")
-		for strings.Contains(line, "  ") {
-			line = strings.Replace(line, "  ", " ", -1)
-		}
-		if len(line) > 0 {
-			p.line = line
-			return nil
+	for {
+		for p.scanner.Scan() {
+			p.lineNr++
+			line := p.scanner.Text()
+			line = strings.SplitN(line, "//", 2)[0]
+			line = strings.Replace(line, "\t", " ", -1)
+			// spaces are kept inside the line so that columns of formulas stay accurate
+			trimmed := strings.TrimLeft(line, " ")
+			p.column = len(line) - len(trimmed) + 1
+			line = strings.TrimRight(trimmed, " ")
+			if strings.HasPrefix(line, "include ") {
+				if err := p.include(strings.TrimLeft(line[len("include "):], " ")); err != nil {
+					return err
+				}
+				continue
+			}
+			if len(line) > 0 {
+				p.line = line
+				return nil
+			}
 		}
+		if err := p.scanner.Err(); err != nil {
+			return p.errorf("failed to read line %d: %s", p.lineNr+1, err.Error())
+		}
+		if len(p.sources) <= 1 {
+			return io.EOF
+		}
+		// continue behind the include directive
+		p.popSource()
+	}
+}
+
+// include continues reading from the quoted path, which is relative to the directory of the current file
+func (p *FileParser) include(quoted string) error {
+	path, err := strconv.Unquote(quoted)
+	if err != nil || path == "" {
+		return p.errorf("invalid include, expected a quoted path but got: %s", quoted)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.current().dir, path)
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return p.errorf("invalid include path: %s", err.Error())
+	}
+
+	for i, s := range p.sources {
+		if s.path == path {
+			cycle := make([]string, 0)
+			for _, s := range append(p.sources[i:], &source{path: path}) {
+				cycle = append(cycle, p.relative(s.path))
+			}
+			return p.errorf("include cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return p.errorf("failed to include %s: %s", quoted, err.Error())
+	}
+	p.pushSource(&source{bufio.NewScanner(file), file, path, filepath.Dir(path), 0})
+	return nil
+}
+
+// relative returns the path relative to the directory of the parsed file, for shorter messages
+func (p *FileParser) relative(path string) string {
+	if rel, err := filepath.Rel(p.sources[0].dir, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+func (p *FileParser) current() *source {
+	return p.sources[len(p.sources)-1]
+}
+
+func (p *FileParser) pushSource(s *source) {
+	if len(p.sources) > 0 {
+		p.current().lineNr = p.lineNr
+	}
+	p.sources = append(p.sources, s)
+	p.scanner = s.scanner
+	p.lineNr = s.lineNr
+}
+
+func (p *FileParser) popSource() {
+	if s := p.current(); s.file != nil {
+		s.file.Close()
 	}
-	return io.EOF
+	p.sources = p.sources[:len(p.sources)-1]
+	p.scanner = p.current().scanner
+	p.lineNr = p.current().lineNr
+}
+
+// includedFile returns the path of the current source if it has been included, the empty string otherwise
+func (p *FileParser) includedFile() string {
+	if len(p.sources) <= 1 {
+		return ""
+	}
+	return p.current().path
+}
+
+// declaration returns the position in the current line, offset being relative to its first character
+func (p *FileParser) declaration(offset int) Declaration {
+	return Declaration{p.includedFile(), p.lineNr, p.column + offset}
 }
 
 func (p *FileParser) errorf(s string, ss ...any) error {
 	if len(ss) <= 0 {
-		return fmt.Errorf("%d: %s", p.lineNr, s)
+		return &ParseError{Line: p.lineNr, Message: s, File: p.includedFile()}
 	}
-	return fmt.Errorf("%d: %s", p.lineNr, fmt.Sprintf(s, ss...))
+	return &ParseError{Line: p.lineNr, Message: fmt.Sprintf(s, ss...), File: p.includedFile()}
 }
 
-func (p *FileParser) parseFormula(s string) (cav.IFormula, error) {
-	s = strings.Trim(s, " ")
-
-	var i int
-	runes := []rune(s)
+// findTopLevel returns the byte index of the first position outside of any brackets at which match returns true,
+// or len(s) if there is no such position
+func (p *FileParser) findTopLevel(s string, match func(i int) bool) (int, error) {
 	pCounter := 0 // counts '('
 	bCounter := 0 // counts '['
 
-	for i = 0; i < len(runes); i++ {
-		if runes[i] == '(' {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '(' {
 			pCounter++
-		} else if runes[i] == ')' {
+		} else if s[i] == ')' {
 			pCounter--
-		} else if runes[i] == '[' {
+		} else if s[i] == '[' {
 			bCounter++
-		} else if runes[i] == ']' {
+		} else if s[i] == ']' {
 			bCounter--
 		}
-		if pCounter != 0 || bCounter != 0 {
-			continue
-		} else if i < len(runes)-3 && s[i:i+3] == "AND" {
-			break
-		} else if i < len(runes)-2 && s[i:i+2] == "OR" {
-			break
+		if pCounter < 0 || bCounter < 0 {
+			return 0, p.errorf("unbalanced brackets in formula: %s", s)
+		}
+		if pCounter == 0 && bCounter == 0 && match(i) {
+			return i, nil
 		}
 	}
 
-	if i < len(runes)-3 && s[i:i+3] == "AND" {
-		left := strings.Trim(s[:i], " ")
-		leftFormula, errLeft := p.parseFormula(left)
-		if errLeft != nil {
-			return nil, errLeft
-		}
-		right := strings.Trim(s[i+3:], " ")
-		rightFormula, errRight := p.parseFormula(right)
-		if errRight != nil {
-			return nil, errRight
+	if pCounter != 0 || bCounter != 0 {
+		return 0, p.errorf("unbalanced brackets in formula: %s", s)
+	}
+	return len(s), nil
+}
+
+func isBracketed(s string) bool {
+	return len(s) >= 2 && ((s[0] == '(' && s[len(s)-1] == ')') || (s[0] == '[' && s[len(s)-1] == ']'))
+}
+
+// trim removes surrounding spaces and moves the offset of s within the line accordingly
+func trim(s string, offset int) (string, int) {
+	trimmed := strings.TrimLeft(s, " ")
+	return strings.TrimRight(trimmed, " "), offset + len(s) - len(trimmed)
+}
+
+func (p *FileParser) position(offset int) ast.Position {
+	return ast.Position{Line: p.lineNr, Column: p.column + offset}
+}
+
+func (p *FileParser) parseBinary(kind ast.Kind, left string, leftOffset int, right string, rightOffset int) (*ast.Node, error) {
+	leftNode, errLeft := p.parseNode(left, leftOffset)
+	if errLeft != nil {
+		return nil, errLeft
+	}
+	rightNode, errRight := p.parseNode(right, rightOffset)
+	if errRight != nil {
+		return nil, errRight
+	}
+	return ast.MakeNode(kind, leftNode, rightNode), nil
+}
+
+func (p *FileParser) parseUnary(kind ast.Kind, s string, offset int) (*ast.Node, error) {
+	node, err := p.parseNode(s, offset)
+	if err != nil {
+		return nil, err
+	}
+	return ast.MakeNode(kind, node), nil
+}
+
+// pathKinds are the node kinds of the temporal operators following one path quantifier
+type pathKinds struct {
+	x, g, f, u, r, y, h, s       ast.Kind
+	boundedG, boundedF, boundedU ast.Kind
+	costG, costF                 ast.Kind
+}
+
+var existentialKinds = pathKinds{ast.EX, ast.EG, ast.EF, ast.EU, ast.ER, ast.EY, ast.EH, ast.ES, ast.BoundedEG, ast.BoundedEF, ast.BoundedEU, ast.CostEG, ast.CostEF}
+var universalKinds = pathKinds{ast.AX, ast.AG, ast.AF, ast.AU, ast.AR, ast.AY, ast.AH, ast.AS, ast.BoundedAG, ast.BoundedAF, ast.BoundedAU, ast.CostAG, ast.CostAF}
+
+// isSince reports whether s has the since operator S as a word of its own at i between two operands,
+// so that a label named S can be the left operand
+func isSince(s string, i int) bool {
+	return s[i] == 'S' && i > 0 && strings.ContainsAny(s[i-1:i], " )]") && strings.TrimSpace(s[:i]) != "" &&
+		i+1 < len(s) && strings.ContainsAny(s[i+1:i+2], " ([") && strings.TrimSpace(s[i+1:]) != ""
+}
+
+var boundPattern = regexp.MustCompile(`^\s*(?:<=\s*(\d+)|\[\s*(\d+)\s*,\s*(\d+)\s*\])`)
+
+// parseBound parses a bound like "<=5" or "[2,5]" at the start of s, returning its length or 0 if there is none
+func (p *FileParser) parseBound(s string) (cav.Bound, int, error) {
+	match := boundPattern.FindStringSubmatch(s)
+	if match == nil {
+		return cav.Bound{}, 0, nil
+	}
+	var bound cav.Bound
+	var err error
+	if match[1] != "" {
+		bound.Upper, err = strconv.Atoi(match[1])
+	} else if bound.Lower, err = strconv.Atoi(match[2]); err == nil {
+		bound.Upper, err = strconv.Atoi(match[3])
+	}
+	if err != nil || bound.Lower > bound.Upper {
+		return cav.Bound{}, 0, p.errorf("invalid bound: %s", strings.TrimSpace(match[0]))
+	}
+	return bound, len(match[0]), nil
+}
+
+// weightedArrowPattern matches transitions with a weight like "-3->" or "<-3-"
+var weightedArrowPattern = regexp.MustCompile(`^(?:-(\d+)->|<-(\d+)-)$`)
+
+// probabilityArrowPattern matches transitions of a Markov chain like "-(0.5)->" or "<-(1/3)-",
+// and of an action of a Markov decision process like "-a(0.5)->"
+var probabilityArrowPattern = regexp.MustCompile(`^(?:-([A-Za-z_]\w*)?\(([^()]+)\)->|<-([A-Za-z_]\w*)?\(([^()]+)\)-)$`)
+
+// moveArrowPattern matches transitions of a concurrent game structure like "-<req,wait>->" or "<-<req,wait>-",
+// giving the action of each agent in the order of the agents section
+var moveArrowPattern = regexp.MustCompile(`^(?:-<([^<>]*)>->|<-<([^<>]*)>-)$`)
+
+// actionPattern matches the name of an agent or of an action of an agent
+var actionPattern = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+
+// parseProbability parses a decimal number or a fraction like "1/3" in the interval (0, 1]
+func parseProbability(s string) (float64, bool) {
+	var probability float64
+	if numerator, denominator, ok := strings.Cut(s, "/"); ok {
+		n, err1 := strconv.ParseFloat(numerator, 64)
+		d, err2 := strconv.ParseFloat(denominator, 64)
+		if err1 != nil || err2 != nil || d == 0 {
+			return 0, false
 		}
-		return p.ks.MakeAndFormula(leftFormula, rightFormula), nil
-	} else if i < len(runes)-2 && s[i:i+2] == "OR" {
-		left := strings.Trim(s[:i], " ")
-		leftFormula, errLeft := p.parseFormula(left)
-		if errLeft != nil {
-			return nil, errLeft
+		probability = n / d
+	} else {
+		var err error
+		if probability, err = strconv.ParseFloat(s, 64); err != nil {
+			return 0, false
 		}
-		right := strings.Trim(s[i+2:], " ")
-		rightFormula, errRight := p.parseFormula(right)
-		if errRight != nil {
-			return nil, errRight
+	}
+	return probability, probability > 0 && probability <= 1
+}
+
+var costPattern = regexp.MustCompile(`^\s*\[\s*cost\s*<=\s*(\d+)\s*\]`)
+
+// parseBounded parses the operand of F or G, which is bounded if s starts with a bound or a cost like "[cost<=10]"
+func (p *FileParser) parseBounded(kind ast.Kind, boundedKind ast.Kind, costKind ast.Kind, s string, offset int) (*ast.Node, error) {
+	var bound cav.Bound
+	var n int
+	var err error
+	if match := costPattern.FindStringSubmatch(s); match != nil {
+		if bound.Upper, err = strconv.Atoi(match[1]); err != nil {
+			return nil, p.errorf("invalid cost: %s", strings.TrimSpace(match[0]))
 		}
-		return p.ks.MakeOrFormula(leftFormula, rightFormula), nil
+		boundedKind, n = costKind, len(match[0])
+	} else if bound, n, err = p.parseBound(s); err != nil {
+		return nil, err
 	}
+	if n <= 0 {
+		return p.parseUnary(kind, s, offset)
+	}
+	node, err := p.parseUnary(boundedKind, s[n:], offset+n)
+	if node != nil {
+		node.Bound = bound
+	}
+	return node, err
+}
 
-	if s == "true" {
-		return p.ks.MakeTrueFormula(), nil
-	} else if s == "false" {
-		return p.ks.MakeFalseFormula(), nil
-	} else if strings.HasPrefix(s, "NOT") {
-		formula, err := p.parseFormula(s[3:])
-		if err == nil {
-			return p.ks.MakeNotFormula(formula), nil
-		} else {
+// parseTemporal parses the part of a temporal formula following its path quantifier, returning nil if it is none
+func (p *FileParser) parseTemporal(s string, offset int, kinds pathKinds) (*ast.Node, error) {
+	s, offset = trim(s, offset)
+	if strings.HasPrefix(s, "X") {
+		return p.parseUnary(kinds.x, s[1:], offset+1)
+	} else if strings.HasPrefix(s, "G") {
+		return p.parseBounded(kinds.g, kinds.boundedG, kinds.costG, s[1:], offset+1)
+	} else if strings.HasPrefix(s, "F") {
+		return p.parseBounded(kinds.f, kinds.boundedF, kinds.costF, s[1:], offset+1)
+	} else if strings.HasPrefix(s, "Y") {
+		return p.parseUnary(kinds.y, s[1:], offset+1)
+	} else if strings.HasPrefix(s, "H") {
+		return p.parseUnary(kinds.h, s[1:], offset+1)
+	} else if isBracketed(s) {
+		s, offset = trim(s[1:len(s)-1], offset+1)
+		i, err := p.findTopLevel(s, func(i int) bool {
+			return s[i] == 'U' || s[i] == 'R' || isSince(s, i)
+		})
+		if err != nil {
 			return nil, err
 		}
-	} else if strings.HasPrefix(s, "E") {
-		s = strings.Trim(s[1:], " ")
-		if strings.HasPrefix(s, "X") {
-			s = strings.Trim(s[1:], " ")
-			formula, err := p.parseFormula(s)
-			if err == nil {
-				return p.ks.MakeEXFormula(formula), nil
-			} else {
-				return nil, err
-			}
-		} else if strings.HasPrefix(s, "G") {
-			s = strings.Trim(s[1:], " ")
-			formula, err := p.parseFormula(s)
-			if err == nil {
-				return p.ks.MakeEGFormula(formula), nil
-			} else {
+		if i >= len(s) {
+			return nil, p.errorf("expected \"U\", \"R\" or \"S\" in formula: %s", s)
+		}
+		switch s[i] {
+		case 'U':
+			bound, n, err := p.parseBound(s[i+1:])
+			if err != nil {
 				return nil, err
 			}
-		} else if strings.HasPrefix(s, "F") {
-			s = strings.Trim(s[1:], " ")
-			formula, err := p.parseFormula(s)
-			if err == nil {
-				return p.ks.MakeEFFormula(formula), nil
-			} else {
-				return nil, err
+			if n <= 0 {
+				return p.parseBinary(kinds.u, s[:i], offset, s[i+1:], offset+i+1)
 			}
-		} else if (strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")) || (strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]")) {
-			s = strings.Trim(s[1:len(s)-1], " ")
-			runes = []rune(s)
-			pCounter = 0
-			bCounter = 0
-			for i = 0; i < len(runes); i++ {
-				if runes[i] == '(' {
-					pCounter++
-				} else if runes[i] == ')' {
-					pCounter--
-				} else if runes[i] == '[' {
-					bCounter++
-				} else if runes[i] == ']' {
-					bCounter--
-				}
-				if pCounter != 0 || bCounter != 0 {
-					continue
-				} else if runes[i] == 'U' || runes[i] == 'R' {
-					break
-				}
+			node, err := p.parseBinary(kinds.boundedU, s[:i], offset, s[i+1+n:], offset+i+1+n)
+			if node != nil {
+				node.Bound = bound
 			}
+			return node, err
+		case 'R':
+			return p.parseBinary(kinds.r, s[:i], offset, s[i+1:], offset+i+1)
+		}
+		return p.parseBinary(kinds.s, s[:i], offset, s[i+1:], offset+i+1)
+	}
+	return nil, nil
+}
 
-			left := strings.Trim(s[:i], " ")
-			leftFormula, errLeft := p.parseFormula(left)
-			if errLeft != nil {
-				return nil, errLeft
-			}
-			right := strings.Trim(s[i+1:], " ")
-			rightFormula, errRight := p.parseFormula(right)
-			if errRight != nil {
-				return nil, errRight
-			}
-			if runes[i] == 'U' {
-				return p.ks.MakeEUFormula(leftFormula, rightFormula), nil
-			} else if runes[i] == 'R' {
-				return p.ks.MakeERFormula(leftFormula, rightFormula), nil
-			}
+// parseNode parses the formula s which starts at the given byte offset of the current line
+func (p *FileParser) parseNode(s string, offset int) (*ast.Node, error) {
+	s, offset = trim(s, offset)
+	node, err := p.parseNodeKind(s, offset)
+	if node != nil {
+		node.Pos = p.position(offset)
+	}
+	return node, err
+}
+
+func (p *FileParser) parseNodeKind(s string, offset int) (*ast.Node, error) {
+	// IMPLIES binds weaker than AND and OR and associates to the right
+	i, err := p.findTopLevel(s, func(i int) bool {
+		return strings.HasPrefix(s[i:], "IMPLIES")
+	})
+	if err != nil {
+		return nil, err
+	}
+	if i < len(s) {
+		return p.parseBinary(ast.Implies, s[:i], offset, s[i+7:], offset+i+7)
+	}
+
+	i, err = p.findTopLevel(s, func(i int) bool {
+		return strings.HasPrefix(s[i:], "AND") || strings.HasPrefix(s[i:], "OR")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(s[i:], "AND") {
+		return p.parseBinary(ast.And, s[:i], offset, s[i+3:], offset+i+3)
+	} else if strings.HasPrefix(s[i:], "OR") {
+		return p.parseBinary(ast.Or, s[:i], offset, s[i+2:], offset+i+2)
+	}
+
+	if s == "true" {
+		return ast.MakeNode(ast.True), nil
+	} else if s == "false" {
+		return ast.MakeNode(ast.False), nil
+	} else if strings.HasPrefix(s, "NOT") {
+		return p.parseUnary(ast.Not, s[3:], offset+3)
+	} else if strings.HasPrefix(s, "E") {
+		node, err := p.parseTemporal(s[1:], offset+1, existentialKinds)
+		if node != nil || err != nil {
+			return node, err
 		}
 	} else if strings.HasPrefix(s, "A") {
-		s = strings.Trim(s[1:], " ")
-		if strings.HasPrefix(s, "X") {
-			s = strings.Trim(s[1:], " ")
-			formula, err := p.parseFormula(s)
-			if err == nil {
-				return p.ks.MakeAXFormula(formula), nil
-			} else {
-				return nil, err
-			}
-		} else if strings.HasPrefix(s, "G") {
-			s = strings.Trim(s[1:], " ")
-			formula, err := p.parseFormula(s)
-			if err == nil {
-				return p.ks.MakeAGFormula(formula), nil
-			} else {
-				return nil, err
-			}
-		} else if strings.HasPrefix(s, "F") {
-			s = strings.Trim(s[1:], " ")
-			formula, err := p.parseFormula(s)
-			if err == nil {
-				return p.ks.MakeAFFormula(formula), nil
-			} else {
-				return nil, err
-			}
-		} else if (strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")) || (strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]")) {
-			s = strings.Trim(s[1:len(s)-1], " ")
-			runes = []rune(s)
-			pCounter = 0
-			bCounter = 0
-			for i = 0; i < len(runes); i++ {
-				if runes[i] == '(' {
-					pCounter++
-				} else if runes[i] == ')' {
-					pCounter--
-				} else if runes[i] == '[' {
-					bCounter++
-				} else if runes[i] == ']' {
-					bCounter--
-				}
-				if pCounter != 0 || bCounter != 0 {
-					continue
-				} else if runes[i] == 'U' || runes[i] == 'R' {
-					break
-				}
-			}
+		node, err := p.parseTemporal(s[1:], offset+1, universalKinds)
+		if node != nil || err != nil {
+			return node, err
+		}
+	} else if isBracketed(s) {
+		node, err := p.parseNode(s[1:len(s)-1], offset+1)
+		if node != nil {
+			// the brackets belong to the node
+			node.Pos = p.position(offset)
+		}
+		return node, err
+	}
 
-			left := strings.Trim(s[:i], " ")
-			leftFormula, errLeft := p.parseFormula(left)
-			if errLeft != nil {
-				return nil, errLeft
-			}
-			right := strings.Trim(s[i+1:], " ")
-			rightFormula, errRight := p.parseFormula(right)
-			if errRight != nil {
-				return nil, errRight
-			}
-			if runes[i] == 'U' {
-				return p.ks.MakeAUFormula(leftFormula, rightFormula), nil
-			} else if runes[i] == 'R' {
-				return p.ks.MakeARFormula(leftFormula, rightFormula), nil
-			}
+	if len(s) <= 0 {
+		return nil, p.errorf("missing formula")
+	}
+	if i := strings.IndexByte(s, '('); i > 0 && s[len(s)-1] == ')' && isIdentifier(s[:i]) {
+		return p.parseCall(s[:i], s[i+1:len(s)-1], offset+i+1)
+	}
+	if strings.ContainsAny(s, " ()[]") {
+		return nil, p.errorf("invalid formula: %s", s)
+	}
+	return ast.MakeLabel(s), nil
+}
+
+// bind turns a parsed node into a formula over the Kripke structure being parsed
+func (p *FileParser) bind(node *ast.Node) (cav.IFormula, error) {
+	formula, err := ast.Bind(node, p.ks)
+	if err != nil {
+		var bindErr *ast.BindError
+		if errors.As(err, &bindErr) {
+			return nil, &ParseError{Line: bindErr.Pos.Line, Column: bindErr.Pos.Column, Message: err.Error(), File: p.includedFile()}
 		}
-	} else if (strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")) || (strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]")) {
-		s = strings.Trim(s[1:len(s)-1], " ")
-		return p.parseFormula(s)
+		return nil, p.errorf(err.Error())
 	}
+	return formula, nil
+}
 
-	s = strings.Split(s, " ")[0]
-	label, ok := p.labelsMap[s]
+// parseFormula parses a CTL formula, or an LTL, CTL*, mu-calculus, PCTL or ATL formula if prefixed by "LTL ", "CTL* ",
+// "MU ", "PCTL " or "ATL ", for which no node is returned.
+// Formulas which are no CTL formulas but CTL* formulas nesting path formulas under a path quantifier are parsed as
+// CTL* formulas as well,
+// and so are those with probabilistic operators or strategy quantifiers as PCTL and ATL formulas.
+func (p *FileParser) parseFormula(s string) (*ast.Node, cav.IFormula, error) {
+	if strings.HasPrefix(s, "LTL ") {
+		formula, err := p.parseLTL(s[4:], 4)
+		return nil, formula, err
+	}
+	if strings.HasPrefix(s, "CTL* ") {
+		formula, err := p.parseCTLStar(s[5:], 5)
+		return nil, formula, err
+	}
+	if strings.HasPrefix(s, "MU ") {
+		formula, err := p.parseMu(s[3:], 3)
+		return nil, formula, err
+	}
+	if strings.HasPrefix(s, "PCTL ") {
+		formula, err := p.parsePCTL(s[5:], 5)
+		return nil, formula, err
+	}
+	if strings.HasPrefix(s, "ATL ") {
+		formula, err := p.parseATL(s[4:], 4)
+		return nil, formula, err
+	}
+
+	node, err := p.parseNode(s, 0)
+	if err != nil {
+		// CTL formulas the CTL parser rejects keep its error, as CTL* differs from CTL in states without successors
+		if f, ltlErr := ltl.ParseCTLStar(s); ltlErr == nil && f.HasPathQuantifier() && !f.IsCTL() {
+			formula, err := p.parseCTLStar(s, 0)
+			return nil, formula, err
+		}
+		if pctlPattern.MatchString(s) {
+			formula, err := p.parsePCTL(s, 0)
+			return nil, formula, err
+		}
+		if strings.Contains(s, "<<") {
+			formula, err := p.parseATL(s, 0)
+			return nil, formula, err
+		}
+		return nil, nil, err
+	}
+	node, err = p.expand(node)
+	if err != nil {
+		return nil, nil, err
+	}
+	formula, err := p.bind(node)
+	if err != nil {
+		return nil, nil, err
+	}
+	return node, formula, nil
+}
+
+// parsePath parses the syntax shared by LTL and CTL* formulas with the given parser, offset being the column of s
+// in the line
+func (p *FileParser) parsePath(parse func(string) (*ltl.Formula, error), s string, offset int) (*ltl.Formula, error) {
+	f, err := parse(s)
+	if err != nil {
+		var syntaxErr *ltl.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, &ParseError{Line: p.lineNr, Column: p.column + offset + syntaxErr.Column - 1, Message: syntaxErr.Message, File: p.includedFile()}
+		}
+		return nil, p.errorf(err.Error())
+	}
+	return f, nil
+}
+
+func (p *FileParser) parseLTL(s string, offset int) (cav.IFormula, error) {
+	f, err := p.parsePath(ltl.Parse, s, offset)
+	if err != nil {
+		return nil, err
+	}
+	formula, err := ltl.MakeLTLFormula(p.ks, f)
+	if err != nil {
+		return nil, p.errorf(err.Error())
+	}
+	return formula, nil
+}
+
+func (p *FileParser) parseCTLStar(s string, offset int) (cav.IFormula, error) {
+	f, err := p.parsePath(ltl.ParseCTLStar, s, offset)
+	if err != nil {
+		return nil, err
+	}
+	formula, err := ctlstar.MakeFormula(p.ks, f)
+	if err != nil {
+		return nil, p.errorf(err.Error())
+	}
+	return formula, nil
+}
+
+func (p *FileParser) parseMu(s string, offset int) (cav.IFormula, error) {
+	f, err := mucalculus.Parse(s)
+	if err != nil {
+		var syntaxErr *mucalculus.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, &ParseError{Line: p.lineNr, Column: p.column + offset + syntaxErr.Column - 1, Message: syntaxErr.Message, File: p.includedFile()}
+		}
+		return nil, p.errorf(err.Error())
+	}
+	formula, err := mucalculus.MakeMuFormula(p.ks, f)
+	if err != nil {
+		return nil, p.errorf(err.Error())
+	}
+	return formula, nil
+}
+
+// pctlPattern matches the probabilistic and the reward operator, which CTL formulas cannot contain
+var pctlPattern = regexp.MustCompile(`\b(?:P|R|Pmax|Pmin)\s*(?:<|>|=\?)`)
+
+func (p *FileParser) parsePCTL(s string, offset int) (cav.IFormula, error) {
+	f, err := pctl.Parse(s)
+	if err != nil {
+		var syntaxErr *pctl.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, &ParseError{Line: p.lineNr, Column: p.column + offset + syntaxErr.Column - 1, Message: syntaxErr.Message, File: p.includedFile()}
+		}
+		return nil, p.errorf(err.Error())
+	}
+	formula, err := pctl.MakePCTLFormula(p.ks, f)
+	if err != nil {
+		return nil, p.errorf(err.Error())
+	}
+	return formula, nil
+}
+
+func (p *FileParser) parseATL(s string, offset int) (cav.IFormula, error) {
+	f, err := atl.Parse(s)
+	if err != nil {
+		var syntaxErr *atl.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, &ParseError{Line: p.lineNr, Column: p.column + offset + syntaxErr.Column - 1, Message: syntaxErr.Message, File: p.includedFile()}
+		}
+		return nil, p.errorf(err.Error())
+	}
+	formula, err := atl.MakeATLFormula(p.ks, f)
+	if err != nil {
+		return nil, p.errorf(err.Error())
+	}
+	return formula, nil
+}
+
+// parseReward parses the reward of a state like "s1: 2" or of a transition like "s1 -> s2: 0.5"
+func (p *FileParser) parseReward(line string) error {
+	target, value, ok := strings.Cut(line, ":")
 	if !ok {
-		return nil, p.errorf("unknown label in formula: %s", s)
+		return p.errorf("invalid reward, expected \"<state>: <reward>\" or \"<state> -> <state>: <reward>\", but got: %s", line)
+	}
+	reward, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || reward < 0 || math.IsInf(reward, 0) || math.IsNaN(reward) {
+		return p.errorf("invalid reward: %s", strings.TrimSpace(value))
+	}
+
+	names := strings.Fields(target)
+	states := make([]cav.IState, 0, 2)
+	for i, name := range names {
+		if i == 1 {
+			if name != "->" {
+				return p.errorf("invalid reward, expected \"->\", but got: %s", name)
+			}
+			continue
+		}
+		state, ok := p.statesMap[name]
+		if !ok {
+			return p.errorf("unknown state for reward: %s", name)
+		}
+		states = append(states, state)
+	}
+
+	key := strings.Join(names, " ")
+	if p.rewards[key] {
+		return p.errorf("duplicate reward: %s", key)
+	}
+	p.rewards[key] = true
+	switch len(names) {
+	case 1:
+		states[0].SetReward(reward)
+	case 3:
+		if !states[0].HasChild(states[1]) {
+			return p.errorf("reward for a missing transition: %s", key)
+		}
+		states[0].SetTransitionReward(states[1], reward)
+	default:
+		return p.errorf("invalid reward, expected \"<state>: <reward>\" or \"<state> -> <state>: <reward>\", but got: %s", line)
 	}
-	return label.MakeLabelFormula(), nil
+	return nil
 }
 
 func (p *FileParser) parseEverything() error {
@@ -264,6 +694,7 @@ func (p *FileParser) parseEverything() error {
 	p.ks = cav.MakeKripkeStructure()
 	p.statesMap = map[string]cav.IState{}
 	p.labelsMap = map[string]cav.ILabel{}
+	p.rewards = map[string]bool{}
 
 	// -------------------------------------------
 	// states
@@ -273,7 +704,7 @@ func (p *FileParser) parseEverything() error {
 		return err
 	}
 
-	for p.line != "transitions" {
+	for p.line != "initial" && p.line != "agents" && p.line != "transitions" {
 		stateName := p.line
 
 		if _, ok := p.statesMap[stateName]; ok {
@@ -281,6 +712,7 @@ func (p *FileParser) parseEverything() error {
 		}
 
 		p.statesMap[stateName] = p.ks.NewState(stateName)
+		p.symbols.States[stateName] = p.declaration(0)
 
 		if err := p.nextLine(); err != nil {
 			if err == io.EOF {
@@ -290,6 +722,62 @@ func (p *FileParser) parseEverything() error {
 		}
 	}
 
+	// -------------------------------------------
+	// initial (optional)
+	// -------------------------------------------
+
+	if p.line == "initial" {
+		if err := p.nextLine(); err != nil {
+			return err
+		}
+
+		for p.line != "agents" && p.line != "transitions" {
+			state, ok := p.statesMap[p.line]
+			if !ok {
+				return p.errorf("unknown initial state: %s", p.line)
+			}
+			p.ks.AddInitialStates(state)
+
+			if err := p.nextLine(); err != nil {
+				if err == io.EOF {
+					return p.errorf("expected \"transitions\", but could not find it")
+				}
+				return err
+			}
+		}
+	}
+
+	// -------------------------------------------
+	// agents (optional)
+	// -------------------------------------------
+
+	if p.line == "agents" {
+		if err := p.nextLine(); err != nil {
+			return err
+		}
+
+		agents := make([]string, 0)
+		for p.line != "transitions" {
+			if !actionPattern.MatchString(p.line) {
+				return p.errorf("invalid agent: %s", p.line)
+			}
+			for _, agent := range agents {
+				if agent == p.line {
+					return p.errorf("duplicate agent: %s", p.line)
+				}
+			}
+			agents = append(agents, p.line)
+
+			if err := p.nextLine(); err != nil {
+				if err == io.EOF {
+					return p.errorf("expected \"transitions\", but could not find it")
+				}
+				return err
+			}
+		}
+		p.ks.SetAgents(agents...)
+	}
+
 	// -------------------------------------------
 	// transitions
 	// -------------------------------------------
@@ -298,20 +786,56 @@ func (p *FileParser) parseEverything() error {
 		return err
 	}
 
+	markovChain, decisionProcess, game := false, false, false
 	for p.line != "labels" {
-		parts := strings.Split(p.line, " ")
+		parts := strings.Fields(p.line)
 
 		var prevState cav.IState
 		var right bool
+		var weight int
+		var probability float64
+		var action string
+		var move []string
 
 		for i, part := range parts {
 			if i%2 == 1 {
+				weight, probability, action, move = 1, 0, "", nil
 				if part == "->" {
 					right = true
 				} else if part == "<-" {
 					right = false
+				} else if match := weightedArrowPattern.FindStringSubmatch(part); match != nil {
+					var err error
+					if weight, err = strconv.Atoi(match[1] + match[2]); err != nil {
+						return p.errorf("invalid weight for transition: %s", part)
+					}
+					right = match[1] != ""
+				} else if match := probabilityArrowPattern.FindStringSubmatch(part); match != nil {
+					var ok bool
+					if probability, ok = parseProbability(match[2] + match[4]); !ok {
+						return p.errorf("invalid probability for transition: %s", part)
+					}
+					right = match[2] != ""
+					action = match[1] + match[3]
+					if action != "" {
+						decisionProcess = true
+					} else {
+						markovChain = true
+					}
+				} else if match := moveArrowPattern.FindStringSubmatch(part); match != nil {
+					move = strings.Split(match[1]+match[2], ",")
+					if len(move) != len(p.ks.GetAgents()) {
+						return p.errorf("move %s needs an action for each of the %d agents", cav.FormatMove(move), len(p.ks.GetAgents()))
+					}
+					for _, a := range move {
+						if !actionPattern.MatchString(a) {
+							return p.errorf("invalid action in move: %s", part)
+						}
+					}
+					right = match[1] != ""
+					game = true
 				} else {
-					return p.errorf("invalid transition, expected \"->\" or \"<-\", but got: %s", part)
+					return p.errorf("invalid transition, expected \"->\", \"<-\", \"-N->\", \"<-N-\", \"-(P)->\", \"<-(P)-\", \"-a(P)->\", \"<-a(P)-\", \"-<a,b>->\" or \"<-<a,b>-\", but got: %s", part)
 				}
 				continue
 			} else {
@@ -326,10 +850,20 @@ func (p *FileParser) parseEverything() error {
 				}
 
 				if i > 0 {
-					if right {
-						prevState.AddChildren(nextState)
-					} else {
-						nextState.AddChildren(prevState)
+					from, to := prevState, nextState
+					if !right {
+						from, to = nextState, prevState
+					}
+					from.AddWeightedChild(to, weight)
+					if move != nil {
+						if successor := from.GetSuccessor(move); successor != nil && successor != to {
+							return p.errorf("move %s from %s leads to both %s and %s", cav.FormatMove(move), from.GetName(), successor.GetName(), to.GetName())
+						}
+						from.AddMove(move, to)
+					} else if action != "" {
+						from.AddChoice(action, to, probability)
+					} else if probability > 0 {
+						from.AddProbabilisticChild(to, probability)
 					}
 				}
 
@@ -344,6 +878,19 @@ func (p *FileParser) parseEverything() error {
 			return err
 		}
 	}
+	if game {
+		if err := cav.CheckGameStructure(p.ks); err != nil {
+			return p.errorf("invalid concurrent game structure: %s", err.Error())
+		}
+	} else if decisionProcess {
+		if err := cav.CheckMarkovDecisionProcess(p.ks); err != nil {
+			return p.errorf("invalid Markov decision process: %s", err.Error())
+		}
+	} else if markovChain {
+		if err := cav.CheckMarkovChain(p.ks); err != nil {
+			return p.errorf("invalid Markov chain: %s", err.Error())
+		}
+	}
 
 	// -------------------------------------------
 	// labels
@@ -353,14 +900,14 @@ func (p *FileParser) parseEverything() error {
 		return err
 	}
 
-	for p.line != "formulas" {
+	for p.line != "rewards" && p.line != "definitions" && p.line != "formulas" {
 		parts := strings.Split(p.line, ":")
 
 		if len(parts) != 2 {
 			return p.errorf("invalid label definition, expected exactly one ':' but got: %s", p.line)
 		}
 
-		labelName := parts[0]
+		labelName := strings.Trim(parts[0], " ")
 
 		if _, ok := p.labelsMap[labelName]; ok {
 			return p.errorf("duplicate label: %s", labelName)
@@ -368,8 +915,13 @@ func (p *FileParser) parseEverything() error {
 
 		label := p.ks.NewLabel(labelName)
 		p.labelsMap[labelName] = label
+		p.symbols.Labels[labelName] = p.declaration(0)
 
 		stateNames := strings.Split(parts[1], ",")
+		if strings.Trim(parts[1], " ") == "" {
+			// a label that does not hold anywhere
+			stateNames = nil
+		}
 
 		for _, stateName := range stateNames {
 			stateName = strings.Trim(stateName, " ")
@@ -388,21 +940,91 @@ func (p *FileParser) parseEverything() error {
 		}
 	}
 
+	// -------------------------------------------
+	// rewards (optional)
+	// -------------------------------------------
+
+	if p.line == "rewards" {
+		if err := p.nextLine(); err != nil {
+			return err
+		}
+
+		for p.line != "definitions" && p.line != "formulas" {
+			if err := p.parseReward(p.line); err != nil {
+				return err
+			}
+
+			if err := p.nextLine(); err != nil {
+				if err == io.EOF {
+					return p.errorf("expected \"formulas\", but could not find it")
+				}
+				return err
+			}
+		}
+	}
+
+	// -------------------------------------------
+	// definitions (optional)
+	// -------------------------------------------
+
+	p.definitions = map[string]*definition{}
+	if p.line == "definitions" {
+		if err := p.nextLine(); err != nil {
+			return err
+		}
+
+		for p.line != "formulas" {
+			if err := p.parseDefinition(p.line); err != nil {
+				return err
+			}
+
+			if err := p.nextLine(); err != nil {
+				if err == io.EOF {
+					return p.errorf("expected \"formulas\", but could not find it")
+				}
+				return err
+			}
+		}
+
+		if err := p.checkDefinitions(); err != nil {
+			return err
+		}
+	}
+
 	// -------------------------------------------
 	// formulas
 	// -------------------------------------------
 
+	p.formulas = make([]cav.IFormula, 0)
+	p.nodes = make([]*ast.Node, 0)
+	p.expected = make([]Expectation, 0)
 	if err := p.nextLine(); err != nil {
+		if err == io.EOF {
+			// a model without any formulas is fine
+			return nil
+		}
 		return err
 	}
 
-	p.formulas = make([]cav.IFormula, 0)
 	for {
-		formula, err := p.parseFormula(p.line)
+		if p.line == "formulas" {
+			// an included model may already have opened the formulas section
+			if err := p.nextLine(); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return err
+			}
+			continue
+		}
+
+		node, formula, err := p.parseAnnotatedFormula(p.line)
 		if err != nil {
 			return err
 		}
 		p.formulas = append(p.formulas, formula)
+		p.nodes = append(p.nodes, node)
+		p.symbols.Formulas = append(p.symbols.Formulas, p.declaration(0))
 
 		if err := p.nextLine(); err != nil {
 			if err == io.EOF {
@@ -415,24 +1037,44 @@ func (p *FileParser) parseEverything() error {
 }
 
 func (p *FileParser) ParseFile(path string) (cav.IKripkeStructure, []cav.IFormula, error) {
-	if _, err := os.Stat(path); err != nil {
+	file, err := os.Open(path)
+	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil, fmt.Errorf("file %s does not exist: %s", path, err.Error())
-		} else {
-			return nil, nil, err
 		}
+		return nil, nil, err
 	}
-	file, err := os.Open(path)
+	defer file.Close()
+
+	abs, err := filepath.Abs(path)
 	if err != nil {
 		return nil, nil, err
 	}
-	defer file.Close()
+	return p.parse(&source{bufio.NewScanner(file), nil, abs, filepath.Dir(abs), 0})
+}
 
-	p.scanner = bufio.NewScanner(file)
+// Parse parses the native file format, includes are resolved relative to the working directory
+func (p *FileParser) Parse(r io.Reader) (cav.IKripkeStructure, []cav.IFormula, error) {
+	return p.parse(&source{bufio.NewScanner(r), nil, "", ".", 0})
+}
+
+func (p *FileParser) parse(root *source) (cav.IKripkeStructure, []cav.IFormula, error) {
+	p.sources = nil
+	p.pushSource(root)
+	defer func() {
+		for len(p.sources) > 1 {
+			p.popSource()
+		}
+	}()
 	p.line = ""
-	p.lineNr = 0
+	p.ks = nil
+	p.formulas = nil
+	p.nodes = nil
+	p.expected = nil
+	p.definitions = nil
+	p.symbols = Symbols{map[string]Declaration{}, map[string]Declaration{}, map[string]Declaration{}, make([]Declaration, 0)}
 
-	err = p.parseEverything()
+	err := p.parseEverything()
 
 	if err == io.EOF {
 		err = p.errorf("unexpected end of file")
@@ -441,4 +1083,98 @@ func (p *FileParser) ParseFile(path string) (cav.IKripkeStructure, []cav.IFormul
 	return p.ks, p.formulas, err
 }
 
+func (p *FileParser) ParseString(s string) (cav.IKripkeStructure, []cav.IFormula, error) {
+	return p.Parse(strings.NewReader(s))
+}
+
+// ParseStringAt parses s as the contents of the file at path, e.g. an unsaved editor buffer,
+// so that includes are resolved relative to the directory of path
+func (p *FileParser) ParseStringAt(s string, path string) (cav.IKripkeStructure, []cav.IFormula, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p.parse(&source{bufio.NewScanner(strings.NewReader(s)), nil, abs, filepath.Dir(abs), 0})
+}
+
+// GetSymbols returns the declarations of the last parsed file, even if parsing failed half way
+func (p *FileParser) GetSymbols() Symbols {
+	return p.symbols
+}
+
+// GetExpectations returns the expected results annotated in the formulas section of the last parsed file
+func (p *FileParser) GetExpectations() []Expectation {
+	return p.expected
+}
+
+// GetNodes returns the syntax trees of the formulas of the last parsed file, nil for LTL, CTL*, mu-calculus and PCTL formulas
+func (p *FileParser) GetNodes() []*ast.Node {
+	return p.nodes
+}
+
+// ParseFormula parses a single formula against an already existing Kripke structure
+func (p *FileParser) ParseFormula(ks cav.IKripkeStructure, s string) (cav.IFormula, error) {
+	p.ks = ks
+	_, formula, err := p.parseFormula(p.prepareFormula(s))
+	return formula, err
+}
+
+// ParseFormulaNode parses a single formula like ParseFormula and also returns its syntax tree,
+// which is nil for LTL, CTL*, mu-calculus, PCTL and ATL formulas
+func (p *FileParser) ParseFormulaNode(ks cav.IKripkeStructure, s string) (cav.IFormula, *ast.Node, error) {
+	p.ks = ks
+	node, formula, err := p.parseFormula(p.prepareFormula(s))
+	return formula, node, err
+}
+
+// ParseNode parses a single formula without binding it to any Kripke structure
+func (p *FileParser) ParseNode(s string) (*ast.Node, error) {
+	return p.parseNode(p.prepareFormula(s), 0)
+}
+
+// prepareFormula sets up the parser for a formula given on its own, as if it was the first line of a file
+func (p *FileParser) prepareFormula(s string) string {
+	p.lineNr = 1
+	s = strings.Replace(s, "\t", " ", -1)
+	trimmed := strings.TrimLeft(s, " ")
+	p.column = len(s) - len(trimmed) + 1
+	return trimmed
+}
+
+// A FileParser keeps the state of the file it is currently parsing, so it must not be shared between goroutines.
+// The functions below use a fresh parser for every call and are safe for concurrent use.
+
+func MakeFileParser() *FileParser {
+	return &FileParser{}
+}
+
+func ParseFile(path string) (cav.IKripkeStructure, []cav.IFormula, error) {
+	return MakeFileParser().ParseFile(path)
+}
+
+func Parse(r io.Reader) (cav.IKripkeStructure, []cav.IFormula, error) {
+	return MakeFileParser().Parse(r)
+}
+
+func ParseString(s string) (cav.IKripkeStructure, []cav.IFormula, error) {
+	return MakeFileParser().ParseString(s)
+}
+
+// ParseFormula parses a single formula against an already existing Kripke structure
+func ParseFormula(ks cav.IKripkeStructure, text string) (cav.IFormula, error) {
+	return MakeFileParser().ParseFormula(ks, text)
+}
+
+// ParseFormulaNode parses a single formula against an already existing Kripke structure together with its syntax tree
+func ParseFormulaNode(ks cav.IKripkeStructure, text string) (cav.IFormula, *ast.Node, error) {
+	return MakeFileParser().ParseFormulaNode(ks, text)
+}
+
+// ParseNode parses a single formula into a syntax tree which can be bound to any Kripke structure
+func ParseNode(text string) (*ast.Node, error) {
+	return MakeFileParser().ParseNode(text)
+}
+
+// Deprecated: PARSER is shared by everyone using it and thus not safe for concurrent use,
+// use ParseFile or MakeFileParser instead.
 var PARSER IFileParser = &FileParser{}
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"cav/golang/types"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Write writes the Kripke structure and the given formulas in the format read by ParseFile
+func Write(w io.Writer, ks cav.IKripkeStructure, formulas ...string) error {
+	var sb strings.Builder
+
+	states := sortedStates(ks.GetStates())
+	sb.WriteString("states\n")
+	for _, state := range states {
+		sb.WriteString(state.GetName() + "\n")
+	}
+
+	// all states are initial if none have been declared
+	if initial := ks.GetInitialStates(); initial.Size() < ks.GetStates().Size() {
+		sb.WriteString("\ninitial\n")
+		for _, state := range sortedStates(initial) {
+			sb.WriteString(state.GetName() + "\n")
+		}
+	}
+
+	if agents := ks.GetAgents(); len(agents) > 0 {
+		sb.WriteString("\nagents\n")
+		for _, agent := range agents {
+			sb.WriteString(agent + "\n")
+		}
+	}
+
+	sb.WriteString("\ntransitions\n")
+	for _, state := range states {
+		if moves := state.GetMoves(); len(moves) > 0 {
+			for _, move := range moves {
+				sb.WriteString(fmt.Sprintf("%s -%s-> %s\n", state.GetName(), cav.FormatMove(move), state.GetSuccessor(move).GetName()))
+			}
+			continue
+		}
+		if actions := state.GetActions(); len(actions) > 0 {
+			for _, action := range actions {
+				choice := state.GetChoice(action)
+				for _, child := range sortedStates(state.GetChildren()) {
+					if probability, ok := choice[child]; ok {
+						sb.WriteString(fmt.Sprintf("%s -%s(%s)-> %s\n", state.GetName(), action, strconv.FormatFloat(probability, 'g', -1, 64), child.GetName()))
+					}
+				}
+			}
+			continue
+		}
+		for _, child := range sortedStates(state.GetChildren()) {
+			if probability := state.GetProbability(child); probability > 0 {
+				sb.WriteString(fmt.Sprintf("%s -(%s)-> %s\n", state.GetName(), strconv.FormatFloat(probability, 'g', -1, 64), child.GetName()))
+			} else if weight := state.GetWeight(child); weight != 1 {
+				sb.WriteString(fmt.Sprintf("%s -%d-> %s\n", state.GetName(), weight, child.GetName()))
+			} else {
+				sb.WriteString(fmt.Sprintf("%s -> %s\n", state.GetName(), child.GetName()))
+			}
+		}
+	}
+
+	labels := make([]cav.ILabel, 0, ks.GetLabels().Size())
+	ks.GetLabels().ForEach(func(label cav.ILabel) {
+		labels = append(labels, label)
+	})
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].String() < labels[j].String()
+	})
+
+	sb.WriteString("\nlabels\n")
+	for _, label := range labels {
+		names := make([]string, 0)
+		for _, state := range states {
+			if state.HasLabel(label) {
+				names = append(names, state.GetName())
+			}
+		}
+		sb.WriteString(label.String() + ": " + strings.Join(names, ", ") + "\n")
+	}
+
+	rewards := make([]string, 0)
+	for _, state := range states {
+		if reward := state.GetReward(); reward != 0 {
+			rewards = append(rewards, fmt.Sprintf("%s: %s\n", state.GetName(), strconv.FormatFloat(reward, 'g', -1, 64)))
+		}
+		for _, child := range sortedStates(state.GetChildren()) {
+			if reward := state.GetTransitionReward(child); reward != 0 {
+				rewards = append(rewards, fmt.Sprintf("%s -> %s: %s\n", state.GetName(), child.GetName(), strconv.FormatFloat(reward, 'g', -1, 64)))
+			}
+		}
+	}
+	if len(rewards) > 0 {
+		sb.WriteString("\nrewards\n" + strings.Join(rewards, ""))
+	}
+
+	sb.WriteString("\nformulas\n")
+	for _, formula := range formulas {
+		sb.WriteString(formula + "\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// sortedStates orders states by name, comparing embedded numbers numerically so that s2 comes before s10
+func sortedStates(set cav.ISet[cav.IState]) []cav.IState {
+	states := make([]cav.IState, 0, set.Size())
+	set.ForEach(func(state cav.IState) {
+		states = append(states, state)
+	})
+	sort.Slice(states, func(i, j int) bool {
+		a, b := states[i].GetName(), states[j].GetName()
+		if len(a) != len(b) && strings.TrimRight(a, "0123456789") == strings.TrimRight(b, "0123456789") {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
+	return states
+}
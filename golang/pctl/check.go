@@ -0,0 +1,418 @@
+package pctl
+
+import (
+	"cav/golang/types"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Precision is the largest change of a value in the last iteration of the equation solver, relative to the value if it exceeds 1
+const Precision = 1e-12
+
+// maxIterations stops the solver for chains converging too slowly to reach Precision
+const maxIterations = 1000000
+
+// tolerance absorbs the rounding errors of the solver when comparing a probability to a bound
+const tolerance = 1e-9
+
+// PCTLFormula is a PCTL formula checked on a Markov chain
+type PCTLFormula struct {
+	kripkeStructure cav.IKripkeStructure
+	formula         *Formula
+}
+
+// MakePCTLFormula checks that all labels of the formula exist in the Kripke structure, that it only uses Pmax and
+// Pmin if it is a Markov decision process and P and R otherwise, and that queries are only used as the outermost operator
+func MakePCTLFormula(ks cav.IKripkeStructure, f *Formula) (*PCTLFormula, error) {
+	decisionProcess := cav.IsMarkovDecisionProcess(ks)
+	if !decisionProcess && !cav.IsMarkovChain(ks) {
+		return nil, errors.New("probabilistic formulas need transitions with probabilities")
+	}
+	labels := map[string]bool{}
+	ks.GetLabels().ForEach(func(label cav.ILabel) {
+		labels[label.String()] = true
+	})
+	for _, name := range f.Labels() {
+		if !labels[name] {
+			return nil, fmt.Errorf("unknown label in formula: %s", name)
+		}
+	}
+	if err := checkOperators(f, decisionProcess, true); err != nil {
+		return nil, err
+	}
+	return &PCTLFormula{ks, f}, nil
+}
+
+func checkOperators(f *Formula, decisionProcess bool, outermost bool) error {
+	switch {
+	case f.IsQuery() && !outermost:
+		return fmt.Errorf("P=? is only allowed as the outermost operator: %s", f.String())
+	case f.Op == OpProb && decisionProcess && f.Optimum == "":
+		return fmt.Errorf("P needs to be Pmax or Pmin in a Markov decision process: %s", f.String())
+	case f.Op == OpProb && !decisionProcess && f.Optimum != "":
+		return fmt.Errorf("Pmax and Pmin need a Markov decision process: %s", f.String())
+	case f.Op == OpReward && decisionProcess:
+		return fmt.Errorf("rewards are not supported for Markov decision processes: %s", f.String())
+	case f.Op == OpReward && f.Path == PathC && f.Steps > maxIterations:
+		// rewards of periodic chains keep changing from step to step, so all steps might have to be computed
+		return fmt.Errorf("C is limited to %d steps: %s", maxIterations, f.String())
+	}
+	for _, sub := range f.Sub {
+		if err := checkOperators(sub, decisionProcess, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check returns the states satisfying the formula, for P=? [path] those in which the path formula has a positive
+// probability and for R=? [path] those in which the expected reward is finite
+func (f *PCTLFormula) Check() cav.ISet[cav.IState] {
+	e := makeSolver(f.kripkeStructure)
+	switch {
+	case f.formula.IsQuery() && f.formula.Op == OpProb:
+		return e.compare(e.probabilities(f.formula), ">", 0)
+	case f.formula.IsQuery():
+		return e.compare(e.rewards(f.formula), "<", math.Inf(1))
+	}
+	return e.check(f.formula)
+}
+
+// Probabilities returns the probability of the path formula in every state if the formula is a probabilistic
+// operator, and nil otherwise
+func (f *PCTLFormula) Probabilities() map[cav.IState]float64 {
+	if f.formula.Op != OpProb {
+		return nil
+	}
+	return makeSolver(f.kripkeStructure).probabilities(f.formula)
+}
+
+// Rewards returns the expected reward of the path formula in every state if the formula is a reward operator,
+// and nil otherwise. The reward of F a is infinite in states from which a is reached with a probability below 1.
+func (f *PCTLFormula) Rewards() map[cav.IState]float64 {
+	if f.formula.Op != OpReward {
+		return nil
+	}
+	return makeSolver(f.kripkeStructure).rewards(f.formula)
+}
+
+// Convergence returns how the iterative solution of the outermost operator ended, nil if it is not solved iteratively
+func (f *PCTLFormula) Convergence() *Convergence {
+	if f.formula.Op != OpProb && f.formula.Op != OpReward {
+		return nil
+	}
+	s := makeSolver(f.kripkeStructure)
+	s.check(f.formula)
+	return s.convergence
+}
+
+// Scheduler returns an action for every state of a Markov decision process, which as a memoryless adversary attains
+// the probability of the outermost Pmax or Pmin. It is nil for other formulas and for bounded path formulas, for
+// which memoryless schedulers are not enough in general.
+func (f *PCTLFormula) Scheduler() map[cav.IState]string {
+	if f.formula.Op != OpProb || f.formula.Optimum == "" {
+		return nil
+	}
+	s := makeSolver(f.kripkeStructure)
+	s.check(f.formula)
+	return s.scheduler
+}
+
+func (f *PCTLFormula) GetKripkeStructure() cav.IKripkeStructure {
+	return f.kripkeStructure
+}
+
+func (f *PCTLFormula) String() string {
+	return "PCTL " + f.formula.String()
+}
+
+type solver struct {
+	ks     cav.IKripkeStructure
+	labels map[string]cav.ILabel
+	states []cav.IState
+	// the successors of every state sorted by name, so that sums of probabilities do not depend on the map order
+	children map[cav.IState][]cav.IState
+	// the convergence and the scheduler of the last operator solved, which is the outermost one once done
+	convergence *Convergence
+	scheduler   map[cav.IState]string
+}
+
+func makeSolver(ks cav.IKripkeStructure) *solver {
+	// a fixed order makes the iterations of the solver reproducible
+	s := &solver{ks: ks, labels: map[string]cav.ILabel{}, states: cav.SortedStates(ks.GetStates()), children: map[cav.IState][]cav.IState{}}
+	ks.GetLabels().ForEach(func(label cav.ILabel) {
+		s.labels[label.String()] = label
+	})
+	for _, state := range s.states {
+		s.children[state] = cav.SortedStates(state.GetChildren())
+	}
+	return s
+}
+
+func (s *solver) check(f *Formula) cav.ISet[cav.IState] {
+	switch f.Op {
+	case OpTrue:
+		return s.ks.GetStates().Copy()
+	case OpFalse:
+		return cav.MakeSet[cav.IState]()
+	case OpLabel:
+		result := cav.MakeSet[cav.IState]()
+		for _, state := range s.states {
+			if state.HasLabel(s.labels[f.Name]) {
+				result.Add(state)
+			}
+		}
+		return result
+	case OpNot:
+		return s.ks.GetStates().Minus(s.check(f.Sub[0]))
+	case OpAnd:
+		return s.check(f.Sub[0]).Intersect(s.check(f.Sub[1]))
+	case OpOr:
+		return s.check(f.Sub[0]).Union(s.check(f.Sub[1]))
+	case OpImplies:
+		return s.ks.GetStates().Minus(s.check(f.Sub[0])).Union(s.check(f.Sub[1]))
+	}
+	if f.Op == OpReward {
+		return s.compare(s.rewards(f), f.Comparison, f.Bound)
+	}
+	return s.compare(s.probabilities(f), f.Comparison, f.Bound)
+}
+
+func (s *solver) compare(probabilities map[cav.IState]float64, comparison string, bound float64) cav.ISet[cav.IState] {
+	result := cav.MakeSet[cav.IState]()
+	for _, state := range s.states {
+		p := probabilities[state]
+		var holds bool
+		switch comparison {
+		case "<":
+			holds = p < bound-tolerance
+		case "<=":
+			holds = p <= bound+tolerance
+		case ">":
+			holds = p > bound+tolerance
+		default:
+			holds = p >= bound-tolerance
+		}
+		if holds {
+			result.Add(state)
+		}
+	}
+	return result
+}
+
+// probabilities computes the probability of the path formula of a probabilistic operator in every state
+func (s *solver) probabilities(f *Formula) map[cav.IState]float64 {
+	operands := s.operands(f)
+	if f.Optimum != "" {
+		return s.optimal(f, operands)
+	}
+	all := s.ks.GetStates()
+	switch f.Path {
+	case PathX:
+		sat := operands[0]
+		result := map[cav.IState]float64{}
+		for _, state := range s.states {
+			result[state] = s.step(state, func(child cav.IState) float64 {
+				if sat.Contains(child) {
+					return 1
+				}
+				return 0
+			})
+		}
+		return result
+	case PathF:
+		return s.until(all, operands[0], f.Steps)
+	case PathG:
+		// a path satisfies G a unless it satisfies F NOT a
+		result := s.until(all, all.Minus(operands[0]), f.Steps)
+		for _, state := range s.states {
+			result[state] = 1 - result[state]
+		}
+		return result
+	}
+	return s.until(operands[0], operands[1], f.Steps)
+}
+
+// operands checks the operands of the path formula, and then forgets how nested operators have been solved
+func (s *solver) operands(f *Formula) []cav.ISet[cav.IState] {
+	operands := make([]cav.ISet[cav.IState], len(f.Sub))
+	for i, sub := range f.Sub {
+		operands[i] = s.check(sub)
+	}
+	s.convergence, s.scheduler = nil, nil
+	return operands
+}
+
+// step returns the expected value of value over the successors of the state
+func (s *solver) step(state cav.IState, value func(cav.IState) float64) float64 {
+	sum := 0.0
+	for _, child := range s.children[state] {
+		sum += state.GetProbability(child) * value(child)
+	}
+	return sum
+}
+
+// until computes the probability of a U b, within the given number of steps unless it is negative
+func (s *solver) until(a cav.ISet[cav.IState], b cav.ISet[cav.IState], steps int) map[cav.IState]float64 {
+	result := map[cav.IState]float64{}
+	for _, state := range s.states {
+		result[state] = 0
+		if b.Contains(state) {
+			result[state] = 1
+		}
+	}
+	if steps >= 0 {
+		// the probabilities grow towards those of the unbounded until, so huge bounds stop once they have settled
+		for i := 0; i < min(steps, maxIterations); i++ {
+			next := map[cav.IState]float64{}
+			change := 0.0
+			for _, state := range s.states {
+				next[state] = result[state]
+				if !b.Contains(state) && a.Contains(state) {
+					next[state] = s.step(state, func(child cav.IState) float64 {
+						return result[child]
+					})
+				}
+				change = math.Max(change, next[state]-result[state])
+			}
+			result = next
+			if change == 0 || (change < Precision && i >= len(s.states)) {
+				break
+			}
+		}
+		return result
+	}
+
+	prob0, prob1 := s.qualitative(a, b)
+	unknown := make([]cav.IState, 0)
+	for _, state := range s.states {
+		if prob1.Contains(state) {
+			result[state] = 1
+		} else if !prob0.Contains(state) {
+			unknown = append(unknown, state)
+		}
+	}
+
+	s.iterate(unknown, result, func(state cav.IState) float64 {
+		return s.step(state, func(child cav.IState) float64 {
+			return result[child]
+		})
+	})
+	return result
+}
+
+// Convergence describes how the iterative solution of the outermost operator of a formula ended
+type Convergence struct {
+	Iterations int
+	Change     float64 // largest change in the last iteration, relative to the value if it exceeds 1
+	Converged  bool    // whether Change is below Precision, otherwise the solver gave up after too many iterations
+}
+
+// iterate updates the values of the states Gauss-Seidel style, using the values of the current iteration as soon
+// as they are computed, until no value changes by Precision anymore
+func (s *solver) iterate(states []cav.IState, values map[cav.IState]float64, update func(cav.IState) float64) {
+	s.convergence = &Convergence{}
+	for s.convergence.Iterations < maxIterations {
+		change := 0.0
+		for _, state := range states {
+			value := update(state)
+			change = math.Max(change, math.Abs(value-values[state])/math.Max(1, value))
+			values[state] = value
+		}
+		s.convergence.Iterations++
+		s.convergence.Change = change
+		if change < Precision {
+			s.convergence.Converged = true
+			return
+		}
+	}
+}
+
+// qualitative returns the states in which a U b holds with probability 0 and 1. The former cannot reach b along a,
+// the latter cannot reach any of them before b holds, which leaves a system of equations with a unique solution.
+func (s *solver) qualitative(a cav.ISet[cav.IState], b cav.ISet[cav.IState]) (cav.ISet[cav.IState], cav.ISet[cav.IState]) {
+	ks := s.ks
+	prob0 := ks.GetStates().Minus(ks.MakeEUFormula(s.constant(a), s.constant(b)).Check())
+	prob1 := ks.GetStates().Minus(ks.MakeEUFormula(s.constant(a.Minus(b)), s.constant(prob0)).Check())
+	return prob0, prob1
+}
+
+// reward returns the reward of the state plus the expected reward of its transition and the value of the successor
+func (s *solver) reward(state cav.IState, value map[cav.IState]float64) float64 {
+	return state.GetReward() + s.step(state, func(child cav.IState) float64 {
+		return state.GetTransitionReward(child) + value[child]
+	})
+}
+
+// rewards computes the expected reward of the path formula of a reward operator in every state, which for F a is
+// accumulated until a holds and for C<=k in the first k steps
+func (s *solver) rewards(f *Formula) map[cav.IState]float64 {
+	operands := s.operands(f)
+	result := map[cav.IState]float64{}
+	for _, state := range s.states {
+		result[state] = 0
+	}
+	if f.Path == PathC {
+		// once every step adds exactly the same rewards as the one before, like when all states reached are absorbing,
+		// so do all remaining steps, which are added up at once
+		var increments map[cav.IState]float64
+		for i := 0; i < f.Steps; i++ {
+			next := map[cav.IState]float64{}
+			added := map[cav.IState]float64{}
+			settled := increments != nil
+			for _, state := range s.states {
+				next[state] = s.reward(state, result)
+				added[state] = next[state] - result[state]
+				settled = settled && added[state] == increments[state]
+			}
+			result, increments = next, added
+			if settled {
+				for _, state := range s.states {
+					result[state] += float64(f.Steps-i-1) * increments[state]
+				}
+				break
+			}
+		}
+		return result
+	}
+
+	goal := operands[0]
+	_, reached := s.qualitative(s.ks.GetStates(), goal)
+	unknown := make([]cav.IState, 0)
+	for _, state := range s.states {
+		if !reached.Contains(state) {
+			result[state] = math.Inf(1)
+		} else if !goal.Contains(state) {
+			unknown = append(unknown, state)
+		}
+	}
+
+	// the successors of states reaching a almost surely do so as well, so only finite values are added up
+	s.iterate(unknown, result, func(state cav.IState) float64 {
+		return s.reward(state, result)
+	})
+	return result
+}
+
+// constant returns a formula satisfied by the given states, so the CTL operators can be applied to computed sets
+func (s *solver) constant(states cav.ISet[cav.IState]) cav.IFormula {
+	return &constantFormula{s.ks, states}
+}
+
+type constantFormula struct {
+	kripkeStructure cav.IKripkeStructure
+	states          cav.ISet[cav.IState]
+}
+
+func (f *constantFormula) Check() cav.ISet[cav.IState] {
+	return f.states.Copy()
+}
+
+func (f *constantFormula) GetKripkeStructure() cav.IKripkeStructure {
+	return f.kripkeStructure
+}
+
+func (f *constantFormula) String() string {
+	return f.states.String()
+}
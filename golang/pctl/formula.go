@@ -0,0 +1,125 @@
+package pctl
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type Op int
+
+const (
+	OpTrue Op = iota
+	OpFalse
+	OpLabel
+	OpNot
+	OpAnd
+	OpOr
+	OpImplies
+	OpProb   // P~p [path]: the probability of the path formula compares to Bound as given by Comparison
+	OpReward // R~r [path]: the expected reward accumulated along the path formula F a or C<=k compares to Bound
+)
+
+// PathOp is the temporal operator of the path formula of a probabilistic operator
+type PathOp int
+
+const (
+	PathX PathOp = iota
+	PathU
+	PathF
+	PathG
+	PathC // C<=k: the first k steps, only used by OpReward
+)
+
+// Query is the comparison of P=? [path], which asks for the probability instead of comparing it
+const Query = "=?"
+
+// Formula is a PCTL state formula. Name is only used by OpLabel, all other fields besides Op and Sub only by OpProb
+// and OpReward, whose Sub are the operands of the path formula.
+type Formula struct {
+	Op         Op
+	Name       string
+	Sub        []*Formula
+	Comparison string // one of "<", "<=", ">", ">=" or Query
+	Optimum    string // "max" or "min" for Pmax and Pmin, which range over the schedulers of a Markov decision process
+	Bound      float64
+	Path       PathOp
+	Steps      int // upper bound on the steps of U, F and G, -1 if there is none
+}
+
+func MakeFormula(op Op, sub ...*Formula) *Formula {
+	return &Formula{Op: op, Sub: sub}
+}
+
+func MakeLabel(name string) *Formula {
+	return &Formula{Op: OpLabel, Name: name}
+}
+
+// MakeProb returns P~bound [path], steps being -1 for an unbounded path formula
+func MakeProb(comparison string, bound float64, path PathOp, steps int, sub ...*Formula) *Formula {
+	return &Formula{Op: OpProb, Sub: sub, Comparison: comparison, Bound: bound, Path: path, Steps: steps}
+}
+
+// MakeReward returns R~bound [path], path being PathF or PathC
+func MakeReward(comparison string, bound float64, path PathOp, steps int, sub ...*Formula) *Formula {
+	return &Formula{Op: OpReward, Sub: sub, Comparison: comparison, Bound: bound, Path: path, Steps: steps}
+}
+
+// IsQuery reports whether the formula is P=? [path] or R=? [path]
+func (f *Formula) IsQuery() bool {
+	return (f.Op == OpProb || f.Op == OpReward) && f.Comparison == Query
+}
+
+// String returns the formula in the syntax read by Parse, with every binary operator in brackets
+func (f *Formula) String() string {
+	switch f.Op {
+	case OpTrue:
+		return "true"
+	case OpFalse:
+		return "false"
+	case OpLabel:
+		return f.Name
+	case OpNot:
+		return "NOT " + f.Sub[0].String()
+	case OpAnd:
+		return fmt.Sprintf("(%s AND %s)", f.Sub[0].String(), f.Sub[1].String())
+	case OpOr:
+		return fmt.Sprintf("(%s OR %s)", f.Sub[0].String(), f.Sub[1].String())
+	case OpImplies:
+		return fmt.Sprintf("(%s IMPLIES %s)", f.Sub[0].String(), f.Sub[1].String())
+	}
+
+	prefix := "P" + f.Optimum + f.Comparison
+	if f.Op == OpReward {
+		prefix = "R" + f.Comparison
+	}
+	if f.Comparison != Query {
+		prefix += strconv.FormatFloat(f.Bound, 'g', -1, 64)
+	}
+	steps := ""
+	if f.Steps >= 0 {
+		steps = fmt.Sprintf("<=%d", f.Steps)
+	}
+	switch f.Path {
+	case PathX:
+		return fmt.Sprintf("%s [X %s]", prefix, f.Sub[0].String())
+	case PathU:
+		return fmt.Sprintf("%s [%s U%s %s]", prefix, f.Sub[0].String(), steps, f.Sub[1].String())
+	case PathF:
+		return fmt.Sprintf("%s [F%s %s]", prefix, steps, f.Sub[0].String())
+	case PathC:
+		return fmt.Sprintf("%s [C%s]", prefix, steps)
+	}
+	return fmt.Sprintf("%s [G%s %s]", prefix, steps, f.Sub[0].String())
+}
+
+// Labels returns the names of all labels occurring in the formula
+func (f *Formula) Labels() []string {
+	if f.Op == OpLabel {
+		return []string{f.Name}
+	}
+	result := make([]string, 0)
+	for _, sub := range f.Sub {
+		result = append(result, sub.Labels()...)
+	}
+	return result
+}
@@ -0,0 +1,255 @@
+package pctl
+
+import (
+	"cav/golang/types"
+	"math"
+)
+
+// optimal computes the maximal or minimal probability of the path formula of Pmax or Pmin over all schedulers,
+// which resolve the choice between the actions of a state
+func (s *solver) optimal(f *Formula, operands []cav.ISet[cav.IState]) map[cav.IState]float64 {
+	maximize := f.Optimum == "max"
+	all := s.ks.GetStates()
+	switch f.Path {
+	case PathX:
+		sat := operands[0]
+		result := map[cav.IState]float64{}
+		s.scheduler = map[cav.IState]string{}
+		for _, state := range s.states {
+			result[state], s.scheduler[state] = s.best(state, maximize, func(child cav.IState) float64 {
+				if sat.Contains(child) {
+					return 1
+				}
+				return 0
+			})
+		}
+		return result
+	case PathF:
+		return s.optimalUntil(all, operands[0], f.Steps, maximize)
+	case PathG:
+		// the best scheduler for G a is the worst one for F NOT a
+		result := s.optimalUntil(all, all.Minus(operands[0]), f.Steps, !maximize)
+		for _, state := range s.states {
+			result[state] = 1 - result[state]
+		}
+		return result
+	}
+	return s.optimalUntil(operands[0], operands[1], f.Steps, maximize)
+}
+
+// best returns the best expected value of value over the actions of the state, and the action attaining it whose
+// name comes first
+func (s *solver) best(state cav.IState, maximize bool, value func(cav.IState) float64) (float64, string) {
+	result, action := 0.0, ""
+	for _, a := range state.GetActions() {
+		sum := s.expected(state, a, value)
+		if action == "" || (maximize && sum > result) || (!maximize && sum < result) || (sum == result && a < action) {
+			result, action = sum, a
+		}
+	}
+	return result, action
+}
+
+// expected returns the expected value of value after taking the action, summing over the successors in the order
+// of their names
+func (s *solver) expected(state cav.IState, action string, value func(cav.IState) float64) float64 {
+	choice := state.GetChoice(action)
+	sum := 0.0
+	for _, child := range s.children[state] {
+		if p, ok := choice[child]; ok {
+			sum += p * value(child)
+		}
+	}
+	return sum
+}
+
+// optimalUntil computes the maximal or minimal probability of a U b, within the given number of steps unless it is
+// negative. Only the unbounded until gets a scheduler, as bounded ones may need to count the steps.
+func (s *solver) optimalUntil(a cav.ISet[cav.IState], b cav.ISet[cav.IState], steps int, maximize bool) map[cav.IState]float64 {
+	result := map[cav.IState]float64{}
+	for _, state := range s.states {
+		result[state] = 0
+		if b.Contains(state) {
+			result[state] = 1
+		}
+	}
+	value := func(child cav.IState) float64 {
+		return result[child]
+	}
+	if steps >= 0 {
+		for i := 0; i < steps; i++ {
+			next := map[cav.IState]float64{}
+			for _, state := range s.states {
+				next[state] = result[state]
+				if !b.Contains(state) && a.Contains(state) {
+					next[state], _ = s.best(state, maximize, value)
+				}
+			}
+			result = next
+		}
+		return result
+	}
+
+	prob0, prob1 := s.optimalQualitative(a, b, maximize)
+	unknown, positive := make([]cav.IState, 0), make([]cav.IState, 0)
+	for _, state := range s.states {
+		if prob1.Contains(state) {
+			result[state] = 1
+		} else if !prob0.Contains(state) {
+			unknown = append(unknown, state)
+		}
+		if !b.Contains(state) && !prob0.Contains(state) {
+			positive = append(positive, state)
+		}
+	}
+	// starting from 0 the iteration approaches the least solution of the equations, which is the optimal probability
+	s.iterate(unknown, result, func(state cav.IState) float64 {
+		p, _ := s.best(state, maximize, value)
+		return p
+	})
+
+	if maximize {
+		s.scheduler = s.maxScheduler(positive, b, result)
+	} else {
+		s.scheduler = s.minScheduler(unknown, prob0, result)
+	}
+	return result
+}
+
+// optimalQualitative returns the states in which a U b has probability 0 and 1 under the best scheduler
+func (s *solver) optimalQualitative(a cav.ISet[cav.IState], b cav.ISet[cav.IState], maximize bool) (cav.ISet[cav.IState], cav.ISet[cav.IState]) {
+	prob0 := s.optimalProb0(a, b, maximize)
+	if !maximize {
+		// every scheduler reaches b almost surely unless some scheduler can reach a state with probability 0
+		ks := s.ks
+		return prob0, ks.GetStates().Minus(ks.MakeEUFormula(s.constant(a.Minus(b)), s.constant(prob0)).Check())
+	}
+
+	// a scheduler reaches b almost surely from the states that can reach it with actions staying among such states
+	prob1 := s.ks.GetStates().Minus(prob0)
+	for changed := true; changed; {
+		reaching := b.Copy()
+		for grown := true; grown; {
+			grown = false
+			for _, state := range s.states {
+				if !reaching.Contains(state) && a.Contains(state) && s.progressingAction(state, prob1, reaching) != "" {
+					reaching.Add(state)
+					grown = true
+				}
+			}
+		}
+		changed = !reaching.Equals(prob1)
+		prob1 = reaching
+	}
+	return prob0, prob1
+}
+
+// optimalProb0 returns the states in which a U b has probability 0 under the best scheduler. Maximizing, these
+// cannot reach b along a at all. Minimizing, some scheduler stays in states with a and without b forever, or leaves
+// them to states without a, which is the greatest set of such states having an action that never leaves it.
+func (s *solver) optimalProb0(a cav.ISet[cav.IState], b cav.ISet[cav.IState], maximize bool) cav.ISet[cav.IState] {
+	if maximize {
+		return s.ks.GetStates().Minus(s.ks.MakeEUFormula(s.constant(a), s.constant(b)).Check())
+	}
+	result := s.ks.GetStates().Minus(b)
+	for changed := true; changed; {
+		changed = false
+		for _, state := range s.states {
+			if result.Contains(state) && a.Contains(state) && s.stayingAction(state, result) == "" {
+				result.Remove(state)
+				changed = true
+			}
+		}
+	}
+	return result
+}
+
+// progressingAction returns the first action of the state whose successors are all in the given set, and one of
+// them in target, or "" if there is none
+func (s *solver) progressingAction(state cav.IState, states cav.ISet[cav.IState], target cav.ISet[cav.IState]) string {
+	for _, action := range state.GetActions() {
+		staying, progress := true, false
+		for child := range state.GetChoice(action) {
+			staying = staying && states.Contains(child)
+			progress = progress || target.Contains(child)
+		}
+		if staying && progress {
+			return action
+		}
+	}
+	return ""
+}
+
+// stayingAction returns the first action of the state whose successors are all in the given set, or "" if there is none
+func (s *solver) stayingAction(state cav.IState, states cav.ISet[cav.IState]) string {
+	for _, action := range state.GetActions() {
+		staying := true
+		for child := range state.GetChoice(action) {
+			staying = staying && states.Contains(child)
+		}
+		if staying {
+			return action
+		}
+	}
+	return ""
+}
+
+// minScheduler picks an action attaining the minimal probability in the unknown states, and in the states with
+// probability 0 one that stays among them, since picking any minimal action there could still reach b
+func (s *solver) minScheduler(unknown []cav.IState, prob0 cav.ISet[cav.IState], values map[cav.IState]float64) map[cav.IState]string {
+	scheduler := s.defaultScheduler()
+	for _, state := range unknown {
+		_, scheduler[state] = s.best(state, false, func(child cav.IState) float64 {
+			return values[child]
+		})
+	}
+	for _, state := range s.states {
+		if action := s.stayingAction(state, prob0); prob0.Contains(state) && action != "" {
+			scheduler[state] = action
+		}
+	}
+	return scheduler
+}
+
+// maxScheduler picks an action attaining the maximal probability in the given states. As an action may attain it
+// by just moving between such states without ever reaching b, the states are decided backwards from b, each picking
+// the first maximal action by name that moves to a decided state with a positive probability.
+func (s *solver) maxScheduler(positive []cav.IState, b cav.ISet[cav.IState], values map[cav.IState]float64) map[cav.IState]string {
+	scheduler := s.defaultScheduler()
+	decided := b.Copy()
+	for changed := true; changed; {
+		changed = false
+		for _, state := range positive {
+			if decided.Contains(state) {
+				continue
+			}
+			for _, action := range state.GetActions() {
+				progress := false
+				for child := range state.GetChoice(action) {
+					progress = progress || decided.Contains(child)
+				}
+				sum := s.expected(state, action, func(child cav.IState) float64 {
+					return values[child]
+				})
+				if progress && sum >= values[state]-tolerance*math.Max(1, values[state]) {
+					scheduler[state] = action
+					decided.Add(state)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return scheduler
+}
+
+// defaultScheduler picks the first action of every state, which is as good as any other where the choice does not matter
+func (s *solver) defaultScheduler() map[cav.IState]string {
+	scheduler := map[cav.IState]string{}
+	for _, state := range s.states {
+		if actions := state.GetActions(); len(actions) > 0 {
+			scheduler[state] = actions[0]
+		}
+	}
+	return scheduler
+}
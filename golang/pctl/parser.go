@@ -0,0 +1,323 @@
+package pctl
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError is returned by Parse, Column being the 1-based byte offset into the parsed text
+type SyntaxError struct {
+	Column  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Message)
+}
+
+type token struct {
+	text   string
+	offset int
+}
+
+var symbols = []string{"<=", ">=", "=?", "->", "&&", "||", "(", ")", "[", "]", "!", "&", "|", "<", ">"}
+
+var keywords = map[string]string{
+	"->": "IMPLIES", "&&": "AND", "&": "AND", "||": "OR", "|": "OR", "!": "NOT",
+}
+
+func tokenize(s string) []token {
+	tokens := make([]token, 0)
+	for i := 0; i < len(s); {
+		if s[i] == ' ' || s[i] == '\t' {
+			i++
+			continue
+		}
+		symbol := ""
+		for _, candidate := range symbols {
+			if strings.HasPrefix(s[i:], candidate) {
+				symbol = candidate
+				break
+			}
+		}
+		if symbol != "" {
+			text := symbol
+			if keyword, ok := keywords[symbol]; ok {
+				text = keyword
+			}
+			tokens = append(tokens, token{text, i})
+			i += len(symbol)
+			continue
+		}
+		j := i
+		for j < len(s) && !strings.ContainsAny(s[j:j+1], " \t()[]!&|<>=") && !strings.HasPrefix(s[j:], "->") {
+			j++
+		}
+		if j == i {
+			j++
+		}
+		tokens = append(tokens, token{s[i:j], i})
+		i = j
+	}
+	return tokens
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	length int
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos].text
+}
+
+func (p *parser) errorf(s string, ss ...any) error {
+	column := p.length + 1
+	if p.pos < len(p.tokens) {
+		column = p.tokens[p.pos].offset + 1
+	}
+	return &SyntaxError{column, fmt.Sprintf(s, ss...)}
+}
+
+func (p *parser) expect(text string) error {
+	if p.peek() != text {
+		return p.errorf("expected %q", text)
+	}
+	p.pos++
+	return nil
+}
+
+// Parse parses a PCTL formula like "P>=0.9 [F p]". Operators from weakest to strongest binding are IMPLIES (->),
+// OR (|), AND (&) and NOT (!). The probabilistic operator P compares with <, <=, > or >= to a probability, or asks
+// for it with =?, and takes one of the path formulas X a, F a, G a and a U b in brackets, where F, G and U may be
+// bounded like F<=5 a. Pmax and Pmin do the same with the maximal and minimal probability over the schedulers of a
+// Markov decision process. The reward operator R compares or asks for the expected reward of F a or C<=k.
+func Parse(s string) (*Formula, error) {
+	p := &parser{tokenize(s), 0, len(s)}
+	f, err := p.parseImplies()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.errorf("unexpected %s", p.peek())
+	}
+	return f, nil
+}
+
+func (p *parser) parseImplies() (*Formula, error) {
+	left, err := p.parseBinary(OpOr, "OR", p.parseAnd)
+	if err != nil || p.peek() != "IMPLIES" {
+		return left, err
+	}
+	p.pos++
+	right, err := p.parseImplies()
+	if err != nil {
+		return nil, err
+	}
+	return MakeFormula(OpImplies, left, right), nil
+}
+
+func (p *parser) parseAnd() (*Formula, error) {
+	return p.parseBinary(OpAnd, "AND", p.parseUnary)
+}
+
+// parseBinary parses a left associative chain of the given operator
+func (p *parser) parseBinary(op Op, keyword string, operand func() (*Formula, error)) (*Formula, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == keyword {
+		p.pos++
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = MakeFormula(op, left, right)
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (*Formula, error) {
+	text := p.peek()
+	switch text {
+	case "":
+		return nil, p.errorf("missing formula")
+	case "NOT":
+		p.pos++
+		sub, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return MakeFormula(OpNot, sub), nil
+	case "P", "Pmax", "Pmin":
+		return p.parseProb()
+	case "R":
+		return p.parseReward()
+	case "(":
+		p.pos++
+		f, err := p.parseImplies()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+	if !isName(text) {
+		return nil, p.errorf("unexpected %s", text)
+	}
+
+	p.pos++
+	switch text {
+	case "true":
+		return MakeFormula(OpTrue), nil
+	case "false":
+		return MakeFormula(OpFalse), nil
+	}
+	return MakeLabel(text), nil
+}
+
+var comparisons = map[string]bool{"<": true, "<=": true, ">": true, ">=": true, Query: true}
+
+// parseComparison parses the comparison following P or R up to the opening bracket of the path formula
+func (p *parser) parseComparison() (string, float64, error) {
+	operator := p.peek()
+	p.pos++
+	comparison := p.peek()
+	if !comparisons[comparison] {
+		return "", 0, p.errorf("expected \"<\", \"<=\", \">\", \">=\" or \"=?\" after %s", operator)
+	}
+	p.pos++
+	bound := 0.0
+	if comparison != Query {
+		var err error
+		bound, err = strconv.ParseFloat(p.peek(), 64)
+		if operator != "R" && (err != nil || bound < 0 || bound > 1) {
+			return "", 0, p.errorf("invalid probability: %s", p.peek())
+		} else if err != nil || bound < 0 || math.IsInf(bound, 0) {
+			return "", 0, p.errorf("invalid reward: %s", p.peek())
+		}
+		p.pos++
+	}
+	if err := p.expect("["); err != nil {
+		return "", 0, err
+	}
+	return comparison, bound, nil
+}
+
+func (p *parser) parseProb() (*Formula, error) {
+	optimum := strings.TrimPrefix(p.peek(), "P")
+	comparison, bound, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+
+	var f *Formula
+	switch p.peek() {
+	case "X":
+		p.pos++
+		sub, err := p.parseImplies()
+		if err != nil {
+			return nil, err
+		}
+		f = MakeProb(comparison, bound, PathX, -1, sub)
+	case "F", "G":
+		path := map[string]PathOp{"F": PathF, "G": PathG}[p.peek()]
+		p.pos++
+		steps, err := p.parseSteps()
+		if err != nil {
+			return nil, err
+		}
+		sub, err := p.parseImplies()
+		if err != nil {
+			return nil, err
+		}
+		f = MakeProb(comparison, bound, path, steps, sub)
+	default:
+		left, err := p.parseImplies()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("U"); err != nil {
+			return nil, err
+		}
+		steps, err := p.parseSteps()
+		if err != nil {
+			return nil, err
+		}
+		right, err := p.parseImplies()
+		if err != nil {
+			return nil, err
+		}
+		f = MakeProb(comparison, bound, PathU, steps, left, right)
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	f.Optimum = optimum
+	return f, nil
+}
+
+func (p *parser) parseReward() (*Formula, error) {
+	comparison, bound, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+
+	var f *Formula
+	switch p.peek() {
+	case "F":
+		p.pos++
+		sub, err := p.parseImplies()
+		if err != nil {
+			return nil, err
+		}
+		f = MakeReward(comparison, bound, PathF, -1, sub)
+	case "C":
+		p.pos++
+		if p.peek() != "<=" {
+			return nil, p.errorf("expected \"<=\" after C")
+		}
+		steps, err := p.parseSteps()
+		if err != nil {
+			return nil, err
+		}
+		f = MakeReward(comparison, bound, PathC, steps)
+	default:
+		return nil, p.errorf("expected \"F\" or \"C\"")
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// parseSteps parses an optional bound like "<=5" on the steps of a path formula, returning -1 if there is none
+func (p *parser) parseSteps() (int, error) {
+	if p.peek() != "<=" {
+		return -1, nil
+	}
+	p.pos++
+	steps, err := strconv.Atoi(p.peek())
+	if err != nil || steps < 0 {
+		return 0, p.errorf("invalid number of steps: %s", p.peek())
+	}
+	p.pos++
+	return steps, nil
+}
+
+func isName(s string) bool {
+	switch s {
+	case "", "AND", "OR", "IMPLIES", "NOT", "P", "Pmax", "Pmin", "R", "X", "F", "G", "U", "C":
+		return false
+	}
+	return !strings.ContainsAny(s, "()[]!&|<>=")
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"cav/golang/parser"
+	"fmt"
+)
+
+// regression evaluates the expected results annotated in the given files and reports every difference
+func regression(files []string) int {
+	if len(files) <= 0 {
+		fmt.Println("Usage: main test <file>...")
+		return 2
+	}
+
+	failed := 0
+	total := 0
+	for _, file := range files {
+		p := parser.MakeFileParser()
+		_, _, err := p.ParseFile(file)
+		if err != nil {
+			fmt.Printf("%s: failed to parse file:\n%s\n", file, err)
+			total++
+			failed++
+			continue
+		}
+
+		for _, expectation := range p.GetExpectations() {
+			total++
+			location := file
+			if expectation.File != "" {
+				location = expectation.File
+			}
+			if ok, diff := expectation.Verify(); ok {
+				fmt.Printf("PASS %s:%d: %s\n", location, expectation.Line, expectation.Formula.String())
+			} else {
+				failed++
+				fmt.Printf("FAIL %s:%d: %s\n  %s\n", location, expectation.Line, expectation.Formula.String(), diff)
+			}
+		}
+	}
+
+	fmt.Printf("%d of %d expectations passed\n", total-failed, total)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
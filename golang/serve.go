@@ -0,0 +1,24 @@
+package main
+
+import (
+	"cav/golang/server"
+	"flag"
+	"fmt"
+	"net/http"
+)
+
+func serve(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	cacheSize := flags.Int("cache", 64, "maximum number of cached models")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	fmt.Println("Listening on " + *addr)
+	if err := http.ListenAndServe(*addr, server.MakeServer(*cacheSize)); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,367 @@
+package server
+
+import (
+	"cav/golang/ast"
+	"cav/golang/explain"
+	"cav/golang/ltl"
+	"cav/golang/parser"
+	"cav/golang/types"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// JSONModel is the JSON representation of a Kripke structure
+type JSONModel struct {
+	States      []string            `json:"states"`
+	Initial     []string            `json:"initial,omitempty"`
+	Transitions [][2]string         `json:"transitions"`
+	Labels      map[string][]string `json:"labels"`
+	Formulas    []string            `json:"formulas,omitempty"`
+}
+
+type CheckRequest struct {
+	Format   string          `json:"format,omitempty"`
+	Model    json.RawMessage `json:"model,omitempty"`
+	ModelID  string          `json:"model_id,omitempty"`
+	Formulas []string        `json:"formulas,omitempty"`
+}
+
+type FormulaResult struct {
+	Formula    string   `json:"formula"`
+	Satisfying []string `json:"satisfying"`
+	Violating  []string `json:"violating"`
+	Holds      bool     `json:"holds"`
+	Trace      *Trace   `json:"trace,omitempty"`
+}
+
+// Trace explains why a formula is violated in State, the first violating initial state: by the path of an LTL
+// counterexample, Cycle being repeated forever, or by the proof tree of a CTL formula
+type Trace struct {
+	State  string         `json:"state"`
+	Prefix []string       `json:"prefix,omitempty"`
+	Cycle  []string       `json:"cycle,omitempty"`
+	Proof  *explain.Proof `json:"proof,omitempty"`
+}
+
+type CheckResponse struct {
+	ModelID string          `json:"model_id"`
+	Results []FormulaResult `json:"results"`
+}
+
+type ModelResponse struct {
+	ModelID  string   `json:"model_id"`
+	States   []string `json:"states"`
+	Initial  []string `json:"initial"`
+	Formulas []string `json:"formulas"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// model keeps the syntax trees of the CTL formulas for explaining them, nil for the other formulas
+type model struct {
+	ks       cav.IKripkeStructure
+	formulas []cav.IFormula
+	nodes    []*ast.Node
+}
+
+// Server answers model checking requests over HTTP and caches parsed models by content hash
+type Server struct {
+	mutex     sync.Mutex
+	cacheSize int
+	cache     map[string]*model
+	order     []string
+	mux       *http.ServeMux
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	var request CheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %s", err.Error()))
+		return
+	}
+
+	id, m, err := s.resolve(&request)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	response := ModelResponse{
+		ModelID:  id,
+		States:   cav.SortedNames(m.ks.GetStates()),
+		Initial:  cav.SortedNames(m.ks.GetInitialStates()),
+		Formulas: make([]string, 0, len(m.formulas)),
+	}
+	for _, formula := range m.formulas {
+		response.Formulas = append(response.Formulas, formula.String())
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleCheck(w http.ResponseWriter, r *http.Request) {
+	var request CheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %s", err.Error()))
+		return
+	}
+
+	id, m, err := s.resolve(&request)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	formulas := make([]cav.IFormula, 0, len(m.formulas)+len(request.Formulas))
+	formulas = append(formulas, m.formulas...)
+	nodes := append(make([]*ast.Node, 0, cap(formulas)), m.nodes...)
+	for _, s := range request.Formulas {
+		formula, node, err := parser.ParseFormulaNode(m.ks, s)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid formula %q: %s", s, err.Error()))
+			return
+		}
+		formulas = append(formulas, formula)
+		nodes = append(nodes, node)
+	}
+
+	response := CheckResponse{
+		ModelID: id,
+		Results: make([]FormulaResult, 0, len(formulas)),
+	}
+	for i, formula := range formulas {
+		satisfying := formula.Check()
+		violating := m.ks.GetInitialStates().Minus(satisfying)
+		response.Results = append(response.Results, FormulaResult{
+			Formula:    formula.String(),
+			Satisfying: cav.SortedNames(satisfying),
+			Violating:  cav.SortedNames(violating),
+			Holds:      violating.Size() <= 0,
+			Trace:      makeTrace(formula, nodes[i], violating),
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// makeTrace explains the violation in the first violating initial state, nil if the formula holds or cannot be
+// explained
+func makeTrace(formula cav.IFormula, node *ast.Node, violating cav.ISet[cav.IState]) *Trace {
+	if violating.Size() <= 0 {
+		return nil
+	}
+	if f, ok := formula.(*ltl.LTLFormula); ok {
+		lasso := f.Counterexample()
+		if lasso == nil {
+			return nil
+		}
+		path := append(names(lasso.Prefix), names(lasso.Cycle)...)
+		return &Trace{State: path[0], Prefix: names(lasso.Prefix), Cycle: names(lasso.Cycle)}
+	}
+	if node == nil {
+		return nil
+	}
+	var state cav.IState
+	first := cav.SortedNames(violating)[0]
+	violating.ForEach(func(s cav.IState) {
+		if s.GetName() == first {
+			state = s
+		}
+	})
+	proof, err := explain.Explain(node, state)
+	if err != nil {
+		return nil
+	}
+	return &Trace{State: state.GetName(), Proof: proof}
+}
+
+func names(states []cav.IState) []string {
+	result := make([]string, len(states))
+	for i, state := range states {
+		result[i] = state.GetName()
+	}
+	return result
+}
+
+// resolve looks up the model referenced by the request, parsing and caching it if necessary
+func (s *Server) resolve(request *CheckRequest) (string, *model, error) {
+	if len(request.Model) <= 0 {
+		if request.ModelID == "" {
+			return "", nil, fmt.Errorf("either model or model_id is required")
+		}
+		s.mutex.Lock()
+		m, ok := s.cache[request.ModelID]
+		s.mutex.Unlock()
+		if !ok {
+			return "", nil, fmt.Errorf("unknown model_id: %s", request.ModelID)
+		}
+		return request.ModelID, m, nil
+	}
+
+	// the text format is the default, so both spellings have to result in the same id
+	format := request.Format
+	if format == "" {
+		format = FormatText
+	}
+
+	var content []byte
+	switch format {
+	case FormatText:
+		var text string
+		if err := json.Unmarshal(request.Model, &text); err != nil {
+			return "", nil, fmt.Errorf("text model must be a JSON string: %s", err.Error())
+		}
+		content = []byte(text)
+	case FormatJSON:
+		var jsonModel JSONModel
+		if err := json.Unmarshal(request.Model, &jsonModel); err != nil {
+			return "", nil, fmt.Errorf("invalid JSON model: %s", err.Error())
+		}
+		// re-encode so that formatting differences do not affect the hash
+		content, _ = json.Marshal(jsonModel)
+	default:
+		return "", nil, fmt.Errorf("unknown model format: %s", request.Format)
+	}
+
+	sum := sha256.Sum256(append([]byte(format+"\n"), content...))
+	id := hex.EncodeToString(sum[:])
+
+	s.mutex.Lock()
+	m, ok := s.cache[id]
+	s.mutex.Unlock()
+	if ok {
+		return id, m, nil
+	}
+
+	var err error
+	if format == FormatJSON {
+		m, err = parseJSONModel(content)
+	} else {
+		m, err = parseTextModel(content)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	s.mutex.Lock()
+	if _, ok := s.cache[id]; !ok {
+		s.cache[id] = m
+		s.order = append(s.order, id)
+		for len(s.order) > s.cacheSize {
+			delete(s.cache, s.order[0])
+			s.order = s.order[1:]
+		}
+	}
+	s.mutex.Unlock()
+	return id, m, nil
+}
+
+func parseTextModel(content []byte) (*model, error) {
+	p := parser.MakeFileParser()
+	ks, formulas, err := p.ParseString(string(content))
+	if err != nil {
+		return nil, err
+	}
+	return &model{ks, formulas, p.GetNodes()}, nil
+}
+
+func parseJSONModel(content []byte) (*model, error) {
+	var jsonModel JSONModel
+	if err := json.Unmarshal(content, &jsonModel); err != nil {
+		return nil, err
+	}
+
+	ks := cav.MakeKripkeStructure()
+	states := map[string]cav.IState{}
+	for _, name := range jsonModel.States {
+		if _, ok := states[name]; ok {
+			return nil, fmt.Errorf("duplicate state: %s", name)
+		}
+		states[name] = ks.NewState(name)
+	}
+
+	lookup := func(name string) (cav.IState, error) {
+		state, ok := states[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown state: %s", name)
+		}
+		return state, nil
+	}
+
+	for _, name := range jsonModel.Initial {
+		state, err := lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		ks.AddInitialStates(state)
+	}
+
+	for _, transition := range jsonModel.Transitions {
+		from, err := lookup(transition[0])
+		if err != nil {
+			return nil, err
+		}
+		to, err := lookup(transition[1])
+		if err != nil {
+			return nil, err
+		}
+		from.AddChildren(to)
+	}
+
+	for labelName, stateNames := range jsonModel.Labels {
+		label := ks.NewLabel(labelName)
+		for _, name := range stateNames {
+			state, err := lookup(name)
+			if err != nil {
+				return nil, err
+			}
+			state.AddLabel(label)
+		}
+	}
+
+	m := &model{ks, make([]cav.IFormula, 0, len(jsonModel.Formulas)), make([]*ast.Node, 0, len(jsonModel.Formulas))}
+	for _, s := range jsonModel.Formulas {
+		formula, node, err := parser.ParseFormulaNode(ks, s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid formula %q: %s", s, err.Error())
+		}
+		m.formulas = append(m.formulas, formula)
+		m.nodes = append(m.nodes, node)
+	}
+	return m, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{err.Error()})
+}
+
+func MakeServer(cacheSize int) *Server {
+	s := &Server{
+		cacheSize: cacheSize,
+		cache:     map[string]*model{},
+		order:     make([]string, 0),
+		mux:       http.NewServeMux(),
+	}
+	s.mux.HandleFunc("POST /models", s.handleModels)
+	s.mux.HandleFunc("POST /check", s.handleCheck)
+	return s
+}
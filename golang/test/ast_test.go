@@ -0,0 +1,91 @@
+package test
+
+import (
+	"cav/golang/ast"
+	"cav/golang/parser"
+	"cav/golang/types"
+	"testing"
+)
+
+func TestAST(t *testing.T) {
+	node, err := parser.ParseNode("  EX (p AND E[q U r])")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Kind != ast.EX || node.Operands[0].Kind != ast.And || node.Operands[0].Operands[1].Kind != ast.EU {
+		t.Fatalf("unexpected tree: %s", node.String())
+	}
+	if node.String() != "EX (p AND E[q U r])" {
+		t.Errorf("unexpected string form: %s", node.String())
+	}
+
+	positions := map[string]ast.Position{}
+	ast.Inspect(node, func(n *ast.Node) bool {
+		if n.Kind == ast.Label {
+			positions[n.Name] = n.Pos
+		}
+		return true
+	})
+	if positions["p"] != (ast.Position{Line: 1, Column: 7}) || positions["r"] != (ast.Position{Line: 1, Column: 19}) {
+		t.Errorf("unexpected positions: %v", positions)
+	}
+
+	// the same tree can be checked against different models
+	ks1, _, _ := parser.ParseString("states\ns1\ns2\ntransitions\ns1 -> s2 -> s2\nlabels\np: s2\nq:\nr: s2\nformulas\n")
+	ks2, _, _ := parser.ParseString("states\nt1\ntransitions\nt1 -> t1\nlabels\np: t1\nq: t1\nr:\nformulas\n")
+	fla1, err := ast.Bind(node, ks1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fla2, err := ast.Bind(node, ks2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testFormula(t, fla1, ks1.GetStates())
+	testFormula(t, fla2, cav.MakeSet[cav.IState]())
+
+	// swap every AND for an OR without touching the original
+	swapped := ast.Rewrite(node, ast.RewriterFunc(func(n *ast.Node) *ast.Node {
+		if n.Kind == ast.And {
+			n.Kind = ast.Or
+		}
+		return n
+	}))
+	if swapped.String() != "EX (p OR E[q U r])" || node.String() != "EX (p AND E[q U r])" {
+		t.Errorf("unexpected rewrite: %s, original: %s", swapped.String(), node.String())
+	}
+	fla2, _ = ast.Bind(swapped, ks2)
+	testFormula(t, fla2, ks2.GetStates())
+
+	again, err := parser.ParseNode(swapped.String())
+	if err != nil || !again.Equal(swapped) {
+		t.Errorf("string form does not parse to the same tree: %v", err)
+	}
+
+	if _, err := ast.Bind(ast.MakeNode(ast.EX, ast.MakeLabel("x")), ks1); err == nil {
+		t.Errorf("expected an error for an unknown label")
+	}
+}
+
+func TestASTFromFile(t *testing.T) {
+	p := parser.MakeFileParser()
+	_, flas, err := p.ParseFile("../../kripkestructure_test.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodes := p.GetNodes()
+	if len(nodes) != len(flas) {
+		t.Fatalf("expected %d nodes, got %d", len(flas), len(nodes))
+	}
+	for i, node := range nodes {
+		fla, err := ast.Bind(node, flas[i].GetKripkeStructure())
+		if err != nil {
+			t.Fatal(err)
+		}
+		testFormula(t, fla, flas[i].Check())
+	}
+	if nodes[0].Pos.Line != 48 {
+		t.Errorf("expected the first formula in line 48, got %s", nodes[0].Pos.String())
+	}
+}
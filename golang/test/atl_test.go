@@ -0,0 +1,202 @@
+package test
+
+import (
+	"cav/golang/atl"
+	"cav/golang/parser"
+	"cav/golang/types"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+const gameModel = "states\ns0\ns1\ns2\ns3\nagents\nclient\nserver\ntransitions\n" +
+	"s0 -<req,ack>-> s1\ns0 -<req,nack>-> s0\ns0 -<wait,ack>-> s0\ns0 -<wait,nack>-> s0\n" +
+	"s1 -<req,ack>-> s2\ns1 -<req,nack>-> s3\ns1 -<wait,ack>-> s2\ns1 -<wait,nack>-> s3\n" +
+	"s2 -<wait,idle>-> s2\ns3 <-<wait,idle>- s3\nlabels\nsent: s1\ndone: s2\nerr: s3\nformulas\n"
+
+func TestATL(t *testing.T) {
+	ks, formulas, err := parser.ParseString(gameModel +
+		"<<server>> F done\n<<client>> F done\n<<client, server>> [NOT err U done]\n<<>> X NOT sent\n" +
+		"<<server>> G NOT err\nATL <<client>> G !err\n<<server>> X sent | done\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []string{"{s1, s2}", "{s2}", "{s0, s1, s2}", "{s1, s2, s3}", "{s0, s1, s2}", "{s0, s2}", "{s2}"} {
+		if actual := formulas[i].Check().String(); actual != expected {
+			t.Errorf("%s: expected %s, got %s", formulas[i].String(), expected, actual)
+		}
+	}
+
+	for i, expected := range []string{
+		"[server] map[s1:[ack]]",
+		"[client] map[]",
+		"[client server] map[s0:[req ack] s1:[req ack]]",
+		"[] map[s1:[] s2:[] s3:[]]",
+		"[server] map[s0:[ack] s1:[ack] s2:[idle]]",
+		"[client] map[s0:[wait] s2:[wait]]",
+	} {
+		strategy := formulas[i].(*atl.ATLFormula).Strategy()
+		actions := map[string][]string{}
+		for state, choice := range strategy.Actions {
+			actions[state.GetName()] = choice
+		}
+		if actual := fmt.Sprint(strategy.Agents, " ", actions); actual != expected {
+			t.Errorf("%s: expected strategy %s, got %s", formulas[i].String(), expected, actual)
+		}
+	}
+	if strategy := formulas[6].(*atl.ATLFormula).Strategy(); strategy != nil {
+		t.Errorf("%s: expected no strategy, got %v", formulas[6].String(), strategy)
+	}
+
+	var sb strings.Builder
+	if err := parser.Write(&sb, ks); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "\nagents\nclient\nserver\n\ntransitions\n") || !strings.Contains(sb.String(), "s3 -<wait,idle>-> s3\n") {
+		t.Errorf("expected the agents and moves to be written:\n%s", sb.String())
+	}
+
+	for text, expected := range map[string]string{
+		"<<a,b>>X p":                  "<<a, b>> X p",
+		"<<>> [p U <<a>> G q] -> !p":  "(<<>> [p U <<a>> G q] IMPLIES NOT p)",
+		"<<a>> F p & <<b>> X (p | q)": "(<<a>> F p AND <<b>> X (p OR q))",
+	} {
+		if f, err := atl.Parse(text); err != nil {
+			t.Errorf("%s: %s", text, err.Error())
+		} else if f.String() != expected {
+			t.Errorf("%s: expected %s, got %s", text, expected, f.String())
+		}
+	}
+
+	for model, expected := range map[string]string{
+		gameModel + "<<client server>> F done\n":                                   "25:10: expected \",\" or \">>\"",
+		gameModel + "<<client>> done\n":                                            "25:12: expected \"X\", \"G\", \"F\" or \"[\" after the coalition",
+		gameModel + "<<user>> F done\n":                                            "25: unknown agent in formula: user",
+		gameModel + "<<client, client>> F done\n":                                  "25: duplicate agent in coalition: client",
+		markovModel + "<<a>> F goal\n":                                             "15: ATL formulas need transitions with moves of agents",
+		strings.Replace(gameModel, "s1 -<req,nack>-> s3\n", "", 1):                 "19: invalid concurrent game structure: move <req,nack> is missing in s1",
+		strings.Replace(gameModel, "s0 -<req,nack>-> s0", "s0 -<req>-> s0", 1):     "11: move <req> needs an action for each of the 2 agents",
+		strings.Replace(gameModel, "s0 -<req,nack>-> s0", "s0 -<req,ack>-> s0", 1): "11: move <req,ack> from s0 leads to both s1 and s0",
+		strings.Replace(gameModel, "s2 -<wait,idle>-> s2", "s2 -> s2", 1):          "20: invalid concurrent game structure: state s2 has no move",
+	} {
+		if _, _, err := parser.ParseString(model); err == nil || err.Error() != expected {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	}
+}
+
+// randomGameStructure builds a concurrent game structure of two agents with up to two actions each per state
+func randomGameStructure(t *testing.T, r *rand.Rand) (cav.IKripkeStructure, []string) {
+	ks := cav.MakeKripkeStructure()
+	ks.SetAgents("a1", "a2")
+	names := []string{"p0", "p1", "p2"}
+	labels := make([]cav.ILabel, len(names))
+	for i, name := range names {
+		labels[i] = ks.NewLabel(name)
+	}
+	states := make([]cav.IState, 6)
+	for i := range states {
+		states[i] = ks.NewState(fmt.Sprintf("s%d", i))
+		for _, label := range labels {
+			if r.Intn(2) == 0 {
+				states[i].AddLabel(label)
+			}
+		}
+	}
+	for _, state := range states {
+		actions1, actions2 := 1+r.Intn(2), 1+r.Intn(2)
+		for i := 0; i < actions1; i++ {
+			for j := 0; j < actions2; j++ {
+				state.AddMove([]string{fmt.Sprintf("x%d", i), fmt.Sprintf("y%d", j)}, states[r.Intn(len(states))])
+			}
+		}
+	}
+	if err := cav.CheckGameStructure(ks); err != nil {
+		t.Fatal(err)
+	}
+	return ks, names
+}
+
+// follows reports whether the move is consistent with the actions the strategy picks in the state
+func follows(strategy *cav.Strategy, state cav.IState, move []string) bool {
+	for i, agent := range strategy.Agents {
+		if move[map[string]int{"a1": 0, "a2": 1}[agent]] != strategy.Actions[state][i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestATLAgainstCTL compares the coalitions of no and of all agents to the universal and existential CTL operators,
+// and checks that the strategies win against every choice of the other agents
+func TestATLAgainstCTL(t *testing.T) {
+	r := rand.New(rand.NewSource(31))
+	for seed := 0; seed < 30; seed++ {
+		ks, names := randomGameStructure(t, r)
+
+		for i := 0; i < 5; i++ {
+			a, b := names[r.Intn(len(names))], names[r.Intn(len(names))]
+			for game, ctl := range map[string]string{
+				"<<a1, a2>> X " + a: "EX " + a,
+				"<<>> X " + a:       "AX " + a,
+				fmt.Sprintf("<<a2, a1>> [%s U %s]", a, b): fmt.Sprintf("E[%s U %s]", a, b),
+				fmt.Sprintf("<<>> [%s U %s]", a, b):       fmt.Sprintf("A[%s U %s]", a, b),
+				"<<a1, a2>> G " + a:                       "EG " + a,
+				"<<>> G " + a:                             "AG " + a,
+				"<<>> F " + a:                             "AF " + a,
+			} {
+				actual, err := parser.ParseFormula(ks, game)
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected, err := parser.ParseFormula(ks, ctl)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !actual.Check().Equals(expected.Check()) {
+					t.Errorf("seed %d: %s gives %s, but %s gives %s", seed, game, actual.Check().String(), ctl, expected.Check().String())
+				}
+			}
+
+			for _, coalition := range []string{"a1", "a2"} {
+				global, _ := parser.ParseFormula(ks, fmt.Sprintf("<<%s>> G %s", coalition, a))
+				until, _ := parser.ParseFormula(ks, fmt.Sprintf("<<%s>> [%s U %s]", coalition, a, b))
+				target, _ := parser.ParseFormula(ks, b)
+
+				// following the strategy of G stays among the winning states
+				winning, strategy := global.Check(), global.(*atl.ATLFormula).Strategy()
+				winning.ForEach(func(state cav.IState) {
+					for _, move := range state.GetMoves() {
+						if follows(strategy, state, move) && !winning.Contains(state.GetSuccessor(move)) {
+							t.Errorf("seed %d: the strategy of %s leaves the winning states in %s", seed, global.String(), state.GetName())
+						}
+					}
+				})
+
+				// following the strategy of U reaches b from every winning state within as many steps as there are states
+				strategy = until.(*atl.ATLFormula).Strategy()
+				reached := target.Check()
+				for step := 0; step < 6; step++ {
+					next := target.Check()
+					ks.GetStates().ForEach(func(state cav.IState) {
+						if _, ok := strategy.Actions[state]; !ok {
+							return
+						}
+						forced := true
+						for _, move := range state.GetMoves() {
+							forced = forced && (!follows(strategy, state, move) || reached.Contains(state.GetSuccessor(move)))
+						}
+						if forced {
+							next.Add(state)
+						}
+					})
+					reached = next
+				}
+				if !reached.Equals(until.Check()) {
+					t.Errorf("seed %d: the strategy of %s wins in %s instead of %s", seed, until.String(), reached.String(), until.Check().String())
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package test
+
+import (
+	"cav/golang/ast"
+	"cav/golang/explain"
+	"cav/golang/generator"
+	"cav/golang/parser"
+	"cav/golang/transform"
+	"cav/golang/types"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+const boundedModel = "states\ns1\ns2\ns3\ns4\ntransitions\ns1 -> s2 -> s3 -> s4\ns1 -> s4\nlabels\np: s1, s2, s3\nq: s4\nformulas\n"
+
+func TestBounded(t *testing.T) {
+	ks, formulas, err := parser.ParseString(boundedModel +
+		"EF<=1 q\nAF<=1 q\nAF[2,3] q\nE[p U<=2 q]\nE[p U[2,3] q]\nAG<=1 p\nEG[1,2] p\nA[p U <= 3 q]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []string{"{s1, s3, s4}", "{s3, s4}", "{s1, s2, s3, s4}", "{s1, s2, s3, s4}", "{s1, s2}", "{s2}", "{s1}", "{s1, s2, s3, s4}"} {
+		if actual := formulas[i].Check().String(); actual != expected {
+			t.Errorf("%s: expected %s, got %s", formulas[i].String(), expected, actual)
+		}
+	}
+
+	for text, expected := range map[string]string{
+		"EF<=1 q":         "EF<=1 q",
+		"AG [2, 5] (p)":   "AG[2,5] p",
+		"E[p U[0,3] q]":   "E[p U<=3 q]",
+		"NOT A[p U<=0 q]": "NOT A[p U<=0 q]",
+		"EF [p AND q]":    "EF (p AND q)",
+	} {
+		node, err := parser.ParseNode(text)
+		if err != nil {
+			t.Errorf("%s: %s", text, err.Error())
+		} else if node.String() != expected {
+			t.Errorf("%s: expected %s, got %s", text, expected, node.String())
+		}
+	}
+	if _, err := parser.ParseNode("AF[3,2] q"); err == nil || err.Error() != "1: invalid bound: [3,2]" {
+		t.Errorf("expected the bound to be rejected, got %v", err)
+	}
+
+	q, _ := parser.ParseFormula(ks, "q")
+	distances := map[string]int{}
+	for state, distance := range cav.Distances(q) {
+		distances[state.GetName()] = distance
+	}
+	if fmt.Sprint(distances) != "map[s1:1 s2:2 s3:1 s4:0]" {
+		t.Errorf("unexpected distances %v", distances)
+	}
+}
+
+// TestBoundedAgainstUnbounded uses that within as many steps as there are states every state reachable at all
+// is reached, and compares the distances to the bounded reachability
+func TestBoundedAgainstUnbounded(t *testing.T) {
+	r := rand.New(rand.NewSource(13))
+	for seed := int64(0); seed < 30; seed++ {
+		o := generator.DefaultOptions()
+		o.States = 6
+		o.MinDegree = int(seed % 2)
+		o.MaxDegree = 2
+		o.Seed = seed
+		ks, err := generator.RandomKripkeStructure(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := o.LabelNames()
+		n := o.States
+
+		for i := 0; i < 5; i++ {
+			a, b := names[r.Intn(len(names))], names[r.Intn(len(names))]
+			for bounded, unbounded := range map[string]string{
+				fmt.Sprintf("EF<=%d %s", n, a):         "EF " + a,
+				fmt.Sprintf("AF<=%d %s", n, a):         "AF " + a,
+				fmt.Sprintf("EG<=%d %s", n, a):         "EG " + a,
+				fmt.Sprintf("AG<=%d %s", n, a):         "AG " + a,
+				fmt.Sprintf("E[%s U<=%d %s]", a, n, b): fmt.Sprintf("E[%s U %s]", a, b),
+				fmt.Sprintf("A[%s U<=%d %s]", a, n, b): fmt.Sprintf("A[%s U %s]", a, b),
+			} {
+				actual, err := parser.ParseFormula(ks, bounded)
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected, _ := parser.ParseFormula(ks, unbounded)
+				if !actual.Check().Equals(expected.Check()) {
+					t.Errorf("seed %d: %s gives %s, but %s gives %s", seed, bounded, actual.Check().String(), unbounded, expected.Check().String())
+				}
+			}
+
+			target, _ := parser.ParseFormula(ks, a)
+			distances := cav.Distances(target)
+			for k := 0; k <= n; k++ {
+				reachable, _ := parser.ParseFormula(ks, fmt.Sprintf("EF<=%d %s", k, a))
+				ks.GetStates().ForEach(func(state cav.IState) {
+					distance, ok := distances[state]
+					if (ok && distance <= k) != reachable.Check().Contains(state) {
+						t.Errorf("seed %d: distance %d of %s to %s contradicts %s", seed, distance, state.GetName(), a, reachable.String())
+					}
+				})
+			}
+		}
+
+		for i := 0; i < 10; i++ {
+			text := generator.RandomFormula(r, 1+r.Intn(3), names)
+			lower := r.Intn(3)
+			bound := fmt.Sprintf("[%d,%d]", lower, lower+r.Intn(3))
+			text = []string{"EF", "AF", "EG", "AG"}[r.Intn(4)] + bound + " (" + text + ")"
+			if r.Intn(2) == 0 {
+				text = fmt.Sprintf("NOT %c[%s U%s %s]", "EA"[r.Intn(2)], names[0], bound, text)
+			}
+			node, err := parser.ParseNode(text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fla, _ := ast.Bind(node, ks)
+			for name, transformation := range map[string]func(*ast.Node) *ast.Node{"NNF": transform.NNF, "ENF": transform.ENF, "Simplify": transform.Simplify} {
+				transformed, err := ast.Bind(transformation(node), ks)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !transformed.Check().Equals(fla.Check()) {
+					t.Errorf("seed %d: %s(%s) = %s is not equivalent", seed, name, text, transformed.String())
+				}
+			}
+		}
+	}
+}
+
+// TestHugeBounds checks bounds far beyond the number of states, which are only cheap because the iteration stops
+// at a fixpoint or once it repeats, against the step by step definition for bounds of up to 20 steps
+func TestHugeBounds(t *testing.T) {
+	texts := []string{"EF<=1000000000 q", "AF[999999999,1000000000] q", "E[true U[1000000000,1000000000] q]", "EG[999999999,999999999] NOT q"}
+	ks, formulas, err := parser.ParseString("states\ns1\ns2\ntransitions\ns1 -> s2 -> s1\nlabels\nq: s2\nformulas\n" +
+		strings.Join(texts, "\n") + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	for i, expected := range []string{"{s1, s2}", "{s1, s2}", "{s2}", "{s2}"} {
+		if actual := formulas[i].Check().String(); actual != expected {
+			t.Errorf("%s: expected %s, got %s", formulas[i].String(), expected, actual)
+		}
+		node, _ := parser.ParseNode(texts[i])
+		ks.GetStates().ForEach(func(state cav.IState) {
+			proof, err := explain.Explain(node, state)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if proof.Holds != formulas[i].Check().Contains(state) {
+				t.Errorf("%s: unexpected proof in %s: %s", texts[i], state.GetName(), proof.Evidence)
+			}
+		})
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("huge bounds took %s", elapsed)
+	}
+
+	r := rand.New(rand.NewSource(17))
+	for seed := int64(0); seed < 30; seed++ {
+		o := generator.DefaultOptions()
+		o.States = 5
+		o.MinDegree = int(seed % 2)
+		o.MaxDegree = 2
+		o.Seed = seed
+		ks, err := generator.RandomKripkeStructure(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := o.LabelNames()
+		for i := 0; i < 10; i++ {
+			a, b := names[r.Intn(len(names))], names[r.Intn(len(names))]
+			lower := r.Intn(16)
+			bound := cav.Bound{Lower: lower, Upper: lower + r.Intn(5)}
+			for _, op := range []string{"E", "A"} {
+				actual, err := parser.ParseFormula(ks, fmt.Sprintf("%s[%s U%s %s]", op, a, bound.String(), b))
+				if err != nil {
+					t.Fatal(err)
+				}
+				p, _ := parser.ParseFormula(ks, a)
+				q, _ := parser.ParseFormula(ks, b)
+				expected := q.Check()
+				for k := bound.Upper - 1; k >= 0; k-- {
+					next := cav.MakeSet[cav.IState]()
+					ks.GetStates().ForEach(func(state cav.IState) {
+						all, some := true, false
+						state.GetChildren().ForEach(func(child cav.IState) {
+							all = all && expected.Contains(child)
+							some = some || expected.Contains(child)
+						})
+						if (op == "E" && some) || (op == "A" && all) {
+							next.Add(state)
+						}
+					})
+					expected = p.Check().Intersect(next)
+					if k >= bound.Lower {
+						expected = expected.Union(q.Check())
+					}
+				}
+				if !actual.Check().Equals(expected) {
+					t.Errorf("seed %d: %s gives %s instead of %s", seed, actual.String(), actual.Check().String(), expected.String())
+				}
+			}
+		}
+	}
+}
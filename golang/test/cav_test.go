@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"testing"
 )
 
@@ -103,14 +102,14 @@ func TestParser(t *testing.T) {
 	// so we go up to project dir
 	wd, _ := os.Getwd()
 	path := ""
-	for !strings.HasSuffix(wd, "CAV") {
+	for _, err := os.Stat(filepath.Join(wd, "go.mod")); err != nil; _, err = os.Stat(filepath.Join(wd, "go.mod")) {
 		wd = filepath.Dir(wd)
 		path = path + "../"
 	}
 
 	t.Log("Working directory: " + wd)
 
-	ks, flas, err := cav2.PARSER.ParseFile(fmt.Sprintf("%s/kripkestructure_exam.txt", wd))
+	ks, flas, err := cav2.ParseFile(fmt.Sprintf("%s/kripkestructure_exam.txt", wd))
 	if err != nil {
 		t.Errorf("Failed to parse file:")
 		t.Error(err)
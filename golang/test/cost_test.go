@@ -0,0 +1,149 @@
+package test
+
+import (
+	"cav/golang/ast"
+	"cav/golang/explain"
+	"cav/golang/generator"
+	"cav/golang/parser"
+	"cav/golang/transform"
+	"cav/golang/types"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+const costModel = "states\ns1\ns2\ns3\ns4\ns5\ntransitions\ns1 -3-> s2 -> s4\ns1 -1-> s3 -5-> s4\ns4 <-0- s5\ns5 -> s5\nlabels\np: s1, s2, s3, s5\nq: s4\nformulas\n"
+
+func TestCost(t *testing.T) {
+	ks, formulas, err := parser.ParseString(costModel +
+		"EF[cost<=4] q\nAF[cost<=4] q\nAF[cost<=6] q\nEG[cost<=5] p\nAG[cost<=0] p\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []string{"{s1, s2, s4, s5}", "{s2, s4}", "{s1, s2, s3, s4}", "{s1, s5}", "{s1, s2, s3}"} {
+		if actual := formulas[i].Check().String(); actual != expected {
+			t.Errorf("%s: expected %s, got %s", formulas[i].String(), expected, actual)
+		}
+	}
+
+	q, _ := parser.ParseFormula(ks, "q")
+	costs := map[string]string{}
+	for state, cost := range cav.MinCosts(q) {
+		costs[state.GetName()] = fmt.Sprint(cost)
+	}
+	for state, cost := range cav.MaxCosts(q) {
+		costs[state.GetName()] += fmt.Sprintf("-%d", cost)
+	}
+	if fmt.Sprint(costs) != "map[s1:4-6 s2:1-1 s3:5-5 s4:0-0 s5:0]" {
+		t.Errorf("unexpected costs %v", costs)
+	}
+
+	var sb strings.Builder
+	if err := parser.Write(&sb, ks); err != nil {
+		t.Fatal(err)
+	}
+	written := sb.String()
+	for _, transition := range []string{"s1 -3-> s2\n", "s2 -> s4\n", "s5 -0-> s4\n"} {
+		if !strings.Contains(written, transition) {
+			t.Errorf("expected %q in\n%s", transition, written)
+		}
+	}
+
+	node, err := parser.ParseNode("NOT EF [cost <= 4] (q)")
+	if err != nil {
+		t.Fatal(err)
+	} else if node.String() != "NOT EF[cost<=4] q" || transform.NNF(node).String() != "AG[cost<=4] NOT q" {
+		t.Errorf("unexpected %s with negation normal form %s", node.String(), transform.NNF(node).String())
+	}
+	var s1 cav.IState
+	ks.GetStates().ForEach(func(state cav.IState) {
+		if state.GetName() == "s1" {
+			s1 = state
+		}
+	})
+	proof, _ := explain.Explain(node.Operands[0], s1)
+	if proof.Evidence != "the path s1 -3-> s2 -1-> s4 reaches q at a cost of 4" {
+		t.Errorf("unexpected evidence %q", proof.Evidence)
+	}
+
+	if _, _, err := parser.ParseString("states\ns1\ntransitions\ns1 --1-> s1\nlabels\n"); err == nil || !strings.Contains(err.Error(), "invalid transition") {
+		t.Errorf("expected the negative weight to be rejected, got %v", err)
+	}
+}
+
+// TestCostAgainstBounded compares costs to steps when all weights are 1, and with random weights
+// to the unbounded operators and the explanations
+func TestCostAgainstBounded(t *testing.T) {
+	r := rand.New(rand.NewSource(17))
+	for seed := int64(0); seed < 30; seed++ {
+		o := generator.DefaultOptions()
+		o.States = 6
+		o.MinDegree = 1
+		o.MaxDegree = 2
+		o.Seed = seed
+		ks, err := generator.RandomKripkeStructure(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := o.LabelNames()
+
+		for i := 0; i < 5; i++ {
+			a, k := names[r.Intn(len(names))], r.Intn(4)
+			for _, op := range []string{"EF", "AF", "EG", "AG"} {
+				cost, _ := parser.ParseFormula(ks, fmt.Sprintf("%s[cost<=%d] %s", op, k, a))
+				bounded, _ := parser.ParseFormula(ks, fmt.Sprintf("%s<=%d %s", op, k, a))
+				if !cost.Check().Equals(bounded.Check()) {
+					t.Errorf("seed %d: %s gives %s, but %s gives %s", seed, cost.String(), cost.Check().String(), bounded.String(), bounded.Check().String())
+				}
+			}
+		}
+
+		if seed%2 == 1 {
+			o.MinDegree = 0
+			if ks, err = generator.RandomKripkeStructure(o); err != nil {
+				t.Fatal(err)
+			}
+		}
+		ks.GetStates().ForEach(func(state cav.IState) {
+			state.GetChildren().ForEach(func(child cav.IState) {
+				state.AddWeightedChild(child, r.Intn(3))
+			})
+		})
+		for _, a := range names {
+			target, _ := parser.ParseFormula(ks, a)
+			minCosts, maxCosts := cav.MinCosts(target), cav.MaxCosts(target)
+			for unbounded, costs := range map[string]map[cav.IState]int{"EF " + a: minCosts, "AF " + a: maxCosts} {
+				expected, _ := parser.ParseFormula(ks, unbounded)
+				actual, _ := parser.ParseFormula(ks, fmt.Sprintf("%s[cost<=%d] %s", unbounded[:2], 2*o.States, a))
+				if !actual.Check().Equals(expected.Check()) {
+					t.Errorf("seed %d: %s gives %s, but %s gives %s", seed, actual.String(), actual.Check().String(), unbounded, expected.Check().String())
+				}
+				ks.GetStates().ForEach(func(state cav.IState) {
+					if _, ok := costs[state]; ok != expected.Check().Contains(state) {
+						t.Errorf("seed %d: cost of %s for %s contradicts %s", seed, state.GetName(), a, unbounded)
+					}
+				})
+			}
+			for state, cost := range maxCosts {
+				if minCost, ok := minCosts[state]; ok && minCost > cost {
+					t.Errorf("seed %d: minimal cost %d of %s to %s exceeds the maximal cost %d", seed, minCost, state.GetName(), a, cost)
+				}
+			}
+
+			for _, op := range []string{"EF", "AF", "EG"} {
+				node := ast.MakeBounded(map[string]ast.Kind{"EF": ast.CostEF, "AF": ast.CostAF, "EG": ast.CostEG}[op], cav.Bound{Upper: r.Intn(5)}, ast.MakeLabel(a))
+				formula, _ := ast.Bind(node, ks)
+				ks.GetStates().ForEach(func(state cav.IState) {
+					proof, err := explain.Explain(node, state)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if proof.Holds != formula.Check().Contains(state) || proof.Evidence == "" {
+						t.Errorf("seed %d: unexpected proof of %s in %s: %s", seed, node.String(), state.GetName(), proof.Evidence)
+					}
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package test
+
+import (
+	"cav/golang/coverage"
+	"cav/golang/parser"
+	"strings"
+	"testing"
+)
+
+func TestCoverage(t *testing.T) {
+	ks, formulas, err := parser.ParseString("states\ns1\ns2\ntransitions\ns1 -> s2 -> s2\nlabels\np: s2\nq: s1\nformulas\nEX p\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := coverage.Compute(ks, formulas)
+	if labels := c.GetLabels(); len(labels) != 2 || labels[0].String() != "p" || labels[1].String() != "q" {
+		t.Fatalf("unexpected labels: %v", labels)
+	}
+	p, q := c.GetLabels()[0], c.GetLabels()[1]
+	if covered := c.GetCovered(p).String(); covered != "{s2}" {
+		t.Errorf("expected p to be covered in s2, got %s", covered)
+	}
+	if uncovered := c.GetUncovered(q).String(); uncovered != "{s1, s2}" {
+		t.Errorf("expected q to be uncovered everywhere, got %s", uncovered)
+	}
+	if c.Ratio() != 0.25 {
+		t.Errorf("expected a coverage of 0.25, got %f", c.Ratio())
+	}
+	if p.MakeLabelFormula().Check().String() != "{s2}" || q.MakeLabelFormula().Check().String() != "{s1}" {
+		t.Errorf("the labels have not been restored:\n%s", ks.DetailString())
+	}
+
+	var dot strings.Builder
+	c.WriteDOT(&dot)
+	if !strings.Contains(dot.String(), "\"s2\" [label=\"s2\\ncovered: p\\nuncovered: q\", fillcolor=khaki];") {
+		t.Errorf("unexpected DOT output:\n%s", dot.String())
+	}
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"cav/golang/ctlstar"
+	"cav/golang/generator"
+	"cav/golang/parser"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestCTLStar(t *testing.T) {
+	ks, formulas, err := parser.ParseString("states\ns1\ns2\ns3\ntransitions\ns1 -> s2 -> s1\ns2 -> s3 -> s1\nlabels\np: s1\nq: s3\nformulas\n" +
+		"A(GF p -> GF q)\nE(GF p & G NOT q)\nCTL* A G E F q\nE[p U q]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []string{"{}", "{s1, s2}", "{s1, s2, s3}", "{s3}"} {
+		if actual := formulas[i].Check().String(); actual != expected {
+			t.Errorf("%s: expected %s, got %s", formulas[i].String(), expected, actual)
+		}
+	}
+	if _, ok := formulas[3].(*ctlstar.Formula); ok {
+		t.Errorf("%s should have been parsed as a CTL formula", formulas[3].String())
+	}
+	if formulas[0].String() != "CTL* A (G F p IMPLIES G F q)" {
+		t.Errorf("unexpected string %s", formulas[0].String())
+	}
+	if size := ks.GetLabels().Size(); size != 2 {
+		t.Errorf("expected no fresh labels in the Kripke structure, got %s", ks.GetLabels().String())
+	}
+
+	for text, expected := range map[string]string{
+		"CTL* G p":      "7: temporal operator outside of a path quantifier: G p",
+		"CTL* E (p U":   "7:12: missing formula",
+		"A (GF p -> r)": "7: unknown label in formula: r",
+		// CTL formulas are not silently read as CTL*, which differs in states without successors
+		"EF p & p": "7: invalid formula: p & p",
+	} {
+		if _, _, err := parser.ParseString("states\ns1\ntransitions\nlabels\np: s1\nformulas\n" + text + "\n"); err == nil || err.Error() != expected {
+			t.Errorf("%s: expected error %q, got %v", text, expected, err)
+		}
+	}
+}
+
+// randomCTL returns a random CTL formula together with the equivalent CTL* formula
+func randomCTL(r *rand.Rand, depth int, labels []string) (string, string) {
+	implies := "%s IMPLIES %s"
+	syntax := generator.CTLSyntax.With(generator.OpImplies, implies)
+	f := generator.Generate(r, generator.FormulaOptions{Depth: depth, Syntax: syntax, Labels: labels})
+	return f.Format(syntax), f.Format(generator.CTLStarSyntax.With(generator.OpImplies, implies))
+}
+
+// TestCTLStarAgainstCTL checks nested CTL formulas written in CTL* syntax on models without deadlocks,
+// where both semantics agree
+func TestCTLStarAgainstCTL(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	for seed := int64(0); seed < 20; seed++ {
+		o := generator.DefaultOptions()
+		o.States = 6
+		o.MinDegree = 1
+		o.MaxDegree = 2
+		o.Seed = seed
+		ks, err := generator.RandomKripkeStructure(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 20; i++ {
+			ctl, star := randomCTL(r, 1+r.Intn(4), o.LabelNames())
+			expected, err := parser.ParseFormula(ks, ctl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := parser.ParseFormula(ks, "CTL* "+star)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !actual.Check().Equals(expected.Check()) {
+				t.Errorf("seed %d: %s gives %s, but %s gives %s", seed, actual.String(), actual.Check().String(), ctl, expected.Check().String())
+			}
+		}
+		if ks.GetLabels().Size() != len(o.LabelNames()) {
+			t.Fatalf("seed %d: fresh labels have been added: %s", seed, ks.GetLabels().String())
+		}
+	}
+}
+
+// TestCTLStarConcurrently checks formulas with nested path quantifiers on a shared Kripke structure, like the server
+// does for concurrent requests
+func TestCTLStarConcurrently(t *testing.T) {
+	ks, formulas, err := parser.ParseString("states\ns1\ns2\ns3\ntransitions\ns1 -> s2 -> s1\ns2 -> s3 -> s1\nlabels\np: s1\nq: s3\nformulas\n" +
+		"CTL* A G E F q\nE (G F p & X A F p)\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan string, 32)
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(f int) {
+			defer wg.Done()
+			results <- formulas[f].Check().String()
+		}(i % 2)
+	}
+	wg.Wait()
+	close(results)
+
+	for actual := range results {
+		if actual != "{s1, s2, s3}" {
+			t.Errorf("expected {s1, s2, s3}, got %s", actual)
+		}
+	}
+	if size := ks.GetLabels().Size(); size != 2 {
+		t.Errorf("expected no fresh labels in the Kripke structure, got %s", ks.GetLabels().String())
+	}
+}
@@ -0,0 +1,69 @@
+package test
+
+import (
+	"cav/golang/explain"
+	"cav/golang/parser"
+	"cav/golang/types"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	ks, _, err := parser.ParseString("states\ns1\ns2\ns3\ntransitions\ns1 -> s2 -> s3 -> s2\ns3 -> s1\nlabels\np: s1, s2, s3\nq: s3\nformulas\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	states := map[string]cav.IState{}
+	ks.GetStates().ForEach(func(state cav.IState) {
+		states[state.GetName()] = state
+	})
+
+	for _, test := range []struct {
+		formula, state, expected string
+	}{
+		{"E[p U q]", "s1", `s1 |= E[p U q]: iteration 2: p holds and successor s2 has been reached in iteration 1
+  s1 |= p: s1 has label p
+  s2 |= E[p U q]: iteration 1: p holds and successor s3 has been reached in iteration 0
+    s2 |= p: s2 has label p
+    s3 |= E[p U q]: iteration 0: q holds
+      s3 |= q: s3 has label q
+`},
+		{"EG p", "s1", `s1 |= EG p: p holds along the path s1 -> s2 -> s3 -> s1 which loops back to s1
+  s1 |= p: s1 has label p
+  s2 |= p: s2 has label p
+  s3 |= p: s3 has label p
+`},
+		{"EX q IMPLIES q", "s2", `s2 |/= (EX q IMPLIES q): the premise holds but the conclusion does not
+  s2 |= EX q: successor s3 satisfies q
+    s3 |= q: s3 has label q
+  s2 |/= q: s2 does not have label q
+`},
+		{"E[NOT q U false]", "s1", `s1 |/= E[NOT q U false]: false does not hold and no successor satisfies E[NOT q U false]
+  s1 |/= false: false holds nowhere
+  s2 |/= E[NOT q U false]: false does not hold and no successor satisfies E[NOT q U false]
+    s2 |/= false: false holds nowhere
+    s3 |/= E[NOT q U false]: neither NOT q nor false holds
+      s3 |/= false: false holds nowhere
+      s3 |/= NOT q: negation
+        s3 |= q: s3 has label q
+`},
+	} {
+		node, err := parser.ParseNode(test.formula)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proof, err := explain.Explain(node, states[test.state])
+		if err != nil {
+			t.Fatal(err)
+		}
+		var text strings.Builder
+		proof.WriteText(&text)
+		if text.String() != test.expected {
+			t.Errorf("unexpected proof for %s in %s:\n%s", test.formula, test.state, text.String())
+		}
+		if _, err := json.Marshal(proof); err != nil {
+			t.Error(err)
+		}
+	}
+}
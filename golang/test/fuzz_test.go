@@ -0,0 +1,64 @@
+package test
+
+import (
+	"cav/golang/parser"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func FuzzParseFile(f *testing.F) {
+	for _, name := range []string{"kripkestructure_test.txt", "kripkestructure_exam.txt", "kripkestructure_report.txt", "kripkestructure_regression.txt"} {
+		data, err := os.ReadFile(filepath.Join("..", "..", name))
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		path := filepath.Join(t.TempDir(), "fuzz.txt")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		ks, flas, err := parser.ParseFile(path)
+		if err != nil {
+			return
+		}
+		_ = ks.DetailString()
+		for _, fla := range flas {
+			fla.Check()
+		}
+	})
+}
+
+func FuzzParseFormula(f *testing.F) {
+	ks, flas, err := parser.ParseFile(filepath.Join("..", "..", "kripkestructure_test.txt"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, fla := range flas {
+		f.Add(fla.String())
+	}
+	f.Add("E[p U q]")
+	f.Add("NOT (p AND q) OR EX r")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		p := parser.MakeFileParser()
+		fla, err := p.ParseFormula(ks, s)
+		if err != nil {
+			return
+		}
+		result := fla.Check()
+
+		// the string form of a formula has to parse to an equivalent formula
+		again, err := p.ParseFormula(ks, fla.String())
+		if err != nil {
+			t.Fatalf("%q parsed to %q which cannot be parsed again: %s", s, fla.String(), err)
+		}
+		if !again.Check().Equals(result) {
+			t.Fatalf("%q parsed to %q which is not equivalent", s, fla.String())
+		}
+	})
+}
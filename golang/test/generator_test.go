@@ -0,0 +1,163 @@
+package test
+
+import (
+	"bytes"
+	"cav/golang/generator"
+	"cav/golang/parser"
+	"cav/golang/types"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// countSCCs counts the strongly connected components using Tarjan's algorithm
+func countSCCs(ks cav.IKripkeStructure) int {
+	index := map[cav.IState]int{}
+	lowlink := map[cav.IState]int{}
+	onStack := map[cav.IState]bool{}
+	stack := make([]cav.IState, 0)
+	count := 0
+
+	var visit func(state cav.IState)
+	visit = func(state cav.IState) {
+		index[state] = len(index)
+		lowlink[state] = index[state]
+		stack = append(stack, state)
+		onStack[state] = true
+		state.GetChildren().ForEach(func(child cav.IState) {
+			if _, ok := index[child]; !ok {
+				visit(child)
+				lowlink[state] = min(lowlink[state], lowlink[child])
+			} else if onStack[child] {
+				lowlink[state] = min(lowlink[state], index[child])
+			}
+		})
+		if lowlink[state] == index[state] {
+			count++
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				if top == state {
+					break
+				}
+			}
+		}
+	}
+	ks.GetStates().ForEach(func(state cav.IState) {
+		if _, ok := index[state]; !ok {
+			visit(state)
+		}
+	})
+	return count
+}
+
+func TestGenerator(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		o := generator.DefaultOptions()
+		o.States = 12
+		o.MinDegree = 1
+		o.MaxDegree = 2
+		o.SCCs = 1 + int(seed%5)
+		o.Seed = seed
+
+		ks, err := generator.RandomKripkeStructure(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ks.GetStates().Size() != 12 || ks.GetLabels().Size() != 3 {
+			t.Errorf("seed %d: unexpected size: %s", seed, ks.String())
+		}
+		if sccs := countSCCs(ks); sccs != o.SCCs {
+			t.Errorf("seed %d: expected %d SCCs, got %d", seed, o.SCCs, sccs)
+		}
+		ks.GetStates().ForEach(func(state cav.IState) {
+			// the cycle through the component may add one successor on top of the drawn out-degree
+			if degree := state.GetChildren().Size(); degree < 1 || degree > 3 {
+				t.Errorf("seed %d: %s has %d successors", seed, state.GetName(), degree)
+			}
+		})
+
+		// the same options always result in the same file
+		var first, second bytes.Buffer
+		parser.Write(&first, ks)
+		again, _ := generator.RandomKripkeStructure(o)
+		parser.Write(&second, again)
+		if first.String() != second.String() {
+			t.Errorf("seed %d: generation is not deterministic", seed)
+		}
+	}
+}
+
+func TestGeneratorRoundTrip(t *testing.T) {
+	o := generator.DefaultOptions()
+	o.LabelDensity = 0.1
+	ks, err := generator.RandomKripkeStructure(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	formulas := make([]string, 20)
+	for i := range formulas {
+		formulas[i] = generator.RandomFormula(r, 4, o.LabelNames())
+	}
+
+	var buffer bytes.Buffer
+	if err := parser.Write(&buffer, ks, formulas...); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "generated.txt")
+	os.WriteFile(path, buffer.Bytes(), 0644)
+
+	parsed, flas, err := parser.ParseFile(path)
+	if err != nil {
+		t.Fatalf("%s\n%s", err, buffer.String())
+	}
+	if len(flas) != len(formulas) || parsed.GetStates().Size() != ks.GetStates().Size() {
+		t.Errorf("round trip lost information: %s", parsed.String())
+	}
+
+	var written bytes.Buffer
+	parser.Write(&written, parsed, formulas...)
+	if written.String() != buffer.String() {
+		t.Errorf("round trip changed the file:\n%s\n%s", buffer.String(), written.String())
+	}
+}
+
+func TestGenerateFormula(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var depth func(f *generator.Formula, syntax generator.Syntax) int
+	depth = func(f *generator.Formula, syntax generator.Syntax) int {
+		if f.Op == "" || f.Op == generator.OpTrue || f.Op == generator.OpFalse {
+			return 0
+		}
+		if _, ok := syntax[f.Op]; !ok || len(f.Sub) != syntax.Arity(f.Op) {
+			t.Fatalf("unexpected operator %s with %d operands", f.Op, len(f.Sub))
+		}
+		result := 0
+		for _, sub := range f.Sub {
+			result = max(result, depth(sub, syntax))
+		}
+		return result + 1
+	}
+
+	for _, syntax := range []generator.Syntax{generator.CTLSyntax, generator.CTLStarSyntax, generator.PastSyntax, generator.LTLSyntax} {
+		for i := 0; i < 100; i++ {
+			o := generator.FormulaOptions{Depth: r.Intn(5), Syntax: syntax, Labels: []string{"p", "q"}}
+			f := generator.Generate(r, o)
+			if d := depth(f, syntax); d != o.Depth {
+				t.Errorf("%s: expected depth %d, got %d", f.Format(syntax), o.Depth, d)
+			}
+			if again := generator.Generate(rand.New(rand.NewSource(int64(i))), o).Format(syntax); again != generator.Generate(rand.New(rand.NewSource(int64(i))), o).Format(syntax) {
+				t.Errorf("the same random numbers gave different formulas")
+			}
+		}
+	}
+
+	ks, _ := generator.RandomKripkeStructure(generator.DefaultOptions())
+	if _, err := parser.ParseFormula(ks, generator.RandomFormula(r, 3, []string{"p0", "p1"})); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,184 @@
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"cav/golang/lsp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func writeLSPMessage(buffer *bytes.Buffer, id int, method string, params any) {
+	msg := map[string]any{"jsonrpc": "2.0", "method": method, "params": params}
+	if id > 0 {
+		msg["id"] = id
+	}
+	body, _ := json.Marshal(msg)
+	fmt.Fprintf(buffer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func readLSPMessages(t *testing.T, r io.Reader) []map[string]json.RawMessage {
+	reader := bufio.NewReader(r)
+	messages := make([]map[string]json.RawMessage, 0)
+	for {
+		header, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return messages
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		length, _ := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "Content-Length:")))
+		reader.ReadString('\n')
+		body := make([]byte, length)
+		io.ReadFull(reader, body)
+		msg := map[string]json.RawMessage{}
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, msg)
+	}
+}
+
+func TestLanguageServer(t *testing.T) {
+	path, _ := filepath.Abs(filepath.Join("..", "..", "kripkestructure_report.txt"))
+	text, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := "file://" + path
+	document := map[string]any{"uri": uri}
+
+	in := &bytes.Buffer{}
+	writeLSPMessage(in, 1, "initialize", map[string]any{})
+	writeLSPMessage(in, 0, "textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": uri, "text": string(text)}})
+	// "s7" in "s1 -> s2 -> s7 -> ..."
+	writeLSPMessage(in, 2, "textDocument/definition", map[string]any{"textDocument": document, "position": map[string]int{"line": 11, "character": 13}})
+	// "E[p U q]" in the formulas section
+	writeLSPMessage(in, 3, "textDocument/hover", map[string]any{"textDocument": document, "position": map[string]int{"line": 23, "character": 0}})
+	writeLSPMessage(in, 4, "textDocument/completion", map[string]any{"textDocument": document, "position": map[string]int{"line": 22, "character": 0}})
+	writeLSPMessage(in, 5, "shutdown", nil)
+	writeLSPMessage(in, 0, "exit", nil)
+
+	out := &bytes.Buffer{}
+	if err := lsp.MakeServer(in, out).Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := readLSPMessages(t, out)
+	if len(messages) != 6 {
+		t.Fatalf("expected 6 messages, got %d", len(messages))
+	}
+
+	var diagnostics struct {
+		Diagnostics []lsp.Diagnostic `json:"diagnostics"`
+	}
+	json.Unmarshal(messages[1]["params"], &diagnostics)
+	if len(diagnostics.Diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diagnostics.Diagnostics)
+	}
+
+	var locations []lsp.Location
+	json.Unmarshal(messages[2]["result"], &locations)
+	if len(locations) != 1 || locations[0].Range.Start.Line != 7 {
+		t.Errorf("expected s7 to be declared in line 7, got %v", locations)
+	}
+
+	var hover lsp.Hover
+	json.Unmarshal(messages[3]["result"], &hover)
+	if hover.Contents != "E[p U q]: {s1, s2, s3, s5, s6, s7}" {
+		t.Errorf("unexpected hover: %s", hover.Contents)
+	}
+
+	var items []lsp.CompletionItem
+	json.Unmarshal(messages[4]["result"], &items)
+	labels := map[string]bool{}
+	for _, item := range items {
+		labels[item.Label] = true
+	}
+	if !labels["p"] || !labels["q"] || !labels["r"] || labels["s1"] {
+		t.Errorf("expected label completions in the formulas section, got %v", items)
+	}
+}
+
+func TestLanguageServerDiagnostics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.txt")
+	text := "states\ns1\ntransitions\ns1 -> s2\nlabels\nformulas\n"
+	os.WriteFile(path, []byte(text), 0644)
+	uri := "file://" + path
+
+	in := &bytes.Buffer{}
+	writeLSPMessage(in, 0, "textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": uri, "text": text}})
+	out := &bytes.Buffer{}
+	if err := lsp.MakeServer(in, out).Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := readLSPMessages(t, out)
+	var diagnostics struct {
+		Diagnostics []lsp.Diagnostic `json:"diagnostics"`
+	}
+	json.Unmarshal(messages[0]["params"], &diagnostics)
+	if len(diagnostics.Diagnostics) != 1 || diagnostics.Diagnostics[0].Range.Start.Line != 3 {
+		t.Errorf("expected a diagnostic in line 3, got %v", diagnostics.Diagnostics)
+	}
+}
+
+func TestLanguageServerUnsavedChanges(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "model.txt")
+	// the file on disk is out of date, only the buffer sent by the client is parsed
+	os.WriteFile(path, []byte("states\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "labels.txt"), []byte("p: s1\nq: s10\n"), 0644)
+	text := "states\n  s10\n  s1\ntransitions\ns10 -> s1 -> s1\nlabels\ninclude \"labels.txt\"\nformulas\nEF q\n"
+	uri := "file://" + path
+	document := map[string]any{"uri": uri}
+	change := func(text string) map[string]any {
+		return map[string]any{"textDocument": document, "contentChanges": []map[string]any{{"text": text}}}
+	}
+
+	in := &bytes.Buffer{}
+	writeLSPMessage(in, 0, "textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": uri, "text": text}})
+	writeLSPMessage(in, 0, "textDocument/didChange", change("states\ns1\ntransitions\ns1 -> s2\nlabels\nformulas\n"))
+	writeLSPMessage(in, 0, "textDocument/didChange", change(text))
+	// "s1" in "s10 -> s1 -> s1"
+	writeLSPMessage(in, 1, "textDocument/definition", map[string]any{"textDocument": document, "position": map[string]int{"line": 4, "character": 8}})
+	// "q" in "EF q" is declared in the included file
+	writeLSPMessage(in, 2, "textDocument/definition", map[string]any{"textDocument": document, "position": map[string]int{"line": 8, "character": 3}})
+	out := &bytes.Buffer{}
+	if err := lsp.MakeServer(in, out).Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := readLSPMessages(t, out)
+	if len(messages) != 5 {
+		t.Fatalf("expected 5 messages, got %d", len(messages))
+	}
+	for i, expected := range []int{-1, 3, -1} {
+		var diagnostics struct {
+			Diagnostics []lsp.Diagnostic `json:"diagnostics"`
+		}
+		json.Unmarshal(messages[i]["params"], &diagnostics)
+		if expected < 0 && len(diagnostics.Diagnostics) != 0 {
+			t.Errorf("expected no diagnostics after change %d, got %v", i, diagnostics.Diagnostics)
+		} else if expected >= 0 && (len(diagnostics.Diagnostics) != 1 || diagnostics.Diagnostics[0].Range.Start.Line != expected) {
+			t.Errorf("expected a diagnostic in line %d after change %d, got %v", expected, i, diagnostics.Diagnostics)
+		}
+	}
+
+	for i, expected := range []lsp.Location{
+		{URI: uri, Range: lsp.Range{Start: lsp.Position{Line: 2, Character: 2}, End: lsp.Position{Line: 2, Character: 4}}},
+		{URI: "file://" + filepath.Join(dir, "labels.txt"), Range: lsp.Range{Start: lsp.Position{Line: 1, Character: 0}, End: lsp.Position{Line: 1, Character: 1}}},
+	} {
+		var locations []lsp.Location
+		json.Unmarshal(messages[3+i]["result"], &locations)
+		if len(locations) != 1 || locations[0] != expected {
+			t.Errorf("expected definition at %v, got %v", expected, locations)
+		}
+	}
+}
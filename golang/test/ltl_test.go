@@ -0,0 +1,233 @@
+package test
+
+import (
+	"cav/golang/generator"
+	"cav/golang/ltl"
+	"cav/golang/parser"
+	"cav/golang/types"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func randomLTL(t *testing.T, r *rand.Rand, depth int, labels []string) *ltl.Formula {
+	text := generator.Generate(r, generator.FormulaOptions{Depth: depth, Syntax: generator.LTLSyntax, Labels: labels}).Format(generator.LTLSyntax)
+	f, err := ltl.Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// evaluateLasso decides the formula on the infinite path prefix (cycle)^ω
+func evaluateLasso(f *ltl.Formula, lasso *ltl.Lasso, labels map[string]cav.ILabel) bool {
+	path := append(append([]cav.IState{}, lasso.Prefix...), lasso.Cycle...)
+	next := func(i int) int {
+		if i+1 < len(path) {
+			return i + 1
+		}
+		return len(lasso.Prefix)
+	}
+
+	var sat func(f *ltl.Formula) []bool
+	sat = func(f *ltl.Formula) []bool {
+		result := make([]bool, len(path))
+		var a, b []bool
+		if len(f.Sub) > 0 {
+			a = sat(f.Sub[0])
+		}
+		if len(f.Sub) > 1 {
+			b = sat(f.Sub[1])
+		}
+		// until and release are fixpoints over the positions, which are reached after 2 * len(path) sweeps
+		fixpoint := func(initial bool, step func(i int) bool) {
+			for i := range result {
+				result[i] = initial
+			}
+			for sweep := 0; sweep < 2*len(path); sweep++ {
+				for i := len(path) - 1; i >= 0; i-- {
+					result[i] = step(i)
+				}
+			}
+		}
+		switch f.Op {
+		case ltl.OpTrue, ltl.OpFalse:
+			for i := range result {
+				result[i] = f.Op == ltl.OpTrue
+			}
+		case ltl.OpLabel:
+			for i, state := range path {
+				result[i] = state.HasLabel(labels[f.Label])
+			}
+		case ltl.OpNot, ltl.OpAnd, ltl.OpOr, ltl.OpImplies, ltl.OpX:
+			for i := range result {
+				switch f.Op {
+				case ltl.OpNot:
+					result[i] = !a[i]
+				case ltl.OpAnd:
+					result[i] = a[i] && b[i]
+				case ltl.OpOr:
+					result[i] = a[i] || b[i]
+				case ltl.OpImplies:
+					result[i] = !a[i] || b[i]
+				case ltl.OpX:
+					result[i] = a[next(i)]
+				}
+			}
+		case ltl.OpF:
+			fixpoint(false, func(i int) bool { return a[i] || result[next(i)] })
+		case ltl.OpG:
+			fixpoint(true, func(i int) bool { return a[i] && result[next(i)] })
+		case ltl.OpU:
+			fixpoint(false, func(i int) bool { return b[i] || (a[i] && result[next(i)]) })
+		case ltl.OpR:
+			fixpoint(true, func(i int) bool { return b[i] && (a[i] || result[next(i)]) })
+		}
+		return result
+	}
+	return sat(f)[0]
+}
+
+func TestLTLParse(t *testing.T) {
+	for text, expected := range map[string]string{
+		"G(req -> F grant)":   "G (req IMPLIES F grant)",
+		"a U b U c":           "(a U (b U c))",
+		"p -> q IMPLIES r":    "(p IMPLIES (q IMPLIES r))",
+		"!a & b | X c":        "((NOT a AND b) OR X c)",
+		"NOT (a R b) AND G a": "(NOT (a R b) AND G a)",
+		// path quantifiers and words of prefix operators are labels in LTL
+		"G E -> F AE": "(G E IMPLIES F AE)",
+	} {
+		f, err := ltl.Parse(text)
+		if err != nil {
+			t.Errorf("%s: %s", text, err.Error())
+		} else if f.String() != expected {
+			t.Errorf("%s: expected %s, got %s", text, expected, f.String())
+		}
+	}
+
+	for text, expected := range map[string]string{
+		"G (p":   "column 5: expected \")\"",
+		"p U":    "column 4: missing formula",
+		"p q":    "column 3: unexpected q",
+		"AND p":  "column 1: unexpected AND",
+		"F (p))": "column 6: unexpected )",
+	} {
+		if _, err := ltl.Parse(text); err == nil || err.Error() != expected {
+			t.Errorf("%s: expected error %q, got %v", text, expected, err)
+		}
+	}
+
+	if f, err := ltl.ParseCTLStar("AG E F p"); err != nil || f.String() != "A G E F p" {
+		t.Errorf("AG E F p: expected A G E F p, got %v, %v", f, err)
+	}
+}
+
+func TestLTL(t *testing.T) {
+	ks, formulas, err := parser.ParseString("states\ns1\ns2\ns3\ninitial\ns1\ntransitions\ns1 -> s2 -> s3 -> s1\ns2 -> s2\nlabels\nreq: s1\ngrant: s3\nformulas\n" +
+		"LTL G (req -> F grant)\nLTL G F req -> G (req -> F grant)\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cav.Holds(formulas[0]) || !cav.Holds(formulas[1]) {
+		t.Errorf("unexpected verdicts for %s and %s", formulas[0].String(), formulas[1].String())
+	}
+	lasso := formulas[0].(*ltl.LTLFormula).Counterexample()
+	if lasso == nil || lasso.String() != "s1 -> (s2)^ω" {
+		t.Errorf("expected the counterexample s1 -> (s2)^ω, got %v", lasso)
+	}
+	if initial := ks.GetInitialStates().String(); initial != "{s1}" {
+		t.Errorf("expected s1 to be the only initial state, got %s", initial)
+	}
+	_, formulas, err = parser.ParseString("states\ns1\ns2\ntransitions\ns1 -> s2 -> s2\nlabels\nE: s2\nAE: s1\nformulas\n" +
+		"LTL G E\nLTL AE -> X G E\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []string{"{s2}", "{s1, s2}"} {
+		if actual := formulas[i].Check().String(); actual != expected {
+			t.Errorf("%s: expected %s, got %s", formulas[i].String(), expected, actual)
+		}
+	}
+	if _, _, err := parser.ParseString("states\ns1\ntransitions\nlabels\nformulas\nLTL G (p\n"); err == nil || err.Error() != "6:9: expected \")\"" {
+		t.Errorf("expected a syntax error in 6:9, got %v", err)
+	}
+}
+
+// TestLTLAgainstCTL compares formulas with a single temporal operator to the equivalent CTL formulas,
+// and checks that counterexamples are paths of the model violating the formula
+func TestLTLAgainstCTL(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	ctl := map[ltl.Op][2]string{
+		ltl.OpX: {"AX %s", "EX %s"}, ltl.OpF: {"AF %s", "EF %s"}, ltl.OpG: {"AG %s", "EG %s"},
+		ltl.OpU: {"A[%s U %s]", "E[%s U %s]"}, ltl.OpR: {"A[%s R %s]", "E[%s R %s]"},
+	}
+
+	for seed := int64(0); seed < 30; seed++ {
+		o := generator.DefaultOptions()
+		o.States = 6
+		o.MinDegree = 1
+		o.MaxDegree = 2
+		o.Seed = seed
+		ks, err := generator.RandomKripkeStructure(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		labels := map[string]cav.ILabel{}
+		ks.GetLabels().ForEach(func(label cav.ILabel) {
+			labels[label.String()] = label
+		})
+		names := o.LabelNames()
+
+		for op, pattern := range ctl {
+			a, b := names[r.Intn(len(names))], names[r.Intn(len(names))]
+			f := ltl.MakeFormula(op, ltl.MakeLabel(a))
+			args := []any{a}
+			if op == ltl.OpU || op == ltl.OpR {
+				f = ltl.MakeFormula(op, ltl.MakeLabel(a), ltl.MakeLabel(b))
+				args = append(args, b)
+			}
+
+			universal, _ := ltl.MakeLTLFormula(ks, f)
+			existential, _ := ltl.Exists(ks, f)
+			for i, result := range []cav.ISet[cav.IState]{universal.Check(), existential} {
+				text := fmt.Sprintf(pattern[i], args...)
+				expected, err := parser.ParseFormula(ks, text)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !result.Equals(expected.Check()) {
+					t.Errorf("seed %d: %s gives %s, but %s gives %s", seed, f.String(), result.String(), text, expected.Check().String())
+				}
+			}
+		}
+
+		for i := 0; i < 20; i++ {
+			f := randomLTL(t, r, 1+r.Intn(3), names)
+			formula, _ := ltl.MakeLTLFormula(ks, f)
+			satisfying := formula.Check()
+			ks.GetStates().ForEach(func(state cav.IState) {
+				lasso, _ := ltl.Counterexample(ks, f, cav.MakeSetOf(state))
+				if (lasso == nil) != satisfying.Contains(state) {
+					t.Fatalf("seed %d: %s in %s: counterexample %v contradicts %s", seed, f.String(), state.GetName(), lasso, satisfying.String())
+				}
+				if lasso == nil {
+					return
+				}
+				path := append(append([]cav.IState{}, lasso.Prefix...), lasso.Cycle...)
+				for j := 0; j < len(path); j++ {
+					next := lasso.Cycle[0]
+					if j+1 < len(path) {
+						next = path[j+1]
+					}
+					if !path[j].HasChild(next) {
+						t.Fatalf("seed %d: %s is not a path", seed, lasso.String())
+					}
+				}
+				if path[0] != state || evaluateLasso(f, lasso, labels) {
+					t.Fatalf("seed %d: %s does not violate %s from %s", seed, lasso.String(), f.String(), state.GetName())
+				}
+			})
+		}
+	}
+}
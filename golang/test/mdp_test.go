@@ -0,0 +1,237 @@
+package test
+
+import (
+	"cav/golang/generator"
+	"cav/golang/parser"
+	"cav/golang/pctl"
+	"cav/golang/types"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const mdpModel = "states\ns0\ns1\ns2\ns3\ntransitions\ns0 -a(0.5)-> s1\ns0 -a(1/2)-> s2\ns0 -b(1)-> s0\ns1 -c(0.5)-> s3\ns1 -c(0.5)-> s2\ns1 -d(1)-> s0\ns2 -e(1)-> s2\ns3 -e(1)-> s3\nlabels\ngoal: s3\nfail: s2\nformulas\n"
+
+func TestMarkovDecisionProcess(t *testing.T) {
+	ks, formulas, err := parser.ParseString(mdpModel +
+		"Pmax=? [F goal]\nPmin=? [F goal]\nPmin=? [G NOT fail]\nPmax>=0.5 [X fail]\nPmax>0.3 [F<=2 goal]\nPmin<0.5 [NOT goal U fail]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []string{"{s0, s1, s3}", "{s3}", "{s3}", "{s0, s1, s2}", "{s1, s3}", "{s0, s1, s3}"} {
+		if actual := formulas[i].Check().String(); actual != expected {
+			t.Errorf("%s: expected %s, got %s", formulas[i].String(), expected, actual)
+		}
+	}
+
+	for i, expected := range []string{
+		"map[s0:0.25 s1:0.5 s2:0 s3:1] map[s0:a s1:c s2:e s3:e]",
+		"map[s0:0 s1:0 s2:0 s3:1] map[s0:a s1:d s2:e s3:e]",
+		"map[s0:0 s1:0 s2:0 s3:1] map[s0:a s1:d s2:e s3:e]",
+	} {
+		formula := formulas[i].(*pctl.PCTLFormula)
+		probabilities := map[string]float64{}
+		for state, p := range formula.Probabilities() {
+			probabilities[state.GetName()] = math.Round(p*1e6) / 1e6
+		}
+		scheduler := map[string]string{}
+		for state, action := range formula.Scheduler() {
+			scheduler[state.GetName()] = action
+		}
+		if actual := fmt.Sprint(probabilities, scheduler); actual != expected {
+			t.Errorf("%s: expected %s, got %s", formula.String(), expected, actual)
+		}
+		if convergence := formula.Convergence(); convergence == nil || !convergence.Converged {
+			t.Errorf("%s: expected convergence, got %v", formula.String(), convergence)
+		}
+	}
+	if bounded := formulas[4].(*pctl.PCTLFormula); bounded.Scheduler() != nil || bounded.Convergence() != nil {
+		t.Errorf("%s: expected neither a scheduler nor convergence", bounded.String())
+	}
+
+	var sb strings.Builder
+	if err := parser.Write(&sb, ks); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "s0 -a(0.5)-> s2\ns0 -b(1)-> s0\n") {
+		t.Errorf("expected the actions to be written:\n%s", sb.String())
+	}
+
+	for model, expected := range map[string]string{
+		mdpModel + "P=? [F goal]\n":                                "19: P needs to be Pmax or Pmin in a Markov decision process: P=? [F goal]",
+		mdpModel + "R=? [F goal]\n":                                "19: rewards are not supported for Markov decision processes: R=? [F goal]",
+		markovModel + "Pmax>0 [F goal]\n":                          "15: Pmax and Pmin need a Markov decision process: Pmax>0 [F goal]",
+		markovModel + "Pmin>=2 [F goal]\n":                         "15:7: invalid probability: 2",
+		strings.Replace(mdpModel, "c(0.5)-> s2", "c(0.4)-> s2", 1): "15: invalid Markov decision process: probabilities of action c in s1 sum to 0.9 instead of 1",
+		strings.Replace(mdpModel, "-e(1)-> s3", "-(1)-> s3", 1):    "15: invalid Markov decision process: state s3 has no action",
+	} {
+		if _, _, err := parser.ParseString(model); err == nil || err.Error() != expected {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	}
+}
+
+// randomMarkovDecisionProcess splits the transitions of a generated Kripke structure without deadlocks into up to
+// two actions per state with random probabilities
+func randomMarkovDecisionProcess(t *testing.T, r *rand.Rand, seed int64) (cav.IKripkeStructure, []string) {
+	o := generator.DefaultOptions()
+	o.States = 6
+	o.MinDegree = 1
+	o.MaxDegree = 4
+	o.Seed = seed
+	ks, err := generator.RandomKripkeStructure(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks.GetStates().ForEach(func(state cav.IState) {
+		weights := map[string]map[cav.IState]float64{"a": {}, "b": {}}
+		state.GetChildren().ForEach(func(child cav.IState) {
+			action := []string{"a", "b"}[r.Intn(2)]
+			weights[action][child] = 1 + float64(r.Intn(4))
+			if r.Intn(3) == 0 {
+				weights[map[string]string{"a": "b", "b": "a"}[action]][child] = 1
+			}
+		})
+		for action, choice := range weights {
+			sum := 0.0
+			for _, weight := range choice {
+				sum += weight
+			}
+			for child, weight := range choice {
+				state.AddChoice(action, child, weight/sum)
+			}
+		}
+	})
+	if err := cav.CheckMarkovDecisionProcess(ks); err != nil {
+		t.Fatal(err)
+	}
+	return ks, o.LabelNames()
+}
+
+// TestMarkovDecisionProcessTies checks that actions with the same distribution, whose sums of probabilities depend
+// on the order of the terms, always result in the same probabilities and in the action that comes first by name
+func TestMarkovDecisionProcessTies(t *testing.T) {
+	model := "states\ns0\ns1\ns2\ns3\ns4\ntransitions\n" +
+		"s0 -b(0.4)-> s4\ns0 -b(0.3)-> s3\ns0 -b(0.2)-> s2\ns0 -b(0.1)-> s1\n" +
+		"s0 -a(0.1)-> s1\ns0 -a(0.2)-> s2\ns0 -a(0.3)-> s3\ns0 -a(0.4)-> s4\n" +
+		"s1 -e(1)-> s1\ns2 -e(1)-> s2\ns3 -e(1)-> s3\ns4 -e(1)-> s4\nlabels\ngoal: s1, s2, s3\nformulas\n" +
+		"Pmax=? [X goal]\nPmin=? [X goal]\nPmax=? [F goal]\nPmin=? [F goal]\n"
+	results := map[int]string{}
+	for run := 0; run < 50; run++ {
+		_, formulas, err := parser.ParseString(model)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, f := range formulas {
+			formula := f.(*pctl.PCTLFormula)
+			var s0 cav.IState
+			for state := range formula.Probabilities() {
+				if state.GetName() == "s0" {
+					s0 = state
+				}
+			}
+			result := strconv.FormatFloat(formula.Probabilities()[s0], 'g', -1, 64) + " " + formula.Scheduler()[s0]
+			if !strings.HasSuffix(result, " a") {
+				t.Fatalf("%s: expected action a in s0, got %s", formula.String(), result)
+			}
+			if expected, ok := results[i]; ok && result != expected {
+				t.Fatalf("%s: got %s and %s", formula.String(), expected, result)
+			}
+			results[i] = result
+		}
+	}
+}
+
+// TestMarkovDecisionProcessAgainstCTL compares the qualitative optimal probabilities to CTL, and checks that the
+// scheduler of Pmax and Pmin attains the optimal probability in the Markov chain it induces
+func TestMarkovDecisionProcessAgainstCTL(t *testing.T) {
+	r := rand.New(rand.NewSource(29))
+	for seed := int64(0); seed < 30; seed++ {
+		ks, names := randomMarkovDecisionProcess(t, r, seed)
+
+		for i := 0; i < 5; i++ {
+			a, b := names[r.Intn(len(names))], names[r.Intn(len(names))]
+			for probabilistic, ctl := range map[string]string{
+				fmt.Sprintf("Pmax>0 [F %s]", a):         "EF " + a,
+				fmt.Sprintf("Pmin>=1 [G %s]", a):        "AG " + a,
+				fmt.Sprintf("Pmax>0 [%s U %s]", a, b):   fmt.Sprintf("E[%s U %s]", a, b),
+				fmt.Sprintf("Pmin>=1 [X %s]", a):        "AX " + a,
+				fmt.Sprintf("Pmax<1 [G %s]", a):         fmt.Sprintf("PCTL Pmin>0 [F NOT %s]", a),
+				fmt.Sprintf("Pmax>=0.5 [F<=0 %s]", a):   a,
+				fmt.Sprintf("Pmin<=0.5 [G<=0 %s]", a):   "NOT " + a,
+				fmt.Sprintf("Pmin>=0.5 [true U %s]", b): fmt.Sprintf("PCTL Pmin>=0.5 [F %s]", b),
+			} {
+				actual, err := parser.ParseFormula(ks, probabilistic)
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected, err := parser.ParseFormula(ks, ctl)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !actual.Check().Equals(expected.Check()) {
+					t.Errorf("seed %d: %s gives %s, but %s gives %s", seed, probabilistic, actual.Check().String(), ctl, expected.Check().String())
+				}
+			}
+
+			maximal, _ := parser.ParseFormula(ks, fmt.Sprintf("Pmax=? [%s U %s]", a, b))
+			minimal, _ := parser.ParseFormula(ks, fmt.Sprintf("Pmin=? [%s U %s]", a, b))
+			for _, formula := range []*pctl.PCTLFormula{maximal.(*pctl.PCTLFormula), minimal.(*pctl.PCTLFormula)} {
+				optimal := formula.Probabilities()
+				induced := inducedMarkovChain(t, formula.Scheduler())
+				chain, _ := parser.ParseFormula(induced.ks, fmt.Sprintf("P=? [%s U %s]", a, b))
+				for state, p := range chain.(*pctl.PCTLFormula).Probabilities() {
+					if original := induced.states[state.GetName()]; math.Abs(p-optimal[original]) > 1e-6 {
+						t.Errorf("seed %d: the scheduler of %s attains %g in %s instead of %g", seed, formula.String(), p, state.GetName(), optimal[original])
+					}
+				}
+			}
+			minimalProbabilities := minimal.(*pctl.PCTLFormula).Probabilities()
+			for state, p := range maximal.(*pctl.PCTLFormula).Probabilities() {
+				if minimalProbabilities[state] > p+1e-9 {
+					t.Errorf("seed %d: minimal probability %g of %s exceeds the maximal one %g", seed, minimalProbabilities[state], state.GetName(), p)
+				}
+			}
+		}
+	}
+}
+
+type inducedChain struct {
+	ks     cav.IKripkeStructure
+	states map[string]cav.IState
+}
+
+// inducedMarkovChain copies the Kripke structure of the scheduler, keeping only the transitions of the chosen actions
+func inducedMarkovChain(t *testing.T, scheduler map[cav.IState]string) inducedChain {
+	var sb strings.Builder
+	sb.WriteString("states\n")
+	result := inducedChain{states: map[string]cav.IState{}}
+	labels := map[string][]string{}
+	for state := range scheduler {
+		sb.WriteString(state.GetName() + "\n")
+		result.states[state.GetName()] = state
+		state.GetLabels().ForEach(func(label cav.ILabel) {
+			labels[label.String()] = append(labels[label.String()], state.GetName())
+		})
+	}
+	sb.WriteString("transitions\n")
+	for state, action := range scheduler {
+		for child, p := range state.GetChoice(action) {
+			sb.WriteString(fmt.Sprintf("%s -(%s)-> %s\n", state.GetName(), strconv.FormatFloat(p, 'g', -1, 64), child.GetName()))
+		}
+	}
+	sb.WriteString("labels\n")
+	for label, states := range labels {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", label, strings.Join(states, ", ")))
+	}
+	sb.WriteString("formulas\n")
+	ks, _, err := parser.ParseString(sb.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	result.ks = ks
+	return result
+}
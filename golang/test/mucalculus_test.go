@@ -0,0 +1,184 @@
+package test
+
+import (
+	"cav/golang/generator"
+	"cav/golang/mucalculus"
+	"cav/golang/parser"
+	"math/rand"
+	"testing"
+)
+
+func TestMuParse(t *testing.T) {
+	for text, expected := range map[string]string{
+		"nu X. p AND [] X":           "(nu X. (p AND [] X))",
+		"mu X. q | <> X":             "(mu X. (q OR <> X))",
+		"nu X. mu Y. <>X & p | <> Y": "(nu X. (mu Y. ((<> X AND p) OR <> Y)))",
+		"p -> mu X. q":               "(p IMPLIES (mu X. q))",
+		"(mu X. <> X) AND X":         "((mu X. <> X) AND X)",
+		"!<>[] true":                 "NOT <> [] true",
+	} {
+		f, err := mucalculus.Parse(text)
+		if err != nil {
+			t.Errorf("%s: %s", text, err.Error())
+		} else if f.String() != expected {
+			t.Errorf("%s: expected %s, got %s", text, expected, f.String())
+		}
+	}
+	// outside of its fixpoint X is a label
+	if f, _ := mucalculus.Parse("(mu X. <> X) AND X"); f.Sub[1].Op != mucalculus.OpLabel || f.Sub[0].Sub[0].Sub[0].Op != mucalculus.OpVar {
+		t.Errorf("variables are not distinguished from labels in %s", f.String())
+	}
+
+	for text, expected := range map[string]string{
+		"mu . p":    "column 4: expected a variable",
+		"mu X p":    "column 6: expected \".\"",
+		"<> (p":     "column 6: expected \")\"",
+		"p AND":     "column 6: missing formula",
+		"[ p":       "column 1: unexpected [",
+		"p mu X. p": "column 3: unexpected mu",
+	} {
+		if _, err := mucalculus.Parse(text); err == nil || err.Error() != expected {
+			t.Errorf("%s: expected error %q, got %v", text, expected, err)
+		}
+	}
+
+	if _, _, err := parser.ParseString("states\ns1\ntransitions\nlabels\np: s1\nformulas\nMU mu X. p AND NOT X\n"); err == nil || err.Error() != "7: variable X occurs below an odd number of negations" {
+		t.Errorf("expected the formula to be rejected as not monotone, got %v", err)
+	}
+}
+
+// TestMuCalculusAgainstCTL checks the translation of random CTL formulas, including models with deadlocks
+func TestMuCalculusAgainstCTL(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	for seed := int64(0); seed < 20; seed++ {
+		o := generator.DefaultOptions()
+		o.States = 7
+		o.MinDegree = 0
+		o.MaxDegree = 2
+		o.Seed = seed
+		ks, err := generator.RandomKripkeStructure(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 20; i++ {
+			text, _ := randomCTL(r, 1+r.Intn(4), o.LabelNames())
+			node, err := parser.ParseNode(text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := parser.ParseFormula(ks, text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			translated, err := mucalculus.FromCTL(node)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := mucalculus.MakeMuFormula(ks, translated)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !actual.Check().Equals(expected.Check()) {
+				t.Errorf("seed %d: %s gives %s, but %s gives %s", seed, actual.String(), actual.Check().String(), text, expected.Check().String())
+			}
+		}
+	}
+}
+
+// TestMuCalculusBounded checks the unrolling of bounded operators, and that past and cost-bounded operators are
+// rejected
+func TestMuCalculusBounded(t *testing.T) {
+	syntax := generator.CTLSyntax.With("EF<=2", "EF<=2 %s").With("AF[1,3]", "AF[1,3] %s").With("EG[2,2]", "EG[2,2] %s").
+		With("AG<=1", "AG<=1 %s").With("EU[1,2]", "E[%s U[1,2] %s]").With("AU<=3", "A[%s U<=3 %s]")
+	r := rand.New(rand.NewSource(8))
+	for seed := int64(0); seed < 20; seed++ {
+		o := generator.DefaultOptions()
+		o.States = 7
+		o.MinDegree = 0
+		o.MaxDegree = 2
+		o.Seed = seed
+		ks, err := generator.RandomKripkeStructure(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 20; i++ {
+			text := generator.Generate(r, generator.FormulaOptions{Depth: 1 + r.Intn(3), Syntax: syntax, Labels: o.LabelNames()}).Format(syntax)
+			node, err := parser.ParseNode(text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := parser.ParseFormula(ks, text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			translated, err := mucalculus.FromCTL(node)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := mucalculus.MakeMuFormula(ks, translated)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !actual.Check().Equals(expected.Check()) {
+				t.Errorf("seed %d: %s gives %s, but %s gives %s", seed, actual.String(), actual.Check().String(), text, expected.Check().String())
+			}
+		}
+	}
+
+	for text, expected := range map[string]string{
+		"EY p":                    "unsupported operator EY: the mu-calculus has no past modalities",
+		"AG (q IMPLIES E[p S q])": "unsupported operator ES: the mu-calculus has no past modalities",
+		"EF[cost<=3] p":           "unsupported operator EF[cost<=3]: the mu-calculus has no weights",
+		"AF[5,1000000000] p":      "unsupported operator AF[5,1000000000]: only bounds up to 1000 are unrolled",
+	} {
+		node, err := parser.ParseNode(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := mucalculus.FromCTL(node); err == nil || err.Error() != expected {
+			t.Errorf("%s: expected error %q, got %v", text, expected, err)
+		}
+	}
+}
+
+// TestMuAlternation compares the alternating fixpoint for "infinitely often p" with CTL* on models without deadlocks
+func TestMuAlternation(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		o := generator.DefaultOptions()
+		o.States = 8
+		o.MinDegree = 1
+		o.MaxDegree = 2
+		o.Seed = seed
+		ks, err := generator.RandomKripkeStructure(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := o.LabelNames()[0]
+		mu, err := parser.ParseFormula(ks, "MU nu X. mu Y. (<> X AND "+p+") OR <> Y")
+		if err != nil {
+			t.Fatal(err)
+		}
+		star, err := parser.ParseFormula(ks, "CTL* E G F "+p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !mu.Check().Equals(star.Check()) {
+			t.Errorf("seed %d: %s gives %s, but %s gives %s", seed, mu.String(), mu.Check().String(), star.String(), star.Check().String())
+		}
+	}
+}
+
+// TestMuEmersonLei uses a model in which reusing the least fixpoint after the enclosing greatest one has shrunk
+// would keep the cycle s1, s2 without p
+func TestMuEmersonLei(t *testing.T) {
+	_, formulas, err := parser.ParseString("states\ns1\ns2\ns3\ns4\ntransitions\ns1 -> s2 -> s1\ns1 -> s3 -> s4 -> s4\nlabels\np: s3\nformulas\n" +
+		"MU nu X. mu Y. (<> X AND p) OR <> Y\nMU mu Y. (<> true AND p) OR <> Y\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []string{"{}", "{s1, s2, s3}"} {
+		if actual := formulas[i].Check().String(); actual != expected {
+			t.Errorf("%s: expected %s, got %s", formulas[i].String(), expected, actual)
+		}
+	}
+}
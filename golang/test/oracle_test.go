@@ -0,0 +1,68 @@
+package test
+
+import (
+	"cav/golang/oracle"
+	"math/rand"
+	"testing"
+)
+
+func TestOracle(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	labels := []string{"p", "q", "r"}
+
+	for i := 0; i < 3000; i++ {
+		m := oracle.RandomModel(r, 6, 3, labels)
+		f := oracle.RandomFormula(r, 4, labels)
+
+		differing, err := oracle.Disagreement(m, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(differing) > 0 {
+			t.Fatalf("Check() disagrees with the oracle, minimized counterexample:\n%s", oracle.MakeCounterexample(m, f).String())
+		}
+	}
+}
+
+func TestOracleMinimize(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	labels := []string{"p", "q"}
+
+	// pretend that Check() is broken for every formula mentioning EG in a model with a p-state
+	failing := func(m *oracle.Model, f *oracle.Formula) bool {
+		var containsEG func(f *oracle.Formula) bool
+		containsEG = func(f *oracle.Formula) bool {
+			if f.Op == oracle.OpEG {
+				return true
+			}
+			for _, sub := range f.Sub {
+				if containsEG(sub) {
+					return true
+				}
+			}
+			return false
+		}
+		for _, holds := range m.Labels["p"] {
+			if holds && containsEG(f) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := 0; i < 100; i++ {
+		m := oracle.RandomModel(r, 6, 3, labels)
+		f := &oracle.Formula{Op: oracle.OpAnd, Sub: []*oracle.Formula{
+			oracle.RandomFormula(r, 3, labels),
+			{Op: oracle.OpEG, Sub: []*oracle.Formula{oracle.RandomFormula(r, 3, labels)}},
+		}}
+		if !failing(m, f) {
+			continue
+		}
+
+		m, f = oracle.Minimize(m, f, failing)
+		if len(m.Edges) != 1 || f.Op != oracle.OpEG || f.Sub[0].Op.Arity() != 0 {
+			t.Errorf("counterexample is not minimal:\n%s", m.String(f))
+		}
+	}
+}
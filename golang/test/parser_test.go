@@ -0,0 +1,138 @@
+package test
+
+import (
+	"cav/golang/parser"
+	"cav/golang/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseString(t *testing.T) {
+	ks, flas, err := parser.ParseString("states\ns1\ns2\ntransitions\ns1 -> s2 -> s2\nlabels\np: s2\nformulas\nEX p\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flas) != 1 || ks.GetStates().Size() != 2 {
+		t.Fatalf("unexpected result: %s, %v", ks.String(), flas)
+	}
+	testFormula(t, flas[0], ks.GetStates())
+
+	fla, err := parser.ParseFormula(ks, "AG NOT p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testFormula(t, fla, cav.MakeSet[cav.IState]())
+
+	if _, err := parser.ParseFormula(ks, "EX unknown"); err == nil {
+		t.Errorf("expected an error for an unknown label")
+	}
+
+	if _, _, err := parser.ParseString("states\ns1\ntransitions\n"); err == nil || err.Error() != "3: unexpected end of file" {
+		t.Errorf("expected an unexpected end of file in line 3, got %v", err)
+	}
+}
+
+func TestParseConcurrently(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "kripkestructure_test.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ks, flas, err := parser.Parse(strings.NewReader(string(data)))
+			if err == nil && (ks.GetStates().Size() != 8 || len(flas) != 20) {
+				err = os.ErrInvalid
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDefinitions(t *testing.T) {
+	model := "states\ns1\ns2\ns3\ntransitions\ns1 -> s2 -> s3 -> s1\nlabels\nreq: s1\ngrant: s3\ncrit1: s2\ncrit2: s2, s3\n" +
+		"definitions\nsafe := NOT (crit1 AND crit2)\nresponse(a, b) := AG (a IMPLIES AF b)\nboth(a) := a AND safe\nformulas\n"
+
+	p := parser.MakeFileParser()
+	ks, flas, err := p.ParseString(model + "safe\nresponse(req, grant)\nresponse(both(crit2), EX req)\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flas) != 3 {
+		t.Fatalf("expected 3 formulas, got %d", len(flas))
+	}
+	if safe := strings.Join(cav.SortedNames(flas[0].Check()), ","); safe != "s1,s3" {
+		t.Errorf("expected safe to hold in s1,s3, got %s", safe)
+	}
+	testFormula(t, flas[1], ks.GetStates())
+	testFormula(t, flas[2], ks.GetStates())
+
+	if expanded := p.GetNodes()[1].String(); expanded != "AG (req IMPLIES AF grant)" {
+		t.Errorf("unexpected expansion: %s", expanded)
+	}
+	if declaration := p.GetSymbols().Definitions["response"]; declaration != (parser.Declaration{Line: 14, Column: 1}) {
+		t.Errorf("expected response to be defined in line 14, column 1, got %+v", declaration)
+	}
+
+	for _, test := range []struct{ definitions, formula, err string }{
+		{"", "response(req)", "17:1: response expects 2 arguments, got 1"},
+		{"", "unknown(req)", "17:1: undefined name: unknown"},
+		{"", "EX unknown", "17:4: unknown label in formula: unknown"},
+		{"loop := EX loop\n", "req", "16:12: recursive definition: loop -> loop"},
+		{"ping := EX pong(req)\npong(a) := a OR ping\n", "req", "17:17: recursive definition: ping -> pong -> ping"},
+		{"other := AF missing\n", "req", "16:13: undefined name in definition other: missing"},
+		{"req := true\n", "req", "16: definition has the same name as a label: req"},
+		{"safe := true\n", "req", "16: duplicate definition: safe"},
+	} {
+		text := strings.Replace(model, "formulas\n", test.definitions+"formulas\n", 1) + test.formula + "\n"
+		if _, _, err := parser.ParseString(text); err == nil || err.Error() != test.err {
+			t.Errorf("expected error %q, got %v", test.err, err)
+		}
+	}
+}
+
+func TestInclude(t *testing.T) {
+	dir := filepath.Join("testdata", "include")
+
+	p := parser.MakeFileParser()
+	ks, flas, err := p.ParseFile(filepath.Join(dir, "properties.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ks.GetStates().Size() != 3 || len(flas) != 2 {
+		t.Fatalf("unexpected result: %s, %v", ks.String(), flas)
+	}
+	for _, expectation := range p.GetExpectations() {
+		if ok, diff := expectation.Verify(); !ok {
+			t.Errorf("%d: %s", expectation.Line, diff)
+		}
+	}
+
+	included, _ := filepath.Abs(filepath.Join(dir, "model", "broken.txt"))
+	if _, _, err := parser.ParseFile(filepath.Join(dir, "broken.txt")); err == nil || err.Error() != included+":3:4: unknown label in formula: unknown" {
+		t.Errorf("expected an unknown label in line 3 of %s, got %v", included, err)
+	}
+
+	if _, _, err := parser.ParseFile(filepath.Join(dir, "cycle.txt")); err == nil || !strings.HasSuffix(err.Error(), "include cycle: cycle.txt -> "+filepath.Join("model", "cycle.txt")+" -> cycle.txt") {
+		t.Errorf("expected an include cycle, got %v", err)
+	}
+
+	if _, _, err := parser.ParseString("include \"missing.txt\"\n"); err == nil || !strings.HasPrefix(err.Error(), "1: failed to include") {
+		t.Errorf("expected a missing include in line 1, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package test
+
+import (
+	"cav/golang/ast"
+	"cav/golang/explain"
+	"cav/golang/generator"
+	"cav/golang/parser"
+	"cav/golang/transform"
+	"cav/golang/types"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestPast(t *testing.T) {
+	// S is only the since operator if it is a word of its own
+	if _, _, err := parser.ParseString("states\ns1\ntransitions\nlabels\nreq: s1\nformulas\nE[Start S req]\n"); err == nil || err.Error() != "7:3: unknown label in formula: Start" {
+		t.Errorf("expected Start to be parsed as a label, got %v", err)
+	}
+	// nor if one of its operands would be empty, so S can still be a label
+	_, labelled, err := parser.ParseString("states\ns1\ns2\ntransitions\ns1 -> s2\nlabels\nS: s1\nq: s2\nformulas\nE[S U q]\nE[S S q]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []string{"{s1, s2}", "{s2}"} {
+		if actual := labelled[i].Check().String(); actual != expected || strings.HasPrefix(labelled[i].String(), "CTL*") {
+			t.Errorf("%s: expected the CTL result %s, got %s", labelled[i].String(), expected, actual)
+		}
+	}
+	ks, formulas, err := parser.ParseString("states\ns1\ns2\ns3\ns4\ntransitions\ns1 -> s2 -> s3 -> s1\ns4 -> s3\nlabels\nreq: s2\ngrant: s3\nformulas\n" +
+		"EY req\nAY req\nE[true S req]\nA[true S req]\nEH NOT req\nAH NOT grant\nAG (grant IMPLIES EY req)\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []string{"{s3}", "{s4}", "{s1, s2, s3}", "{s2}", "{s1, s3, s4}", "{s4}", "{s1, s2, s3, s4}"} {
+		if actual := formulas[i].Check().String(); actual != expected {
+			t.Errorf("%s: expected %s, got %s", formulas[i].String(), expected, actual)
+		}
+	}
+
+	states := map[string]cav.IState{}
+	ks.GetStates().ForEach(func(state cav.IState) {
+		states[state.GetName()] = state
+	})
+	for _, test := range []struct {
+		formula, state, expected string
+	}{
+		{"E[true S req]", "s1", `s1 |= E[true S req]: iteration 2: true holds and predecessor s3 has been reached in iteration 1
+  s1 |= true: true holds everywhere
+  s3 |= E[true S req]: iteration 1: true holds and predecessor s2 has been reached in iteration 0
+    s3 |= true: true holds everywhere
+    s2 |= E[true S req]: iteration 0: req holds
+      s2 |= req: s2 has label req
+`},
+		{"EH NOT req", "s3", `s3 |= EH NOT req: NOT req holds along the backward path s3 -> s4 which ends in s4 without predecessors
+  s3 |= NOT req: negation
+    s3 |/= req: s3 does not have label req
+  s4 |= NOT req: negation
+    s4 |/= req: s4 does not have label req
+`},
+		{"EY grant", "s4", "s4 |/= EY grant: no predecessors\n"},
+	} {
+		node, err := parser.ParseNode(test.formula)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proof, err := explain.Explain(node, states[test.state])
+		if err != nil {
+			t.Fatal(err)
+		}
+		var text strings.Builder
+		proof.WriteText(&text)
+		if text.String() != test.expected {
+			t.Errorf("%s in %s: expected\n%s\ngot\n%s", test.formula, test.state, test.expected, text.String())
+		}
+	}
+}
+
+func randomPast(r *rand.Rand, depth int, labels []string) string {
+	return generator.Generate(r, generator.FormulaOptions{Depth: depth, Syntax: generator.PastSyntax, Labels: labels}).Format(generator.PastSyntax)
+}
+
+// reversed returns the Kripke structure with all transitions turned around, and the states corresponding to each other
+func reversed(ks cav.IKripkeStructure) (cav.IKripkeStructure, map[cav.IState]cav.IState) {
+	result := cav.MakeKripkeStructure()
+	labels := map[cav.ILabel]cav.ILabel{}
+	ks.GetLabels().ForEach(func(label cav.ILabel) {
+		labels[label] = result.NewLabel(label.String())
+	})
+	states := map[cav.IState]cav.IState{}
+	ks.GetStates().ForEach(func(state cav.IState) {
+		states[state] = result.NewState(state.GetName())
+		state.GetLabels().ForEach(func(label cav.ILabel) {
+			states[state].AddLabel(labels[label])
+		})
+	})
+	ks.GetStates().ForEach(func(state cav.IState) {
+		state.GetChildren().ForEach(func(child cav.IState) {
+			states[child].AddChildren(states[state])
+		})
+	})
+	return result, states
+}
+
+// TestPastAgainstFuture compares the past operators to the future ones on the reversed Kripke structure,
+// where EH a also holds along backward paths ending in a state without predecessors
+func TestPastAgainstFuture(t *testing.T) {
+	r := rand.New(rand.NewSource(11))
+	for seed := int64(0); seed < 30; seed++ {
+		o := generator.DefaultOptions()
+		o.States = 6
+		o.MinDegree = int(seed % 2)
+		o.MaxDegree = 2
+		o.Seed = seed
+		ks, err := generator.RandomKripkeStructure(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reverse, states := reversed(ks)
+		names := o.LabelNames()
+
+		for i := 0; i < 10; i++ {
+			a, b := names[r.Intn(len(names))], names[r.Intn(len(names))]
+			for past, future := range map[string]string{
+				"EY " + a:                  "EX " + a,
+				"AY " + a:                  "AX " + a,
+				"E[" + a + " S " + b + "]": "E[" + a + " U " + b + "]",
+				"A[" + a + " S " + b + "]": "A[" + a + " U " + b + "] AND NOT E[NOT " + b + " U (NOT " + b + " AND NOT EX true)]",
+				"EH " + a:                  "EG " + a + " OR E[" + a + " U (" + a + " AND NOT EX true)]",
+				"AH " + a:                  "AG " + a,
+			} {
+				expected, err := parser.ParseFormula(reverse, future)
+				if err != nil {
+					t.Fatal(err)
+				}
+				actual, err := parser.ParseFormula(ks, past)
+				if err != nil {
+					t.Fatal(err)
+				}
+				mapped := cav.MakeSet[cav.IState]()
+				actual.Check().ForEach(func(state cav.IState) {
+					mapped.Add(states[state])
+				})
+				if !mapped.Equals(expected.Check()) {
+					t.Errorf("seed %d: %s gives %s, but %s on the reversed structure gives %s", seed, past, mapped.String(), future, expected.Check().String())
+				}
+			}
+		}
+
+		for i := 0; i < 10; i++ {
+			text := randomPast(r, 1+r.Intn(4), names)
+			node, err := parser.ParseNode(text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fla, _ := ast.Bind(node, ks)
+			for name, transformation := range map[string]func(*ast.Node) *ast.Node{"NNF": transform.NNF, "ENF": transform.ENF, "Simplify": transform.Simplify} {
+				transformed, err := ast.Bind(transformation(node), ks)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !transformed.Check().Equals(fla.Check()) {
+					t.Errorf("seed %d: %s(%s) = %s is not equivalent", seed, name, text, transformed.String())
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package test
+
+import (
+	"cav/golang/generator"
+	"cav/golang/parser"
+	"cav/golang/pctl"
+	"cav/golang/types"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+const markovModel = "states\ns0\ns1\ns2\ns3\ntransitions\ns0 -(0.5)-> s1 -(1/2)-> s3\ns1 -(0.5)-> s0 -(0.5)-> s2\ns2 -(1)-> s2\ns3 -(1)-> s3\nlabels\ngoal: s3\nfail: s2\nformulas\n"
+
+func TestPCTL(t *testing.T) {
+	ks, formulas, err := parser.ParseString(markovModel +
+		"P=? [F goal]\nP>=0.5 [F goal]\nP<0.1 [X goal]\nP>=0.5 [F<=2 goal]\nP>=1 [NOT fail U goal]\n" +
+		"P>=0.3 [X P>=0.5 [F goal]]\nP>0.5 [G NOT goal] AND NOT fail\nPCTL P<=0.25 [F<=2 goal]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []string{"{s0, s1, s3}", "{s1, s3}", "{s0, s2}", "{s1, s3}", "{s3}", "{s0, s1, s3}", "{s0}", "{s0, s2}"} {
+		if actual := formulas[i].Check().String(); actual != expected {
+			t.Errorf("%s: expected %s, got %s", formulas[i].String(), expected, actual)
+		}
+	}
+
+	probabilities := map[string]string{}
+	for state, p := range formulas[0].(*pctl.PCTLFormula).Probabilities() {
+		probabilities[state.GetName()] = fmt.Sprintf("%.6f", p)
+	}
+	if fmt.Sprint(probabilities) != "map[s0:0.333333 s1:0.666667 s2:0.000000 s3:1.000000]" {
+		t.Errorf("unexpected probabilities %v", probabilities)
+	}
+
+	// huge bounds stop once the probabilities have settled at those of the unbounded until
+	start := time.Now()
+	huge, err := parser.ParseFormula(ks, "P=? [F<=1000000000 goal]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unbounded := formulas[0].(*pctl.PCTLFormula).Probabilities()
+	for state, p := range huge.(*pctl.PCTLFormula).Probabilities() {
+		if math.Abs(p-unbounded[state]) > 1e-9 {
+			t.Errorf("%s: expected %f in %s, got %f", huge.String(), unbounded[state], state.GetName(), p)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("%s took %s", huge.String(), elapsed)
+	}
+
+	var sb strings.Builder
+	if err := parser.Write(&sb, ks); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "s0 -(0.5)-> s1\n") {
+		t.Errorf("expected the probabilities to be written:\n%s", sb.String())
+	}
+
+	for text, expected := range map[string]string{
+		"P >= 0.9 [ F p ]":         "P>=0.9 [F p]",
+		"P=? [p U<=3 (q | !p)]":    "P=? [p U<=3 (q OR NOT p)]",
+		"!P<0.1 [X p] -> P>0[G p]": "(NOT P<0.1 [X p] IMPLIES P>0 [G p])",
+	} {
+		if f, err := pctl.Parse(text); err != nil {
+			t.Errorf("%s: %s", text, err.Error())
+		} else if f.String() != expected {
+			t.Errorf("%s: expected %s, got %s", text, expected, f.String())
+		}
+	}
+
+	for model, expected := range map[string]string{
+		markovModel + "P>=1.5 [F goal]\n":                 "15:4: invalid probability: 1.5",
+		markovModel + "P>0 [X P=? [F goal]]\n":            "15: P=? is only allowed as the outermost operator: P=? [F goal]",
+		markovModel + "P>0 [goal]\n":                      "15:10: expected \"U\"",
+		strings.Replace(markovModel, "(1/2)", "(0.4)", 1): "11: invalid Markov chain: probabilities of the transitions from s1 sum to 0.9 instead of 1",
+		strings.Replace(markovModel, "-(1)->", "->", 1):   "11: invalid Markov chain: transition from s2 to s2 has no probability",
+		boundedModel + "P>0 [F q]\n":                      "13: probabilistic formulas need transitions with probabilities",
+	} {
+		if _, _, err := parser.ParseString(model); err == nil || err.Error() != expected {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	}
+}
+
+// randomMarkovChain assigns random probabilities to the transitions of a generated Kripke structure without deadlocks
+func randomMarkovChain(t *testing.T, r *rand.Rand, seed int64) (cav.IKripkeStructure, []string) {
+	o := generator.DefaultOptions()
+	o.States = 6
+	o.MinDegree = 1
+	o.MaxDegree = 3
+	o.Seed = seed
+	ks, err := generator.RandomKripkeStructure(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks.GetStates().ForEach(func(state cav.IState) {
+		weights := map[cav.IState]float64{}
+		sum := 0.0
+		state.GetChildren().ForEach(func(child cav.IState) {
+			weights[child] = 1 + float64(r.Intn(4))
+			sum += weights[child]
+		})
+		for child, weight := range weights {
+			state.AddProbabilisticChild(child, weight/sum)
+		}
+	})
+	return ks, o.LabelNames()
+}
+
+// TestPCTLAgainstCTL compares the qualitative probabilistic operators to CTL and the solution of the equations
+// for the unbounded until to the bounded until with many steps
+func TestPCTLAgainstCTL(t *testing.T) {
+	r := rand.New(rand.NewSource(19))
+	for seed := int64(0); seed < 30; seed++ {
+		ks, names := randomMarkovChain(t, r, seed)
+		if err := cav.CheckMarkovChain(ks); err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 5; i++ {
+			a, b := names[r.Intn(len(names))], names[r.Intn(len(names))]
+			for probabilistic, ctl := range map[string]string{
+				fmt.Sprintf("P>0 [X %s]", a):          "EX " + a,
+				fmt.Sprintf("P>=1 [X %s]", a):         "AX " + a,
+				fmt.Sprintf("P>0 [%s U %s]", a, b):    fmt.Sprintf("E[%s U %s]", a, b),
+				fmt.Sprintf("P>0 [F<=2 %s]", a):       fmt.Sprintf("%s OR EX %s OR EX EX %s", a, a, a),
+				fmt.Sprintf("P<1 [G %s]", a):          "EF NOT " + a,
+				fmt.Sprintf("P>=1 [G<=1 %s]", a):      fmt.Sprintf("%s AND AX %s", a, a),
+				fmt.Sprintf("P=? [%s U %s]", a, b):    fmt.Sprintf("E[%s U %s]", a, b),
+				fmt.Sprintf("NOT P<=0 [F %s]", b):     "EF " + b,
+				fmt.Sprintf("P>=0.5 [true U %s]", b):  fmt.Sprintf("PCTL P>=0.5 [F %s]", b),
+				fmt.Sprintf("P<0.5 [F<=3 NOT %s]", b): fmt.Sprintf("PCTL P>0.5 [G<=3 %s]", b),
+			} {
+				actual, err := parser.ParseFormula(ks, probabilistic)
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected, err := parser.ParseFormula(ks, ctl)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !actual.Check().Equals(expected.Check()) {
+					t.Errorf("seed %d: %s gives %s, but %s gives %s", seed, probabilistic, actual.Check().String(), ctl, expected.Check().String())
+				}
+			}
+
+			unbounded, _ := parser.ParseFormula(ks, fmt.Sprintf("P=? [%s U %s]", a, b))
+			bounded, _ := parser.ParseFormula(ks, fmt.Sprintf("P=? [%s U<=2000 %s]", a, b))
+			limit := bounded.(*pctl.PCTLFormula).Probabilities()
+			for state, p := range unbounded.(*pctl.PCTLFormula).Probabilities() {
+				if math.Abs(p-limit[state]) > 1e-6 {
+					t.Errorf("seed %d: probability %g of %s in %s differs from %g within 2000 steps", seed, p, unbounded.String(), state.GetName(), limit[state])
+				}
+			}
+		}
+	}
+}
+
+func TestRewards(t *testing.T) {
+	model := strings.Replace(markovModel, "formulas\n", "rewards\ns0: 1\ns1 -> s0: 2\ns1: 0.5\nformulas\n", 1)
+	ks, formulas, err := parser.ParseString(model + "R=? [F goal OR fail]\nR=? [F goal]\nR<=2 [C<=2]\nR>1.5 [F fail] OR goal\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []string{"{s0, s1, s2, s3}", "{s3}", "{s0, s1, s2, s3}", "{s0, s1, s3}"} {
+		if actual := formulas[i].Check().String(); actual != expected {
+			t.Errorf("%s: expected %s, got %s", formulas[i].String(), expected, actual)
+		}
+	}
+	for i, expected := range []string{
+		"map[s0:2.333333 s1:2.666667 s2:0 s3:0]",
+		"map[s0:+Inf s1:+Inf s2:+Inf s3:0]",
+		"map[s0:1.75 s1:2 s2:0 s3:0]",
+	} {
+		rewards := map[string]float64{}
+		for state, r := range formulas[i].(*pctl.PCTLFormula).Rewards() {
+			rewards[state.GetName()] = math.Round(r*1e6) / 1e6
+		}
+		if fmt.Sprint(rewards) != expected {
+			t.Errorf("%s: expected %s, got %v", formulas[i].String(), expected, rewards)
+		}
+	}
+
+	// the cumulative rewards stop growing once s2 and s3 are reached, and grow by 1 per step in s3 with a reward
+	start := time.Now()
+	cumulative, err := parser.ParseFormula(ks, "R=? [C<=1000000]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewards := map[string]float64{}
+	for state, r := range cumulative.(*pctl.PCTLFormula).Rewards() {
+		rewards[state.GetName()] = math.Round(r*1e6) / 1e6
+	}
+	if fmt.Sprint(rewards) != "map[s0:2.333333 s1:2.666667 s2:0 s3:0]" {
+		t.Errorf("%s: expected the rewards of F goal OR fail, got %v", cumulative.String(), rewards)
+	}
+	absorbing, formulas, err := parser.ParseString(strings.Replace(model, "s1: 0.5\n", "s1: 0.5\ns3: 1\n", 1) + "R=? [C<=1000000]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for state, r := range formulas[0].(*pctl.PCTLFormula).Rewards() {
+		if state.GetName() == "s3" && r != 1000000 {
+			t.Errorf("%s: expected 1000000 in s3, got %f", formulas[0].String(), r)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cumulative rewards of 1000000 steps took %s", elapsed)
+	}
+	if _, err := parser.ParseFormula(absorbing, "R=? [C<=1000001]"); err == nil || err.Error() != "1: C is limited to 1000000 steps: R=? [C<=1000001]" {
+		t.Errorf("expected the bound to be rejected, got %v", err)
+	}
+
+	var sb strings.Builder
+	if err := parser.Write(&sb, ks); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "\nrewards\ns0: 1\ns1: 0.5\ns1 -> s0: 2\n") {
+		t.Errorf("expected the rewards to be written:\n%s", sb.String())
+	}
+
+	for rewards, expected := range map[string]string{
+		"s1 -> s2: 1\n": "15: reward for a missing transition: s1 -> s2",
+		"s1: -1\n":      "15: invalid reward: -1",
+		"s0: 1\ns0: 2":  "16: duplicate reward: s0",
+		"s9: 1\n":       "15: unknown state for reward: s9",
+	} {
+		model := strings.Replace(markovModel, "formulas\n", "rewards\n"+rewards+"\nformulas\n", 1)
+		if _, _, err := parser.ParseString(model); err == nil || err.Error() != expected {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	}
+}
+
+// TestRewardsAgainstProbabilities checks that the expected reward until a holds is infinite exactly where a is not
+// reached almost surely, and satisfies the equations of the expected number of steps elsewhere
+func TestRewardsAgainstProbabilities(t *testing.T) {
+	r := rand.New(rand.NewSource(23))
+	for seed := int64(0); seed < 30; seed++ {
+		ks, names := randomMarkovChain(t, r, seed)
+		ks.GetStates().ForEach(func(state cav.IState) {
+			state.SetReward(1)
+		})
+		a := names[r.Intn(len(names))]
+
+		reward, _ := parser.ParseFormula(ks, fmt.Sprintf("R=? [F %s]", a))
+		almostSure, _ := parser.ParseFormula(ks, fmt.Sprintf("P>=1 [F %s]", a))
+		target, _ := parser.ParseFormula(ks, a)
+		rewards := reward.(*pctl.PCTLFormula).Rewards()
+		for state, actual := range rewards {
+			if math.IsInf(actual, 1) == almostSure.Check().Contains(state) {
+				t.Errorf("seed %d: reward %g of %s in %s contradicts %s", seed, actual, reward.String(), state.GetName(), almostSure.String())
+				continue
+			}
+			expected := 0.0
+			if !math.IsInf(actual, 1) && !target.Check().Contains(state) {
+				expected = 1
+				state.GetChildren().ForEach(func(child cav.IState) {
+					expected += state.GetProbability(child) * rewards[child]
+				})
+			}
+			if !math.IsInf(actual, 1) && math.Abs(actual-expected) > 1e-6 {
+				t.Errorf("seed %d: reward %g of %s in %s differs from %g", seed, actual, reward.String(), state.GetName(), expected)
+			}
+		}
+
+		cumulative, _ := parser.ParseFormula(ks, "R=? [C<=7]")
+		for state, actual := range cumulative.(*pctl.PCTLFormula).Rewards() {
+			if math.Abs(actual-7) > 1e-9 {
+				t.Errorf("seed %d: reward %g of %s in %s should be 7", seed, actual, cumulative.String(), state.GetName())
+			}
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"cav/golang/parser"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegressionFile(t *testing.T) {
+	p := parser.MakeFileParser()
+	_, _, err := p.ParseFile(filepath.Join("..", "..", "kripkestructure_regression.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectations := p.GetExpectations()
+	if len(expectations) != 20 {
+		t.Errorf("expected 20 expectations, got %d", len(expectations))
+	}
+	for _, expectation := range expectations {
+		if ok, diff := expectation.Verify(); !ok {
+			t.Errorf("%d: %s: %s", expectation.Line, expectation.Formula.String(), diff)
+		}
+	}
+}
+
+func TestRegressionDiff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.txt")
+	os.WriteFile(path, []byte("states\ns1\ns2\ntransitions\ns1 -> s2 -> s2\nlabels\np: s2\nformulas\nEX p == {s1}\np : holds\np : fails\nEX p : verdict\n"), 0644)
+
+	p := parser.MakeFileParser()
+	if _, _, err := p.ParseFile(path); err == nil || !strings.HasPrefix(err.Error(), "12: ") {
+		t.Fatalf("expected an invalid verdict in line 12, got %v", err)
+	}
+
+	os.WriteFile(path, []byte("states\ns1\ns2\ntransitions\ns1 -> s2 -> s2\nlabels\np: s2\nformulas\nEX p == {s1}\np : holds\np : fails\n"), 0644)
+	if _, _, err := p.ParseFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	expectations := p.GetExpectations()
+	if ok, diff := expectations[0].Verify(); ok || !strings.Contains(diff, "unexpected: {s2}") {
+		t.Errorf("expected s2 to be reported as unexpected, got %q", diff)
+	}
+	if ok, diff := expectations[1].Verify(); ok || !strings.Contains(diff, "fails in {s1}") {
+		t.Errorf("expected p to fail in s1, got %q", diff)
+	}
+	if ok, _ := expectations[2].Verify(); !ok {
+		t.Errorf("expected p to fail")
+	}
+}
@@ -0,0 +1,118 @@
+package test
+
+import (
+	"bytes"
+	"cav/golang/server"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func postJSON(t *testing.T, url string, request any, response any) int {
+	body, _ := json.Marshal(request)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode
+}
+
+func TestServer(t *testing.T) {
+	ts := httptest.NewServer(server.MakeServer(4))
+	defer ts.Close()
+
+	text, err := os.ReadFile(filepath.Join("..", "..", "kripkestructure_report.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	model, _ := json.Marshal(string(text))
+
+	var checked server.CheckResponse
+	status := postJSON(t, ts.URL+"/check", server.CheckRequest{
+		Format:   server.FormatText,
+		Model:    model,
+		Formulas: []string{"EF q"},
+	}, &checked)
+	if status != http.StatusOK || len(checked.Results) != 4 {
+		t.Fatalf("unexpected response %d: %v", status, checked)
+	}
+	if !slices.Equal(checked.Results[2].Satisfying, []string{"s1", "s2", "s3", "s5", "s6", "s7"}) {
+		t.Errorf("E[p U q] is invalid: got %v", checked.Results[2].Satisfying)
+	}
+	if !checked.Results[3].Holds {
+		t.Errorf("EF q should hold, violating: %v", checked.Results[3].Violating)
+	}
+
+	// follow-up queries only need the model id
+	var cached server.CheckResponse
+	status = postJSON(t, ts.URL+"/check", server.CheckRequest{
+		ModelID:  checked.ModelID,
+		Formulas: []string{"EG p"},
+	}, &cached)
+	if status != http.StatusOK || cached.ModelID != checked.ModelID || len(cached.Results) != 4 {
+		t.Fatalf("unexpected response %d: %v", status, cached)
+	}
+	if cached.Results[3].Holds || len(cached.Results[3].Satisfying) != 0 {
+		t.Errorf("EG p is invalid: got %v", cached.Results[3])
+	}
+
+	// the text format is the default, leaving it out refers to the same model
+	var defaulted server.CheckResponse
+	status = postJSON(t, ts.URL+"/check", server.CheckRequest{
+		Model:    model,
+		Formulas: []string{"EF q"},
+	}, &defaulted)
+	if status != http.StatusOK || defaulted.ModelID != checked.ModelID {
+		t.Errorf("expected the model id %s without a format, got %d: %s", checked.ModelID, status, defaulted.ModelID)
+	}
+
+	jsonModel, _ := json.Marshal(server.JSONModel{
+		States:      []string{"s1", "s2"},
+		Initial:     []string{"s1"},
+		Transitions: [][2]string{{"s1", "s2"}, {"s2", "s2"}},
+		Labels:      map[string][]string{"p": {"s2"}},
+	})
+	var jsonChecked server.CheckResponse
+	status = postJSON(t, ts.URL+"/check", server.CheckRequest{
+		Format:   server.FormatJSON,
+		Model:    jsonModel,
+		Formulas: []string{"AX p", "p", "LTL G p"},
+	}, &jsonChecked)
+	if status != http.StatusOK || len(jsonChecked.Results) != 3 {
+		t.Fatalf("unexpected response %d: %v", status, jsonChecked)
+	}
+	if !jsonChecked.Results[0].Holds || jsonChecked.Results[1].Holds {
+		t.Errorf("verdicts are invalid: got %v", jsonChecked.Results)
+	}
+	if !slices.Equal(jsonChecked.Results[1].Violating, []string{"s1"}) {
+		t.Errorf("p should only be violated by s1: got %v", jsonChecked.Results[1].Violating)
+	}
+	if trace := jsonChecked.Results[0].Trace; trace != nil {
+		t.Errorf("AX p holds and should have no trace, got %v", trace)
+	}
+	if trace := jsonChecked.Results[1].Trace; trace == nil || trace.State != "s1" || trace.Proof == nil || trace.Proof.Holds ||
+		trace.Proof.Evidence != "s1 does not have label p" {
+		t.Errorf("expected a proof that p does not hold in s1, got %v", trace)
+	}
+	if trace := jsonChecked.Results[2].Trace; trace == nil || trace.State != "s1" || !slices.Equal(trace.Prefix, []string{"s1"}) ||
+		!slices.Equal(trace.Cycle, []string{"s2"}) || trace.Proof != nil {
+		t.Errorf("expected the counterexample s1 -> (s2)^ω, got %v", trace)
+	}
+
+	var failed server.ErrorResponse
+	status = postJSON(t, ts.URL+"/check", server.CheckRequest{
+		ModelID:  checked.ModelID,
+		Formulas: []string{"EX unknown"},
+	}, &failed)
+	if status != http.StatusBadRequest || failed.Error == "" {
+		t.Errorf("expected an error for an unknown label, got %d: %v", status, failed)
+	}
+}
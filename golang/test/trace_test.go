@@ -0,0 +1,44 @@
+package test
+
+import (
+	"cav/golang/parser"
+	"cav/golang/trace"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	ks, _, err := parser.ParseFile(filepath.Join("..", "..", "kripkestructure_test.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := trace.MakeRecorder()
+	ks.SetTracer(recorder)
+	fla, err := parser.ParseFormula(ks, "E[p U q] AND EG p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fla.Check()
+	ks.SetTracer(nil)
+
+	expected := []string{
+		"Z0 = {}, Z1 = {s5}, Z2 = {s5, s6}, Z3 = {s5, s6, s7}, Z4 = {s2, s5, s6, s7}, Z5 = {s1, s2, s3, s5, s6, s7}, Z6 = {s1, s2, s3, s5, s6, s7}",
+		"Z0 = {s1, s2, s3, s4, s5, s6, s7, s8}, Z1 = {s1, s2, s3, s6, s7, s8}, Z2 = {s1, s2, s3, s7}, Z3 = {s1, s2, s3}, Z4 = {s1, s3}, Z5 = {}, Z6 = {}",
+	}
+	if len(recorder.Fixpoints) != len(expected) {
+		t.Fatalf("expected %d fixpoints, got %d", len(expected), len(recorder.Fixpoints))
+	}
+	for i, fixpoint := range recorder.Fixpoints {
+		if fixpoint.String() != expected[i] {
+			t.Errorf("%s: expected %s, got %s", fixpoint.Formula.String(), expected[i], fixpoint.String())
+		}
+	}
+
+	var dot strings.Builder
+	recorder.Fixpoints[0].WriteDOT(&dot, 1)
+	if !strings.Contains(dot.String(), "\"s5\" [fillcolor=lightblue];") || !strings.Contains(dot.String(), "\"s6\" [fillcolor=white];") {
+		t.Errorf("unexpected DOT frame:\n%s", dot.String())
+	}
+}
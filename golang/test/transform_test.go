@@ -0,0 +1,151 @@
+package test
+
+import (
+	"cav/golang/ast"
+	"cav/golang/generator"
+	"cav/golang/parser"
+	"cav/golang/transform"
+	"cav/golang/types"
+	"math/rand"
+	"testing"
+)
+
+// nonENFKinds may not occur in the existential normal form
+var nonENFKinds = []ast.Kind{ast.Implies, ast.EF, ast.ER, ast.AX, ast.AG, ast.AF, ast.AU, ast.AR, ast.AY, ast.AH, ast.AS,
+	ast.BoundedEF, ast.BoundedEG, ast.BoundedAF, ast.BoundedAG, ast.BoundedAU, ast.CostAF, ast.CostAG}
+
+func checkENF(t *testing.T, node *ast.Node) {
+	ast.Inspect(transform.ENF(node), func(n *ast.Node) bool {
+		for _, kind := range nonENFKinds {
+			if n.Kind == kind {
+				t.Errorf("ENF of %s contains %s", node.String(), n.String())
+			}
+		}
+		return true
+	})
+}
+
+func TestNormalForms(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for seed := int64(0); seed < 40; seed++ {
+		o := generator.DefaultOptions()
+		o.States = 6
+		// states without successors are where many textbook identities break down
+		o.MinDegree = int(seed % 2)
+		o.MaxDegree = 2
+		o.Seed = seed
+		ks, err := generator.RandomKripkeStructure(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 25; i++ {
+			text := generator.RandomFormula(r, 1+r.Intn(4), o.LabelNames())
+			if i%5 == 0 {
+				text += " IMPLIES " + generator.RandomFormula(r, 1+r.Intn(3), o.LabelNames())
+			}
+			node, err := parser.ParseNode(text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fla, _ := ast.Bind(node, ks)
+			expected := fla.Check()
+
+			for name, transformation := range map[string]func(*ast.Node) *ast.Node{"NNF": transform.NNF, "ENF": transform.ENF, "Simplify": transform.Simplify} {
+				transformed := transformation(node)
+				fla, err := ast.Bind(transformed, ks)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result := fla.Check(); !result.Equals(expected) {
+					t.Fatalf("%s(%s) = %s is not equivalent on seed %d: expected %s but got %s", name, node.String(), transformed.String(), seed, expected.String(), result.String())
+				}
+			}
+
+			ast.Inspect(transform.NNF(node), func(n *ast.Node) bool {
+				if n.Kind == ast.Not && n.Operands[0].Kind != ast.Label {
+					t.Errorf("NNF of %s negates %s", node.String(), n.Operands[0].String())
+				}
+				return true
+			})
+			checkENF(t, node)
+		}
+	}
+}
+
+// TestENFBoundedAndCost checks that the universal bounded and cost-bounded operators are expressed by existential
+// ones, on models with states without successors and with random weights
+func TestENFBoundedAndCost(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	for seed := int64(0); seed < 40; seed++ {
+		o := generator.DefaultOptions()
+		o.States = 6
+		o.MinDegree = int(seed % 2)
+		o.MaxDegree = 2
+		o.Seed = seed
+		ks, err := generator.RandomKripkeStructure(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ks.GetStates().ForEach(func(state cav.IState) {
+			state.GetChildren().ForEach(func(child cav.IState) {
+				state.AddWeightedChild(child, r.Intn(3))
+			})
+		})
+		names := o.LabelNames()
+
+		for i := 0; i < 20; i++ {
+			a, b := ast.MakeLabel(names[r.Intn(len(names))]), ast.MakeLabel(names[r.Intn(len(names))])
+			lower := r.Intn(3)
+			bound := cav.Bound{Lower: lower, Upper: lower + r.Intn(4)}
+			cost := cav.Bound{Upper: r.Intn(5)}
+			for _, node := range []*ast.Node{
+				ast.MakeBounded(ast.BoundedEF, bound, a),
+				ast.MakeBounded(ast.BoundedAF, bound, a),
+				ast.MakeBounded(ast.BoundedEG, bound, a),
+				ast.MakeBounded(ast.BoundedAG, bound, a),
+				ast.MakeBounded(ast.BoundedAU, bound, a, b),
+				ast.MakeNode(ast.Not, ast.MakeBounded(ast.BoundedAU, bound, a, ast.MakeNode(ast.Not, b))),
+				ast.MakeBounded(ast.CostAF, cost, a),
+				ast.MakeBounded(ast.CostEG, cost, a),
+				ast.MakeBounded(ast.CostAG, cost, a),
+			} {
+				expected, _ := ast.Bind(node, ks)
+				enf := transform.ENF(node)
+				actual, err := ast.Bind(enf, ks)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !actual.Check().Equals(expected.Check()) {
+					t.Fatalf("ENF(%s) = %s is not equivalent on seed %d: expected %s but got %s", node.String(), enf.String(), seed, expected.Check().String(), actual.Check().String())
+				}
+				checkENF(t, node)
+			}
+		}
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	for input, expected := range map[string]string{
+		"NOT NOT p":              "p",
+		"p AND true":             "p",
+		"false OR (p AND NOT p)": "false",
+		"p OR (p AND q)":         "p",
+		"(q OR p) AND p":         "p",
+		"AG AG p":                "AG p",
+		"EF EF p":                "EF p",
+		"E[true U (q OR q)]":     "EF q",
+		"A[false R p]":           "AG p",
+		"EX false OR AX true":    "true",
+		"EG true":                "EG true",
+	} {
+		node, err := parser.ParseNode(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if simplified := transform.Simplify(node).String(); simplified != expected {
+			t.Errorf("Simplify(%s) = %s, expected %s", input, simplified, expected)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package test
+
+import (
+	"cav/golang/parser"
+	"cav/golang/vacuity"
+	"testing"
+)
+
+func TestVacuity(t *testing.T) {
+	p := parser.MakeFileParser()
+	ks, _, err := p.ParseString("states\ns1\ns2\ntransitions\ns1 -> s2 -> s1\nlabels\nreq:\ngrant: s2\nformulas\n" +
+		"AG (req IMPLIES AF grant)\nAG (NOT grant IMPLIES EX grant)\nEF req\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{
+		{"req@10:5 -> AG (true IMPLIES AF grant)", "grant@10:20 -> AG (req IMPLIES AF false)"},
+		{},
+		nil,
+	}
+	for i, node := range p.GetNodes() {
+		occurrences, err := vacuity.Check(node, ks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (occurrences == nil) != (expected[i] == nil) || len(occurrences) != len(expected[i]) {
+			t.Errorf("%s: expected %v, got %v", node.String(), expected[i], occurrences)
+			continue
+		}
+		for j, o := range occurrences {
+			if actual := o.Node.String() + "@" + o.Node.Pos.String() + " -> " + o.Formula.String(); actual != expected[i][j] {
+				t.Errorf("%s: expected %s, got %s", node.String(), expected[i][j], actual)
+			}
+		}
+	}
+}
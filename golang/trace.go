@@ -0,0 +1,72 @@
+package main
+
+import (
+	"cav/golang/parser"
+	"cav/golang/trace"
+	"cav/golang/types"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func traceFixpoints(args []string) int {
+	flags := flag.NewFlagSet("trace", flag.ContinueOnError)
+	text := flags.String("formula", "", "formula to trace instead of those in the file")
+	dot := flags.String("dot", "", "directory to write one Graphviz file per iteration to")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Println("Usage: main trace [-formula <formula>] [-dot <directory>] <file>")
+		return 2
+	}
+
+	ks, formulas, err := parser.ParseFile(flags.Arg(0))
+	if err != nil {
+		fmt.Println("Failed to parse file:")
+		fmt.Println(err)
+		return 1
+	}
+	if *text != "" {
+		formula, err := parser.ParseFormula(ks, *text)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		formulas = []cav.IFormula{formula}
+	}
+	if *dot != "" {
+		if err := os.MkdirAll(*dot, 0755); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+	}
+
+	for k, formula := range formulas {
+		recorder := trace.MakeRecorder()
+		ks.SetTracer(recorder)
+		result := formula.Check()
+		ks.SetTracer(nil)
+
+		fmt.Printf("%s: %s\n", formula.String(), result.String())
+		for j, fixpoint := range recorder.Fixpoints {
+			fmt.Printf("  %s: %s\n", fixpoint.Formula.String(), fixpoint.String())
+			if *dot == "" {
+				continue
+			}
+			for i := range fixpoint.Iterations {
+				// names sort in the order the frames are meant to be shown
+				path := filepath.Join(*dot, fmt.Sprintf("formula%03d_fixpoint%03d_z%03d.dot", k, j, i))
+				file, err := os.Create(path)
+				if err != nil {
+					fmt.Println(err)
+					return 1
+				}
+				fixpoint.WriteDOT(file, i)
+				file.Close()
+			}
+		}
+	}
+	return 0
+}
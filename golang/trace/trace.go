@@ -0,0 +1,70 @@
+package trace
+
+import (
+	"cav/golang/types"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Fixpoint is the sequence of Z-sets a single EU or EG computation went through
+type Fixpoint struct {
+	Formula    cav.IFormula
+	Iterations []cav.ISet[cav.IState]
+}
+
+// String returns the iterations like "Z0 = {}, Z1 = {s5}, Z2 = {s5}"
+func (f *Fixpoint) String() string {
+	iterations := make([]string, len(f.Iterations))
+	for i, z := range f.Iterations {
+		iterations[i] = fmt.Sprintf("Z%d = %s", i, z.String())
+	}
+	return strings.Join(iterations, ", ")
+}
+
+// WriteDOT writes the Kripke structure as a frame of the animation of the fixpoint, highlighting the states in Zi
+func (f *Fixpoint) WriteDOT(w io.Writer, i int) {
+	escape := strings.NewReplacer("\\", "\\\\", "\"", "\\\"")
+	ks := f.Formula.GetKripkeStructure()
+	z := f.Iterations[i]
+
+	fmt.Fprintln(w, "digraph fixpoint {")
+	fmt.Fprintf(w, "  label=\"%s: Z%d\";\n", escape.Replace(f.Formula.String()), i)
+	fmt.Fprintln(w, "  node [style=filled];")
+	states := map[string]cav.IState{}
+	ks.GetStates().ForEach(func(state cav.IState) {
+		states[state.GetName()] = state
+	})
+	names := cav.SortedNames(ks.GetStates())
+	for _, name := range names {
+		color := "white"
+		if z.Contains(states[name]) {
+			color = "lightblue"
+		}
+		fmt.Fprintf(w, "  \"%s\" [fillcolor=%s];\n", escape.Replace(name), color)
+	}
+	for _, name := range names {
+		for _, child := range cav.SortedNames(states[name].GetChildren()) {
+			fmt.Fprintf(w, "  \"%s\" -> \"%s\";\n", escape.Replace(name), escape.Replace(child))
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// Recorder is a tracer collecting all fixpoint computations in the order they have been started
+type Recorder struct {
+	Fixpoints []*Fixpoint
+}
+
+func (r *Recorder) Iteration(formula cav.IFormula, i int, z cav.ISet[cav.IState]) {
+	// subformulas are checked before the fixpoint of a formula starts, so iterations always belong to the latest one
+	if i == 0 {
+		r.Fixpoints = append(r.Fixpoints, &Fixpoint{formula, make([]cav.ISet[cav.IState], 0)})
+	}
+	current := r.Fixpoints[len(r.Fixpoints)-1]
+	current.Iterations = append(current.Iterations, z.Copy())
+}
+
+func MakeRecorder() *Recorder {
+	return &Recorder{make([]*Fixpoint, 0)}
+}
@@ -0,0 +1,184 @@
+package transform
+
+import (
+	"cav/golang/ast"
+)
+
+func isKind(n *ast.Node, kind ast.Kind) bool {
+	return n.Kind == kind
+}
+
+// isNegationOf reports whether a is NOT b or b is NOT a
+func isNegationOf(a *ast.Node, b *ast.Node) bool {
+	return (a.Kind == ast.Not && a.Operands[0].Equal(b)) || (b.Kind == ast.Not && b.Operands[0].Equal(a))
+}
+
+// absorbs reports whether a AND (a OR x) respectively a OR (a AND x) equals a, kind being the inner connective
+func absorbs(a *ast.Node, b *ast.Node, kind ast.Kind) bool {
+	return b.Kind == kind && (b.Operands[0].Equal(a) || b.Operands[1].Equal(a))
+}
+
+// Simplify applies equivalences which make the formula smaller until none of them applies anymore.
+// Only identities which also hold in states without successors are used, e.g. EG true is left as it is.
+func Simplify(n *ast.Node) *ast.Node {
+	for {
+		simplified := ast.Rewrite(n, ast.RewriterFunc(simplify))
+		if simplified.Equal(n) {
+			return simplified
+		}
+		n = simplified
+	}
+}
+
+func simplify(n *ast.Node) *ast.Node {
+	t := ast.MakeNode(ast.True)
+	f := ast.MakeNode(ast.False)
+	var a, b *ast.Node
+	if len(n.Operands) > 0 {
+		a = n.Operands[0]
+	}
+	if len(n.Operands) > 1 {
+		b = n.Operands[1]
+	}
+
+	switch n.Kind {
+	case ast.Not:
+		switch {
+		case isKind(a, ast.Not):
+			return a.Operands[0]
+		case isKind(a, ast.True):
+			return f
+		case isKind(a, ast.False):
+			return t
+		}
+	case ast.And:
+		switch {
+		case isKind(a, ast.True):
+			return b
+		case isKind(b, ast.True):
+			return a
+		case isKind(a, ast.False), isKind(b, ast.False), isNegationOf(a, b):
+			return f
+		case a.Equal(b), absorbs(a, b, ast.Or):
+			return a
+		case absorbs(b, a, ast.Or):
+			return b
+		}
+	case ast.Or:
+		switch {
+		case isKind(a, ast.False):
+			return b
+		case isKind(b, ast.False):
+			return a
+		case isKind(a, ast.True), isKind(b, ast.True), isNegationOf(a, b):
+			return t
+		case a.Equal(b), absorbs(a, b, ast.And):
+			return a
+		case absorbs(b, a, ast.And):
+			return b
+		}
+	case ast.Implies:
+		switch {
+		case isKind(a, ast.True):
+			return b
+		case isKind(a, ast.False), isKind(b, ast.True), a.Equal(b):
+			return t
+		case isKind(b, ast.False):
+			return withPos(not(a), n)
+		}
+	case ast.EX:
+		if isKind(a, ast.False) {
+			return f
+		}
+	case ast.AX:
+		if isKind(a, ast.True) {
+			return t
+		}
+	case ast.EY:
+		if isKind(a, ast.False) {
+			return f
+		}
+	case ast.AY:
+		if isKind(a, ast.True) {
+			return t
+		}
+	case ast.EG:
+		switch {
+		case isKind(a, ast.False):
+			return f
+		case isKind(a, ast.EG):
+			return a
+		}
+	case ast.AF:
+		switch {
+		case isKind(a, ast.True):
+			return t
+		case isKind(a, ast.AF):
+			return a
+		}
+	case ast.EF:
+		switch {
+		case isKind(a, ast.True), isKind(a, ast.False):
+			return a
+		case isKind(a, ast.EF):
+			return a
+		}
+	case ast.AG:
+		switch {
+		case isKind(a, ast.True), isKind(a, ast.False):
+			return a
+		case isKind(a, ast.AG):
+			return a
+		}
+	case ast.EU:
+		switch {
+		case a.Equal(b):
+			return b
+		case isKind(b, ast.True), isKind(b, ast.False):
+			return b
+		case isKind(a, ast.False):
+			return b
+		case isKind(a, ast.True):
+			return withPos(ast.MakeNode(ast.EF, b), n)
+		}
+	case ast.AU:
+		switch {
+		case a.Equal(b):
+			return b
+		case isKind(b, ast.True):
+			return t
+		case isKind(a, ast.False):
+			return b
+		case isKind(a, ast.True):
+			return withPos(ast.MakeNode(ast.AF, b), n)
+		}
+	case ast.ER:
+		switch {
+		case a.Equal(b):
+			return b
+		case isKind(b, ast.False):
+			return f
+		case isKind(a, ast.True):
+			return b
+		case isKind(a, ast.False):
+			return withPos(ast.MakeNode(ast.EG, b), n)
+		}
+	case ast.AR:
+		switch {
+		case a.Equal(b):
+			return b
+		case isKind(b, ast.True), isKind(b, ast.False):
+			return b
+		case isKind(a, ast.True):
+			return b
+		case isKind(a, ast.False):
+			return withPos(ast.MakeNode(ast.AG, b), n)
+		}
+	}
+	return n
+}
+
+func withPos(n *ast.Node, original *ast.Node) *ast.Node {
+	n.Pos = original.Pos
+	return n
+}
@@ -0,0 +1,203 @@
+package transform
+
+import (
+	"cav/golang/ast"
+	"cav/golang/types"
+)
+
+func not(n *ast.Node) *ast.Node {
+	return ast.MakeNode(ast.Not, n)
+}
+
+// NNF converts the formula into negation normal form, in which NOT is only applied to labels,
+// to the past operators EH, AH, ES and AS and to bounded until
+func NNF(n *ast.Node) *ast.Node {
+	return nnf(n, false)
+}
+
+func nnf(n *ast.Node, negated bool) *ast.Node {
+	result := func(kind ast.Kind, dual ast.Kind, operands ...*ast.Node) *ast.Node {
+		if negated {
+			kind = dual
+		}
+		node := ast.MakeNode(kind, operands...)
+		node.Pos = n.Pos
+		node.Bound = n.Bound
+		return node
+	}
+	op := func(i int) *ast.Node {
+		return nnf(n.Operands[i], negated)
+	}
+
+	switch n.Kind {
+	case ast.Label:
+		label := ast.MakeLabel(n.Name)
+		label.Pos = n.Pos
+		if negated {
+			return not(label)
+		}
+		return label
+	case ast.True:
+		return result(ast.True, ast.False)
+	case ast.False:
+		return result(ast.False, ast.True)
+	case ast.Not:
+		return nnf(n.Operands[0], !negated)
+	case ast.And:
+		return result(ast.And, ast.Or, op(0), op(1))
+	case ast.Or:
+		return result(ast.Or, ast.And, op(0), op(1))
+	case ast.Implies:
+		// a IMPLIES b is NOT a OR b
+		return result(ast.Or, ast.And, nnf(n.Operands[0], !negated), op(1))
+	case ast.EX:
+		return result(ast.EX, ast.AX, op(0))
+	case ast.AX:
+		return result(ast.AX, ast.EX, op(0))
+	case ast.EG:
+		return result(ast.EG, ast.AF, op(0))
+	case ast.AF:
+		return result(ast.AF, ast.EG, op(0))
+	case ast.EF:
+		return result(ast.EF, ast.AG, op(0))
+	case ast.AG:
+		return result(ast.AG, ast.EF, op(0))
+	case ast.EU:
+		return result(ast.EU, ast.AR, op(0), op(1))
+	case ast.AR:
+		return result(ast.AR, ast.EU, op(0), op(1))
+	case ast.AU:
+		return result(ast.AU, ast.ER, op(0), op(1))
+	case ast.ER:
+		return result(ast.ER, ast.AU, op(0), op(1))
+	case ast.EY:
+		return result(ast.EY, ast.AY, op(0))
+	case ast.AY:
+		return result(ast.AY, ast.EY, op(0))
+	case ast.BoundedEF:
+		return result(ast.BoundedEF, ast.BoundedAG, op(0))
+	case ast.BoundedAG:
+		return result(ast.BoundedAG, ast.BoundedEF, op(0))
+	case ast.BoundedAF:
+		return result(ast.BoundedAF, ast.BoundedEG, op(0))
+	case ast.BoundedEG:
+		return result(ast.BoundedEG, ast.BoundedAF, op(0))
+	case ast.CostEF:
+		return result(ast.CostEF, ast.CostAG, op(0))
+	case ast.CostAG:
+		return result(ast.CostAG, ast.CostEF, op(0))
+	case ast.CostAF:
+		return result(ast.CostAF, ast.CostEG, op(0))
+	case ast.CostEG:
+		return result(ast.CostEG, ast.CostAF, op(0))
+	case ast.EH, ast.AH, ast.ES, ast.AS, ast.BoundedEU, ast.BoundedAU:
+		// there are no dual operators among these, so the negation stays in front
+		operands := make([]*ast.Node, len(n.Operands))
+		for i, operand := range n.Operands {
+			operands[i] = nnf(operand, false)
+		}
+		node := ast.MakeBounded(n.Kind, n.Bound, operands...)
+		node.Pos = n.Pos
+		if negated {
+			return not(node)
+		}
+		return node
+	}
+	return n.Clone()
+}
+
+// ENF converts the formula into existential normal form, using only boolean connectives, EX, EU and EG,
+// the past operators EY, ES and EH, the bounded EU and the cost-bounded EF and EG
+func ENF(n *ast.Node) *ast.Node {
+	enf := ast.Rewrite(n, ast.RewriterFunc(func(n *ast.Node) *ast.Node {
+		var result *ast.Node
+		switch n.Kind {
+		case ast.Implies:
+			result = ast.MakeNode(ast.Or, not(n.Operands[0]), n.Operands[1])
+		case ast.EF:
+			result = ast.MakeNode(ast.EU, ast.MakeNode(ast.True), n.Operands[0])
+		case ast.ER:
+			// b holds until a AND b releases it, or forever
+			a, b := n.Operands[0], n.Operands[1]
+			result = ast.MakeNode(ast.Or, ast.MakeNode(ast.EU, b, ast.MakeNode(ast.And, a, b)), ast.MakeNode(ast.EG, b))
+		case ast.AX:
+			result = not(ast.MakeNode(ast.EX, not(n.Operands[0])))
+		case ast.AG:
+			result = not(ast.MakeNode(ast.EU, ast.MakeNode(ast.True), not(n.Operands[0])))
+		case ast.AF:
+			result = not(ast.MakeNode(ast.EG, not(n.Operands[0])))
+		case ast.AU:
+			a, b := n.Operands[0], n.Operands[1]
+			result = ast.MakeNode(ast.And,
+				not(ast.MakeNode(ast.EU, not(b), ast.MakeNode(ast.And, not(a), not(b)))),
+				not(ast.MakeNode(ast.EG, not(b))))
+		case ast.AR:
+			result = not(ast.MakeNode(ast.EU, not(n.Operands[0]), not(n.Operands[1])))
+		case ast.AY:
+			result = not(ast.MakeNode(ast.EY, not(n.Operands[0])))
+		case ast.AH:
+			result = not(ast.MakeNode(ast.ES, ast.MakeNode(ast.True), not(n.Operands[0])))
+		case ast.AS:
+			a, b := n.Operands[0], n.Operands[1]
+			result = ast.MakeNode(ast.And,
+				not(ast.MakeNode(ast.ES, not(b), ast.MakeNode(ast.And, not(a), not(b)))),
+				not(ast.MakeNode(ast.EH, not(b))))
+		case ast.BoundedEF:
+			result = ast.MakeBounded(ast.BoundedEU, n.Bound, ast.MakeNode(ast.True), n.Operands[0])
+		case ast.BoundedAF:
+			result = not(boundedEG(n.Bound, not(n.Operands[0])))
+		case ast.BoundedEG:
+			result = boundedEG(n.Bound, n.Operands[0])
+		case ast.BoundedAG:
+			result = not(ast.MakeBounded(ast.BoundedEU, n.Bound, ast.MakeNode(ast.True), not(n.Operands[0])))
+		case ast.BoundedAU:
+			result = not(notBoundedAU(n.Bound, n.Operands[0], n.Operands[1]))
+		case ast.CostAF:
+			result = not(ast.MakeBounded(ast.CostEG, n.Bound, not(n.Operands[0])))
+		case ast.CostAG:
+			result = not(ast.MakeBounded(ast.CostEF, n.Bound, not(n.Operands[0])))
+		default:
+			return n
+		}
+		result.Pos = n.Pos
+		return result
+	}))
+	return eliminateDoubleNegation(enf)
+}
+
+// atStep returns E[true U[k,k] f], which holds if some path reaches a state satisfying f in exactly k steps
+func atStep(k int, f *ast.Node) *ast.Node {
+	if k <= 0 {
+		return f
+	}
+	return ast.MakeBounded(ast.BoundedEU, cav.Bound{Lower: k, Upper: k}, ast.MakeNode(ast.True), f)
+}
+
+// boundedEG returns EG[l,u] a as some path reaching step l and keeping a from there until step u
+func boundedEG(bound cav.Bound, a *ast.Node) *ast.Node {
+	steps := bound.Upper - bound.Lower
+	return atStep(bound.Lower, ast.MakeBounded(ast.BoundedEU, cav.Bound{Lower: steps, Upper: steps}, a, a))
+}
+
+// notBoundedAU returns NOT A[a U[l,u] b] like the unbounded ENF of AU: some path violates a before step l, or
+// reaches step l and from there violates b until a fails too or the upper bound is reached
+func notBoundedAU(bound cav.Bound, a *ast.Node, b *ast.Node) *ast.Node {
+	steps := cav.Bound{Lower: 0, Upper: bound.Upper - bound.Lower}
+	result := atStep(bound.Lower, ast.MakeNode(ast.Or,
+		ast.MakeBounded(ast.BoundedEU, steps, not(b), ast.MakeNode(ast.And, not(a), not(b))),
+		boundedEG(steps, not(b))))
+	if bound.Lower > 0 {
+		early := ast.MakeBounded(ast.BoundedEU, cav.Bound{Lower: 0, Upper: bound.Lower - 1}, ast.MakeNode(ast.True), not(a))
+		result = ast.MakeNode(ast.Or, early, result)
+	}
+	return result
+}
+
+func eliminateDoubleNegation(n *ast.Node) *ast.Node {
+	return ast.Rewrite(n, ast.RewriterFunc(func(n *ast.Node) *ast.Node {
+		if n.Kind == ast.Not && n.Operands[0].Kind == ast.Not {
+			return n.Operands[0].Operands[0]
+		}
+		return n
+	}))
+}
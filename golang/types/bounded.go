@@ -0,0 +1,230 @@
+package cav
+
+import "fmt"
+
+// Bound restricts a temporal operator to the steps Lower to Upper of a path, both included
+type Bound struct {
+	Lower int
+	Upper int
+}
+
+// String returns "<=k" if the bound starts at step 0 and "[a,b]" otherwise
+func (b Bound) String() string {
+	if b.Lower == 0 {
+		return fmt.Sprintf("<=%d", b.Upper)
+	}
+	return fmt.Sprintf("[%d,%d]", b.Lower, b.Upper)
+}
+
+// Shift returns the bound as seen from the next step, the lower bound not going below 0
+func (b Bound) Shift() Bound {
+	return Bound{max(b.Lower-1, 0), b.Upper - 1}
+}
+
+type boundedSubFormula struct {
+	kripkeStructure    IKripkeStructure
+	bound              Bound
+	formula            IFormula
+	equivalenceFormula IFormula
+}
+
+type boundedBiSubFormula struct {
+	kripkeStructure IKripkeStructure
+	bound           Bound
+	formula1        IFormula
+	formula2        IFormula
+}
+
+// checkBoundedUntil computes the states from which formula2 holds at some step within the bound and formula1 before,
+// going back from the upper bound one step at a time. Successors are chosen by pre, which is EX or AX.
+// Huge bounds are cheap: the steps down to the lower bound only grow the result until a fixpoint, which is reached
+// within as many steps as there are states, and the steps below it repeat periodically once a result recurs.
+// The smallest bound giving the same result is returned as well.
+func checkBoundedUntil(ks IKripkeStructure, bound Bound, formula1 IFormula, formula2 IFormula, pre func(ISet[IState]) ISet[IState]) (ISet[IState], Bound) {
+	p := formula1.Check()
+	q := formula2.Check()
+	z := q
+	steps := 0
+	for ; steps < min(bound.Upper-bound.Lower, ks.GetStates().Size()); steps++ {
+		next := p.Intersect(pre(z)).Union(q)
+		if next.Equals(z) {
+			break
+		}
+		z = next
+	}
+
+	history := []ISet[IState]{z}
+	for i := bound.Lower; i > 0; i-- {
+		z = p.Intersect(pre(z))
+		for k, previous := range history {
+			if previous.Equals(z) {
+				lower := k + (i-1)%(len(history)-k)
+				return history[lower], Bound{lower, lower + steps}
+			}
+		}
+		history = append(history, z)
+	}
+	return z, Bound{bound.Lower, bound.Lower + steps}
+}
+
+// IBoundedUntilFormula is a bounded until, which is equivalent to the one with the smallest bound giving the same result
+type IBoundedUntilFormula interface {
+	IFormula
+	ReducedBound() Bound
+}
+
+// BoundedEUFormula holds if along some path formula2 holds within the bound and formula1 at all steps before
+type BoundedEUFormula boundedBiSubFormula
+
+func (f *BoundedEUFormula) Check() ISet[IState] {
+	z, _ := f.check()
+	return z
+}
+
+// ReducedBound returns the smallest bound for which the formula holds in the same states
+func (f *BoundedEUFormula) ReducedBound() Bound {
+	_, bound := f.check()
+	return bound
+}
+
+func (f *BoundedEUFormula) check() (ISet[IState], Bound) {
+	return checkBoundedUntil(f.kripkeStructure, f.bound, f.formula1, f.formula2, func(z ISet[IState]) ISet[IState] {
+		result := MakeSet[IState]()
+		f.kripkeStructure.GetStates().ForEach(func(state IState) {
+			state.GetChildren().ForEach(func(child IState) {
+				if z.Contains(child) {
+					result.Add(state)
+				}
+			})
+		})
+		return result
+	})
+}
+
+func (f *BoundedEUFormula) String() string {
+	return fmt.Sprintf("E[%s U%s %s]", f.formula1.String(), f.bound.String(), f.formula2.String())
+}
+
+func (f *BoundedEUFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
+// BoundedAUFormula holds if along all paths formula2 holds within the bound and formula1 at all steps before.
+// Like AX, it holds in states without successors in which formula1 holds.
+type BoundedAUFormula boundedBiSubFormula
+
+func (f *BoundedAUFormula) Check() ISet[IState] {
+	z, _ := f.check()
+	return z
+}
+
+// ReducedBound returns the smallest bound for which the formula holds in the same states
+func (f *BoundedAUFormula) ReducedBound() Bound {
+	_, bound := f.check()
+	return bound
+}
+
+func (f *BoundedAUFormula) check() (ISet[IState], Bound) {
+	return checkBoundedUntil(f.kripkeStructure, f.bound, f.formula1, f.formula2, func(z ISet[IState]) ISet[IState] {
+		result := MakeSet[IState]()
+		f.kripkeStructure.GetStates().ForEach(func(state IState) {
+			all := true
+			state.GetChildren().ForEach(func(child IState) {
+				if !z.Contains(child) {
+					all = false
+				}
+			})
+			if all {
+				result.Add(state)
+			}
+		})
+		return result
+	})
+}
+
+func (f *BoundedAUFormula) String() string {
+	return fmt.Sprintf("A[%s U%s %s]", f.formula1.String(), f.bound.String(), f.formula2.String())
+}
+
+func (f *BoundedAUFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
+type BoundedEFFormula boundedSubFormula
+
+func (f *BoundedEFFormula) Check() ISet[IState] {
+	return f.equivalenceFormula.Check()
+}
+
+func (f *BoundedEFFormula) String() string {
+	return fmt.Sprintf("EF%s %s", f.bound.String(), f.formula.String())
+}
+
+func (f *BoundedEFFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
+type BoundedAFFormula boundedSubFormula
+
+func (f *BoundedAFFormula) Check() ISet[IState] {
+	return f.equivalenceFormula.Check()
+}
+
+func (f *BoundedAFFormula) String() string {
+	return fmt.Sprintf("AF%s %s", f.bound.String(), f.formula.String())
+}
+
+func (f *BoundedAFFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
+// BoundedEGFormula holds if along some path of at least Upper steps the formula holds at all steps within the bound
+type BoundedEGFormula boundedSubFormula
+
+func (f *BoundedEGFormula) Check() ISet[IState] {
+	return f.equivalenceFormula.Check()
+}
+
+func (f *BoundedEGFormula) String() string {
+	return fmt.Sprintf("EG%s %s", f.bound.String(), f.formula.String())
+}
+
+func (f *BoundedEGFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
+type BoundedAGFormula boundedSubFormula
+
+func (f *BoundedAGFormula) Check() ISet[IState] {
+	return f.equivalenceFormula.Check()
+}
+
+func (f *BoundedAGFormula) String() string {
+	return fmt.Sprintf("AG%s %s", f.bound.String(), f.formula.String())
+}
+
+func (f *BoundedAGFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
+// Distances returns the minimal number of steps from each state to a state satisfying the formula.
+// States from which no such state can be reached are missing.
+func Distances(formula IFormula) map[IState]int {
+	distances := map[IState]int{}
+	queue := make([]IState, 0)
+	formula.Check().ForEach(func(state IState) {
+		distances[state] = 0
+		queue = append(queue, state)
+	})
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		state.GetParents().ForEach(func(parent IState) {
+			if _, ok := distances[parent]; !ok {
+				distances[parent] = distances[state] + 1
+				queue = append(queue, parent)
+			}
+		})
+	}
+	return distances
+}
@@ -0,0 +1,149 @@
+package cav
+
+import (
+	"fmt"
+)
+
+// MinCosts returns for each state the minimal cost of a path to a state satisfying the formula,
+// that is the least c for which EF[cost<=c] holds. States from which no such state can be reached are missing.
+func MinCosts(formula IFormula) map[IState]int {
+	costs := map[IState]int{}
+	formula.Check().ForEach(func(state IState) {
+		costs[state] = 0
+	})
+	// Dijkstra's algorithm along the reversed transitions, picking the next state by a linear scan
+	done := map[IState]bool{}
+	for {
+		var next IState
+		for state, cost := range costs {
+			if !done[state] && (next == nil || cost < costs[next] || (cost == costs[next] && state.GetName() < next.GetName())) {
+				next = state
+			}
+		}
+		if next == nil {
+			return costs
+		}
+		done[next] = true
+		next.GetParents().ForEach(func(parent IState) {
+			cost := costs[next] + parent.GetWeight(next)
+			if old, ok := costs[parent]; !ok || cost < old {
+				costs[parent] = cost
+			}
+		})
+	}
+}
+
+// MaxCosts returns for each state the maximal cost of a path until it reaches a state satisfying the formula,
+// that is the least c for which AF[cost<=c] holds. A path ending in a state without successors counts with the cost
+// spent until then, and states with an infinite path never satisfying the formula are missing.
+func MaxCosts(formula IFormula) map[IState]int {
+	ks := formula.GetKripkeStructure()
+	goal := formula.Check()
+	// states with an infinite path avoiding the goal cannot be bounded by any cost
+	unbounded := ks.MakeEGFormula(ks.MakeNotFormula(formula)).Check()
+
+	costs := map[IState]int{}
+	var visit func(state IState) int
+	visit = func(state IState) int {
+		if cost, ok := costs[state]; ok {
+			return cost
+		}
+		cost := 0
+		if !goal.Contains(state) {
+			// the states visited here have no path back to themselves avoiding the goal, so this terminates
+			state.GetChildren().ForEach(func(child IState) {
+				cost = max(cost, state.GetWeight(child)+visit(child))
+			})
+		}
+		costs[state] = cost
+		return cost
+	}
+	for _, state := range SortedStates(ks.GetStates().Minus(unbounded)) {
+		visit(state)
+	}
+	return costs
+}
+
+// CostEFFormula holds if a state satisfying the formula can be reached at a cost of at most its cost
+type CostEFFormula struct {
+	kripkeStructure IKripkeStructure
+	cost            int
+	formula         IFormula
+}
+
+func (f *CostEFFormula) Check() ISet[IState] {
+	return withinCost(MinCosts(f.formula), f.cost)
+}
+
+func withinCost(costs map[IState]int, bound int) ISet[IState] {
+	result := MakeSet[IState]()
+	for state, cost := range costs {
+		if cost <= bound {
+			result.Add(state)
+		}
+	}
+	return result
+}
+
+func (f *CostEFFormula) String() string {
+	return fmt.Sprintf("EF[cost<=%d] %s", f.cost, f.formula.String())
+}
+
+func (f *CostEFFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
+// CostAFFormula holds if all paths reach a state satisfying the formula at a cost of at most its cost,
+// paths ending in a state without successors before doing so only needing to end within that cost
+type CostAFFormula struct {
+	kripkeStructure IKripkeStructure
+	cost            int
+	formula         IFormula
+}
+
+func (f *CostAFFormula) Check() ISet[IState] {
+	return withinCost(MaxCosts(f.formula), f.cost)
+}
+
+func (f *CostAFFormula) String() string {
+	return fmt.Sprintf("AF[cost<=%d] %s", f.cost, f.formula.String())
+}
+
+func (f *CostAFFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
+type costEquivalencyFormula struct {
+	kripkeStructure    IKripkeStructure
+	cost               int
+	formula            IFormula
+	equivalenceFormula IFormula
+}
+
+type CostEGFormula costEquivalencyFormula
+
+func (f *CostEGFormula) Check() ISet[IState] {
+	return f.equivalenceFormula.Check()
+}
+
+func (f *CostEGFormula) String() string {
+	return fmt.Sprintf("EG[cost<=%d] %s", f.cost, f.formula.String())
+}
+
+func (f *CostEGFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
+type CostAGFormula costEquivalencyFormula
+
+func (f *CostAGFormula) Check() ISet[IState] {
+	return f.equivalenceFormula.Check()
+}
+
+func (f *CostAGFormula) String() string {
+	return fmt.Sprintf("AG[cost<=%d] %s", f.cost, f.formula.String())
+}
+
+func (f *CostAGFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
@@ -8,6 +8,18 @@ type IFormula interface {
 	String() string
 }
 
+// Holds reports whether every initial state of the formula's Kripke structure satisfies it
+func Holds(formula IFormula) bool {
+	satisfying := formula.Check()
+	result := true
+	formula.GetKripkeStructure().GetInitialStates().ForEach(func(state IState) {
+		if !satisfying.Contains(state) {
+			result = false
+		}
+	})
+	return result
+}
+
 type emptyFormula struct {
 	kripkeStructure IKripkeStructure
 }
@@ -130,6 +142,20 @@ func (f *OrFormula) GetKripkeStructure() IKripkeStructure {
 	return f.kripkeStructure
 }
 
+type ImpliesFormula biEquivalencyFormula
+
+func (f *ImpliesFormula) Check() ISet[IState] {
+	return f.equivalenceFormula.Check()
+}
+
+func (f *ImpliesFormula) String() string {
+	return fmt.Sprintf("(%s IMPLIES %s)", f.formula1.String(), f.formula2.String())
+}
+
+func (f *ImpliesFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
 type EXFormula subFormula
 
 func (f *EXFormula) Check() ISet[IState] {
@@ -160,8 +186,9 @@ func (f *EGFormula) Check() ISet[IState] {
 
 	var prevZ ISet[IState]
 	var nextZ ISet[IState] = f.kripkeStructure.GetStates()
+	trace(f, 0, nextZ)
 
-	for !nextZ.Equals(prevZ) {
+	for i := 1; !nextZ.Equals(prevZ); i++ {
 		prevZ = nextZ
 
 		exz := MakeSet[IState]()
@@ -174,6 +201,7 @@ func (f *EGFormula) Check() ISet[IState] {
 		})
 
 		nextZ = p.Intersect(exz)
+		trace(f, i, nextZ)
 	}
 	return prevZ
 }
@@ -208,8 +236,9 @@ func (f *EUFormula) Check() ISet[IState] {
 
 	var prevZ ISet[IState]
 	var nextZ ISet[IState] = MakeSet[IState]()
+	trace(f, 0, nextZ)
 
-	for !nextZ.Equals(prevZ) {
+	for i := 1; !nextZ.Equals(prevZ); i++ {
 		prevZ = nextZ
 
 		exz := MakeSet[IState]()
@@ -222,6 +251,7 @@ func (f *EUFormula) Check() ISet[IState] {
 		})
 
 		nextZ = q.Union(p.Intersect(exz))
+		trace(f, i, nextZ)
 	}
 	return prevZ
 }
@@ -317,3 +347,133 @@ func (f *ARFormula) String() string {
 func (f *ARFormula) GetKripkeStructure() IKripkeStructure {
 	return f.kripkeStructure
 }
+
+// EYFormula holds in states with a predecessor satisfying the formula ("yesterday")
+type EYFormula subFormula
+
+func (f *EYFormula) Check() ISet[IState] {
+	return predecessorsOf(f.kripkeStructure, f.formula.Check())
+}
+
+// predecessorsOf returns the states having a parent in the given set
+func predecessorsOf(ks IKripkeStructure, states ISet[IState]) ISet[IState] {
+	result := MakeSet[IState]()
+	ks.GetStates().ForEach(func(state IState) {
+		state.GetParents().ForEach(func(parent IState) {
+			if states.Contains(parent) {
+				result.Add(state)
+			}
+		})
+	})
+	return result
+}
+
+func (f *EYFormula) String() string {
+	return fmt.Sprintf("EY%s", f.formula.String())
+}
+
+func (f *EYFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
+// EHFormula holds if the formula held all along some backward path ("historically"),
+// which is either infinite or starts in a state without predecessors
+type EHFormula subFormula
+
+func (f *EHFormula) Check() ISet[IState] {
+	p := f.formula.Check()
+	roots := MakeSet[IState]()
+	f.kripkeStructure.GetStates().ForEach(func(state IState) {
+		if state.GetParents().Size() <= 0 {
+			roots.Add(state)
+		}
+	})
+
+	var prevZ ISet[IState]
+	var nextZ ISet[IState] = f.kripkeStructure.GetStates()
+	trace(f, 0, nextZ)
+
+	for i := 1; !nextZ.Equals(prevZ); i++ {
+		prevZ = nextZ
+		nextZ = p.Intersect(predecessorsOf(f.kripkeStructure, prevZ).Union(roots))
+		trace(f, i, nextZ)
+	}
+	return prevZ
+}
+
+func (f *EHFormula) String() string {
+	return fmt.Sprintf("EH%s", f.formula.String())
+}
+
+func (f *EHFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
+// ESFormula holds if along some backward path formula2 held at some point and formula1 ever since ("since")
+type ESFormula biSubFormula
+
+func (f *ESFormula) Check() ISet[IState] {
+	p := f.formula1.Check()
+	q := f.formula2.Check()
+
+	var prevZ ISet[IState]
+	var nextZ ISet[IState] = MakeSet[IState]()
+	trace(f, 0, nextZ)
+
+	for i := 1; !nextZ.Equals(prevZ); i++ {
+		prevZ = nextZ
+		nextZ = q.Union(p.Intersect(predecessorsOf(f.kripkeStructure, prevZ)))
+		trace(f, i, nextZ)
+	}
+	return prevZ
+}
+
+func (f *ESFormula) String() string {
+	return fmt.Sprintf("E[%s S %s]", f.formula1.String(), f.formula2.String())
+}
+
+func (f *ESFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
+type AYFormula equivalencyFormula
+
+func (f *AYFormula) Check() ISet[IState] {
+	return f.equivalenceFormula.Check()
+}
+
+func (f *AYFormula) String() string {
+	return fmt.Sprintf("AY%s", f.formula.String())
+}
+
+func (f *AYFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
+type AHFormula equivalencyFormula
+
+func (f *AHFormula) Check() ISet[IState] {
+	return f.equivalenceFormula.Check()
+}
+
+func (f *AHFormula) String() string {
+	return fmt.Sprintf("AH%s", f.formula.String())
+}
+
+func (f *AHFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
+type ASFormula biEquivalencyFormula
+
+func (f *ASFormula) Check() ISet[IState] {
+	return f.equivalenceFormula.Check()
+}
+
+func (f *ASFormula) String() string {
+	return fmt.Sprintf("A[%s S %s]", f.formula1.String(), f.formula2.String())
+}
+
+func (f *ASFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
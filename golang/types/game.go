@@ -0,0 +1,288 @@
+package cav
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IsGameStructure reports whether any state of the Kripke structure has moves of agents
+func IsGameStructure(ks IKripkeStructure) bool {
+	found := false
+	ks.GetStates().ForEach(func(state IState) {
+		if len(state.GetMoves()) > 0 {
+			found = true
+		}
+	})
+	return found
+}
+
+// CheckGameStructure returns an error unless the Kripke structure is a concurrent game structure: every move has
+// an action for each agent, every state has a move for each combination of the actions its agents can choose,
+// and every transition belongs to a move
+func CheckGameStructure(ks IKripkeStructure) error {
+	agents := ks.GetAgents()
+	if len(agents) <= 0 {
+		return fmt.Errorf("no agents declared")
+	}
+	for _, state := range SortedStates(ks.GetStates()) {
+		moves := state.GetMoves()
+		if len(moves) <= 0 {
+			return fmt.Errorf("state %s has no move", state.GetName())
+		}
+		// the actions each agent can choose, in the order of their first move
+		actions := make([][]string, len(agents))
+		covered := MakeSet[IState]()
+		for _, move := range moves {
+			if len(move) != len(agents) {
+				return fmt.Errorf("move %s in %s needs an action for each of the %d agents", FormatMove(move), state.GetName(), len(agents))
+			}
+			for i, action := range move {
+				if !contains(actions[i], action) {
+					actions[i] = append(actions[i], action)
+				}
+			}
+			covered.Add(state.GetSuccessor(move))
+		}
+		for _, move := range combinations(actions) {
+			if state.GetSuccessor(move) == nil {
+				return fmt.Errorf("move %s is missing in %s", FormatMove(move), state.GetName())
+			}
+		}
+		for _, child := range SortedStates(state.GetChildren().Minus(covered)) {
+			return fmt.Errorf("transition from %s to %s has no move", state.GetName(), child.GetName())
+		}
+	}
+	return nil
+}
+
+// FormatMove returns a move in the syntax of the transitions, like "<req,wait>"
+func FormatMove(move []string) string {
+	return "<" + strings.Join(move, ",") + ">"
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// combinations returns all moves choosing one of the given actions for each agent
+func combinations(actions [][]string) [][]string {
+	result := [][]string{{}}
+	for _, choices := range actions {
+		next := make([][]string, 0, len(result)*len(choices))
+		for _, prefix := range result {
+			for _, action := range choices {
+				next = append(next, append(append([]string{}, prefix...), action))
+			}
+		}
+		result = next
+	}
+	return result
+}
+
+// Strategy gives the actions the agents of a coalition choose in the states from which they win, one action per
+// agent in the order of Agents. It is memoryless, only depending on the current state.
+type Strategy struct {
+	Agents  []string
+	Actions map[IState][]string
+}
+
+// IStrategyFormula is a formula of a coalition of agents, which has a winning strategy in the states satisfying it
+type IStrategyFormula interface {
+	IFormula
+	Strategy() *Strategy
+}
+
+// controllable returns the actions of the coalition forcing the successor into z whatever the other agents choose,
+// the first such ones in the order of the moves, or nil if there are none
+func controllable(state IState, coalition []string, z ISet[IState]) []string {
+	agents := state.GetKripkeStructure().GetAgents()
+	indices := make([]int, 0, len(coalition))
+	for _, agent := range coalition {
+		for i, a := range agents {
+			if a == agent {
+				indices = append(indices, i)
+			}
+		}
+	}
+
+	order := make([]string, 0)
+	choices := map[string][]string{}
+	forcing := map[string]bool{}
+	for _, move := range state.GetMoves() {
+		choice := make([]string, len(indices))
+		for i, index := range indices {
+			choice[i] = move[index]
+		}
+		key := strings.Join(choice, "\x00")
+		if _, ok := forcing[key]; !ok {
+			order = append(order, key)
+			choices[key] = choice
+			forcing[key] = true
+		}
+		forcing[key] = forcing[key] && z.Contains(state.GetSuccessor(move))
+	}
+	for _, key := range order {
+		if forcing[key] {
+			return choices[key]
+		}
+	}
+	return nil
+}
+
+// ControllablePredecessors returns the states in which the coalition can force the successor into z, the analogue
+// of EX for concurrent game structures. The empty coalition has to reach z with every move, and the coalition of
+// all agents with some move.
+func ControllablePredecessors(ks IKripkeStructure, coalition []string, z ISet[IState]) ISet[IState] {
+	result := MakeSet[IState]()
+	ks.GetStates().ForEach(func(state IState) {
+		if controllable(state, coalition, z) != nil {
+			result.Add(state)
+		}
+	})
+	return result
+}
+
+type coalitionFormula struct {
+	kripkeStructure IKripkeStructure
+	coalition       []string
+	formula1        IFormula
+	formula2        IFormula
+}
+
+type coalitionEquivalencyFormula struct {
+	kripkeStructure    IKripkeStructure
+	coalition          []string
+	formula            IFormula
+	equivalenceFormula IFormula
+}
+
+func coalitionString(coalition []string) string {
+	return "<<" + strings.Join(coalition, ", ") + ">>"
+}
+
+type CoalitionXFormula coalitionFormula
+
+func (f *CoalitionXFormula) Check() ISet[IState] {
+	return ControllablePredecessors(f.kripkeStructure, f.coalition, f.formula1.Check())
+}
+
+// Strategy picks actions forcing the next state to satisfy the formula
+func (f *CoalitionXFormula) Strategy() *Strategy {
+	p := f.formula1.Check()
+	strategy := &Strategy{f.coalition, map[IState][]string{}}
+	f.kripkeStructure.GetStates().ForEach(func(state IState) {
+		if actions := controllable(state, f.coalition, p); actions != nil {
+			strategy.Actions[state] = actions
+		}
+	})
+	return strategy
+}
+
+func (f *CoalitionXFormula) String() string {
+	return fmt.Sprintf("%sX%s", coalitionString(f.coalition), f.formula1.String())
+}
+
+func (f *CoalitionXFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
+type CoalitionGFormula coalitionFormula
+
+func (f *CoalitionGFormula) Check() ISet[IState] {
+	p := f.formula1.Check()
+
+	var prevZ ISet[IState]
+	var nextZ ISet[IState] = f.kripkeStructure.GetStates()
+	trace(f, 0, nextZ)
+
+	for i := 1; !nextZ.Equals(prevZ); i++ {
+		prevZ = nextZ
+		nextZ = p.Intersect(ControllablePredecessors(f.kripkeStructure, f.coalition, prevZ))
+		trace(f, i, nextZ)
+	}
+	return prevZ
+}
+
+// Strategy picks actions staying in the states from which the coalition can keep the formula true forever
+func (f *CoalitionGFormula) Strategy() *Strategy {
+	z := f.Check()
+	strategy := &Strategy{f.coalition, map[IState][]string{}}
+	z.ForEach(func(state IState) {
+		strategy.Actions[state] = controllable(state, f.coalition, z)
+	})
+	return strategy
+}
+
+func (f *CoalitionGFormula) String() string {
+	return fmt.Sprintf("%sG%s", coalitionString(f.coalition), f.formula1.String())
+}
+
+func (f *CoalitionGFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
+type CoalitionFFormula coalitionEquivalencyFormula
+
+func (f *CoalitionFFormula) Check() ISet[IState] {
+	return f.equivalenceFormula.Check()
+}
+
+func (f *CoalitionFFormula) Strategy() *Strategy {
+	return f.equivalenceFormula.(IStrategyFormula).Strategy()
+}
+
+func (f *CoalitionFFormula) String() string {
+	return fmt.Sprintf("%sF%s", coalitionString(f.coalition), f.formula.String())
+}
+
+func (f *CoalitionFFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
+
+type CoalitionUFormula coalitionFormula
+
+func (f *CoalitionUFormula) Check() ISet[IState] {
+	z, _ := f.attractor()
+	return z
+}
+
+// attractor computes the least fixpoint of the until like EU does, and a strategy forcing each state added in an
+// iteration into the states of the previous one, which reaches the second formula within as many steps
+func (f *CoalitionUFormula) attractor() (ISet[IState], *Strategy) {
+	p := f.formula1.Check()
+	q := f.formula2.Check()
+	strategy := &Strategy{f.coalition, map[IState][]string{}}
+
+	var prevZ ISet[IState]
+	var nextZ ISet[IState] = MakeSet[IState]()
+	trace(f, 0, nextZ)
+
+	for i := 1; !nextZ.Equals(prevZ); i++ {
+		prevZ = nextZ
+		nextZ = q.Union(p.Intersect(ControllablePredecessors(f.kripkeStructure, f.coalition, prevZ)))
+		nextZ.Minus(prevZ).Minus(q).ForEach(func(state IState) {
+			strategy.Actions[state] = controllable(state, f.coalition, prevZ)
+		})
+		trace(f, i, nextZ)
+	}
+	return prevZ, strategy
+}
+
+// Strategy picks actions making progress towards the second formula while the first one holds
+func (f *CoalitionUFormula) Strategy() *Strategy {
+	_, strategy := f.attractor()
+	return strategy
+}
+
+func (f *CoalitionUFormula) String() string {
+	return fmt.Sprintf("%s[%s U %s]", coalitionString(f.coalition), f.formula1.String(), f.formula2.String())
+}
+
+func (f *CoalitionUFormula) GetKripkeStructure() IKripkeStructure {
+	return f.kripkeStructure
+}
@@ -6,14 +6,23 @@ import (
 
 type IKripkeStructure interface {
 	NewLabel(name string) ILabel
+	RemoveLabel(label ILabel)
 	NewState(name string, label ...ILabel) IState
 	GetStates() ISet[IState]
+	GetLabels() ISet[ILabel]
+	AddInitialStates(state ...IState)
+	GetInitialStates() ISet[IState]
+	SetAgents(agents ...string)
+	GetAgents() []string
 	Validate() bool
+	SetTracer(tracer ITracer)
+	GetTracer() ITracer
 	MakeTrueFormula() IFormula
 	MakeFalseFormula() IFormula
 	MakeNotFormula(formula IFormula) IFormula
 	MakeAndFormula(formula1 IFormula, formula2 IFormula) IFormula
 	MakeOrFormula(formula1 IFormula, formula2 IFormula) IFormula
+	MakeImpliesFormula(formula1 IFormula, formula2 IFormula) IFormula
 	MakeEXFormula(formula IFormula) IFormula
 	MakeEGFormula(formula IFormula) IFormula
 	MakeEFFormula(formula IFormula) IFormula
@@ -24,6 +33,26 @@ type IKripkeStructure interface {
 	MakeAFFormula(formula IFormula) IFormula
 	MakeAUFormula(formula1 IFormula, formula2 IFormula) IFormula
 	MakeARFormula(formula1 IFormula, formula2 IFormula) IFormula
+	MakeEYFormula(formula IFormula) IFormula
+	MakeEHFormula(formula IFormula) IFormula
+	MakeESFormula(formula1 IFormula, formula2 IFormula) IFormula
+	MakeAYFormula(formula IFormula) IFormula
+	MakeAHFormula(formula IFormula) IFormula
+	MakeASFormula(formula1 IFormula, formula2 IFormula) IFormula
+	MakeBoundedEUFormula(lower int, upper int, formula1 IFormula, formula2 IFormula) IFormula
+	MakeBoundedAUFormula(lower int, upper int, formula1 IFormula, formula2 IFormula) IFormula
+	MakeBoundedEFFormula(lower int, upper int, formula IFormula) IFormula
+	MakeBoundedAFFormula(lower int, upper int, formula IFormula) IFormula
+	MakeBoundedEGFormula(lower int, upper int, formula IFormula) IFormula
+	MakeBoundedAGFormula(lower int, upper int, formula IFormula) IFormula
+	MakeCostEFFormula(cost int, formula IFormula) IFormula
+	MakeCostAFFormula(cost int, formula IFormula) IFormula
+	MakeCostEGFormula(cost int, formula IFormula) IFormula
+	MakeCostAGFormula(cost int, formula IFormula) IFormula
+	MakeCoalitionXFormula(coalition []string, formula IFormula) IFormula
+	MakeCoalitionGFormula(coalition []string, formula IFormula) IFormula
+	MakeCoalitionFFormula(coalition []string, formula IFormula) IFormula
+	MakeCoalitionUFormula(coalition []string, formula1 IFormula, formula2 IFormula) IFormula
 	DetailString() string
 	String() string
 }
@@ -32,6 +61,30 @@ type KripkeStructure struct {
 	labels        ISet[ILabel]
 	states        ISet[IState]
 	initialStates ISet[IState]
+	agents        []string
+	tracer        ITracer
+}
+
+// ITracer is notified of every iteration of the fixpoint computations of EU and EG formulas.
+// Iteration 0 is the set the computation starts with, the last iteration equals the one before it.
+// The sets must not be modified.
+type ITracer interface {
+	Iteration(formula IFormula, i int, z ISet[IState])
+}
+
+// SetTracer installs a tracer for all formulas of this Kripke structure, nil disables tracing
+func (ks *KripkeStructure) SetTracer(tracer ITracer) {
+	ks.tracer = tracer
+}
+
+func (ks *KripkeStructure) GetTracer() ITracer {
+	return ks.tracer
+}
+
+func trace(formula IFormula, i int, z ISet[IState]) {
+	if tracer := formula.GetKripkeStructure().GetTracer(); tracer != nil {
+		tracer.Iteration(formula, i, z)
+	}
 }
 
 func (ks *KripkeStructure) NewLabel(name string) ILabel {
@@ -43,6 +96,14 @@ func (ks *KripkeStructure) NewLabel(name string) ILabel {
 	return label
 }
 
+// RemoveLabel removes the label from the Kripke structure and all of its states
+func (ks *KripkeStructure) RemoveLabel(label ILabel) {
+	ks.labels.Remove(label)
+	ks.states.ForEach(func(state IState) {
+		state.RemoveLabel(label)
+	})
+}
+
 func (ks *KripkeStructure) NewState(name string, label ...ILabel) IState {
 	state := &State{
 		kripkeStructure: ks,
@@ -62,6 +123,33 @@ func (ks *KripkeStructure) GetStates() ISet[IState] {
 	return ks.states
 }
 
+func (ks *KripkeStructure) GetLabels() ISet[ILabel] {
+	return ks.labels
+}
+
+func (ks *KripkeStructure) AddInitialStates(states ...IState) {
+	for _, state := range states {
+		ks.initialStates.Add(state)
+	}
+}
+
+// GetInitialStates returns all states if no initial states have been declared
+func (ks *KripkeStructure) GetInitialStates() ISet[IState] {
+	if ks.initialStates.Size() <= 0 {
+		return ks.states
+	}
+	return ks.initialStates
+}
+
+// SetAgents declares the agents of a concurrent game structure, whose moves give one action per agent in this order
+func (ks *KripkeStructure) SetAgents(agents ...string) {
+	ks.agents = agents
+}
+
+func (ks *KripkeStructure) GetAgents() []string {
+	return ks.agents
+}
+
 func (ks *KripkeStructure) Validate() bool {
 	result := true
 	ks.states.ForEach(func(state IState) {
@@ -76,6 +164,11 @@ func (ks *KripkeStructure) Validate() bool {
 			}
 		})
 	})
+	ks.initialStates.ForEach(func(state IState) {
+		if !ks.states.Contains(state) {
+			result = false
+		}
+	})
 	return result
 }
 
@@ -103,6 +196,10 @@ func (ks *KripkeStructure) MakeOrFormula(formula1 IFormula, formula2 IFormula) I
 	return &OrFormula{ks, formula1, formula2}
 }
 
+func (ks *KripkeStructure) MakeImpliesFormula(formula1 IFormula, formula2 IFormula) IFormula {
+	return &ImpliesFormula{ks, formula1, formula2, ks.MakeOrFormula(ks.MakeNotFormula(formula1), formula2)}
+}
+
 func (ks *KripkeStructure) MakeEXFormula(formula IFormula) IFormula {
 	return &EXFormula{ks, formula}
 }
@@ -143,6 +240,86 @@ func (ks *KripkeStructure) MakeARFormula(formula1 IFormula, formula2 IFormula) I
 	return &ARFormula{ks, formula1, formula2, ks.MakeNotFormula(ks.MakeEUFormula(ks.MakeNotFormula(formula1), ks.MakeNotFormula(formula2)))}
 }
 
+func (ks *KripkeStructure) MakeEYFormula(formula IFormula) IFormula {
+	return &EYFormula{ks, formula}
+}
+
+func (ks *KripkeStructure) MakeEHFormula(formula IFormula) IFormula {
+	return &EHFormula{ks, formula}
+}
+
+func (ks *KripkeStructure) MakeESFormula(formula1 IFormula, formula2 IFormula) IFormula {
+	return &ESFormula{ks, formula1, formula2}
+}
+
+func (ks *KripkeStructure) MakeAYFormula(formula IFormula) IFormula {
+	return &AYFormula{ks, formula, ks.MakeNotFormula(ks.MakeEYFormula(ks.MakeNotFormula(formula)))}
+}
+
+func (ks *KripkeStructure) MakeAHFormula(formula IFormula) IFormula {
+	return &AHFormula{ks, formula, ks.MakeNotFormula(ks.MakeESFormula(ks.MakeTrueFormula(), ks.MakeNotFormula(formula)))}
+}
+
+func (ks *KripkeStructure) MakeASFormula(formula1 IFormula, formula2 IFormula) IFormula {
+	return &ASFormula{ks, formula1, formula2, ks.MakeAndFormula(ks.MakeNotFormula(ks.MakeESFormula(ks.MakeNotFormula(formula2), ks.MakeAndFormula(ks.MakeNotFormula(formula1), ks.MakeNotFormula(formula2)))), ks.MakeNotFormula(ks.MakeEHFormula(ks.MakeNotFormula(formula2))))}
+}
+
+func (ks *KripkeStructure) MakeBoundedEUFormula(lower int, upper int, formula1 IFormula, formula2 IFormula) IFormula {
+	return &BoundedEUFormula{ks, Bound{lower, upper}, formula1, formula2}
+}
+
+func (ks *KripkeStructure) MakeBoundedAUFormula(lower int, upper int, formula1 IFormula, formula2 IFormula) IFormula {
+	return &BoundedAUFormula{ks, Bound{lower, upper}, formula1, formula2}
+}
+
+func (ks *KripkeStructure) MakeBoundedEFFormula(lower int, upper int, formula IFormula) IFormula {
+	return &BoundedEFFormula{ks, Bound{lower, upper}, formula, ks.MakeBoundedEUFormula(lower, upper, ks.MakeTrueFormula(), formula)}
+}
+
+func (ks *KripkeStructure) MakeBoundedAFFormula(lower int, upper int, formula IFormula) IFormula {
+	return &BoundedAFFormula{ks, Bound{lower, upper}, formula, ks.MakeBoundedAUFormula(lower, upper, ks.MakeTrueFormula(), formula)}
+}
+
+func (ks *KripkeStructure) MakeBoundedEGFormula(lower int, upper int, formula IFormula) IFormula {
+	return &BoundedEGFormula{ks, Bound{lower, upper}, formula, ks.MakeNotFormula(ks.MakeBoundedAFFormula(lower, upper, ks.MakeNotFormula(formula)))}
+}
+
+func (ks *KripkeStructure) MakeBoundedAGFormula(lower int, upper int, formula IFormula) IFormula {
+	return &BoundedAGFormula{ks, Bound{lower, upper}, formula, ks.MakeNotFormula(ks.MakeBoundedEFFormula(lower, upper, ks.MakeNotFormula(formula)))}
+}
+
+func (ks *KripkeStructure) MakeCostEFFormula(cost int, formula IFormula) IFormula {
+	return &CostEFFormula{ks, cost, formula}
+}
+
+func (ks *KripkeStructure) MakeCostAFFormula(cost int, formula IFormula) IFormula {
+	return &CostAFFormula{ks, cost, formula}
+}
+
+func (ks *KripkeStructure) MakeCostEGFormula(cost int, formula IFormula) IFormula {
+	return &CostEGFormula{ks, cost, formula, ks.MakeNotFormula(ks.MakeCostAFFormula(cost, ks.MakeNotFormula(formula)))}
+}
+
+func (ks *KripkeStructure) MakeCostAGFormula(cost int, formula IFormula) IFormula {
+	return &CostAGFormula{ks, cost, formula, ks.MakeNotFormula(ks.MakeCostEFFormula(cost, ks.MakeNotFormula(formula)))}
+}
+
+func (ks *KripkeStructure) MakeCoalitionXFormula(coalition []string, formula IFormula) IFormula {
+	return &CoalitionXFormula{ks, coalition, formula, nil}
+}
+
+func (ks *KripkeStructure) MakeCoalitionGFormula(coalition []string, formula IFormula) IFormula {
+	return &CoalitionGFormula{ks, coalition, formula, nil}
+}
+
+func (ks *KripkeStructure) MakeCoalitionFFormula(coalition []string, formula IFormula) IFormula {
+	return &CoalitionFFormula{ks, coalition, formula, ks.MakeCoalitionUFormula(coalition, ks.MakeTrueFormula(), formula)}
+}
+
+func (ks *KripkeStructure) MakeCoalitionUFormula(coalition []string, formula1 IFormula, formula2 IFormula) IFormula {
+	return &CoalitionUFormula{ks, coalition, formula1, formula2}
+}
+
 func (ks *KripkeStructure) DetailString() string {
 	result := "KripkeStructure:\n"
 	result += "  Labels:\n"
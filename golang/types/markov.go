@@ -0,0 +1,40 @@
+package cav
+
+import (
+	"fmt"
+	"math"
+)
+
+// ProbabilityTolerance is the deviation from 1 allowed for the sum of the probabilities of the transitions of a state
+const ProbabilityTolerance = 1e-9
+
+// IsMarkovChain reports whether any transition of the Kripke structure has been given a probability
+func IsMarkovChain(ks IKripkeStructure) bool {
+	found := false
+	ks.GetStates().ForEach(func(state IState) {
+		state.GetChildren().ForEach(func(child IState) {
+			if state.GetProbability(child) > 0 {
+				found = true
+			}
+		})
+	})
+	return found
+}
+
+// CheckMarkovChain returns an error unless every transition has a probability and those of each state sum to 1
+func CheckMarkovChain(ks IKripkeStructure) error {
+	for _, state := range SortedStates(ks.GetStates()) {
+		sum := 0.0
+		for _, child := range SortedStates(state.GetChildren()) {
+			probability := state.GetProbability(child)
+			if probability <= 0 {
+				return fmt.Errorf("transition from %s to %s has no probability", state.GetName(), child.GetName())
+			}
+			sum += probability
+		}
+		if math.Abs(sum-1) > ProbabilityTolerance {
+			return fmt.Errorf("probabilities of the transitions from %s sum to %g instead of 1", state.GetName(), sum)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package cav
+
+import (
+	"fmt"
+	"math"
+)
+
+// IsMarkovDecisionProcess reports whether any state of the Kripke structure has actions to choose from
+func IsMarkovDecisionProcess(ks IKripkeStructure) bool {
+	found := false
+	ks.GetStates().ForEach(func(state IState) {
+		if len(state.GetActions()) > 0 {
+			found = true
+		}
+	})
+	return found
+}
+
+// CheckMarkovDecisionProcess returns an error unless every state has an action, the distribution of every action
+// sums to 1 and every transition belongs to an action
+func CheckMarkovDecisionProcess(ks IKripkeStructure) error {
+	for _, state := range SortedStates(ks.GetStates()) {
+		actions := state.GetActions()
+		if len(actions) <= 0 {
+			return fmt.Errorf("state %s has no action", state.GetName())
+		}
+		covered := MakeSet[IState]()
+		for _, action := range actions {
+			choice := state.GetChoice(action)
+			support := MakeSet[IState]()
+			for child := range choice {
+				support.Add(child)
+			}
+			sum := 0.0
+			for _, child := range SortedStates(support) {
+				sum += choice[child]
+				covered.Add(child)
+			}
+			if math.Abs(sum-1) > ProbabilityTolerance {
+				return fmt.Errorf("probabilities of action %s in %s sum to %g instead of 1", action, state.GetName(), sum)
+			}
+		}
+		for _, child := range SortedStates(state.GetChildren().Minus(covered)) {
+			return fmt.Errorf("transition from %s to %s has no action", state.GetName(), child.GetName())
+		}
+	}
+	return nil
+}
@@ -1,10 +1,15 @@
 package cav
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
 
 type ISet[T comparable] interface {
 	Add(value T)
+	Remove(value T)
 	Contains(value T) bool
+	Size() int
 	ForEach(f func(T))
 	Copy() ISet[T]
 	Union(other ISet[T]) ISet[T]
@@ -20,11 +25,19 @@ func (s Set[T]) Add(value T) {
 	s[value] = struct{}{}
 }
 
+func (s Set[T]) Remove(value T) {
+	delete(s, value)
+}
+
 func (s Set[T]) Contains(value T) bool {
 	_, ok := s[value]
 	return ok
 }
 
+func (s Set[T]) Size() int {
+	return len(s)
+}
+
 func (s Set[T]) ForEach(f func(T)) {
 	for value := range s {
 		f(value)
@@ -86,16 +99,18 @@ func (s Set[T]) Equals(other ISet[T]) bool {
 }
 
 func (s Set[T]) String() string {
-	result := "{"
-	first := true
+	values := make([]string, 0, len(s))
 	s.ForEach(func(value T) {
-		if !first {
+		values = append(values, fmt.Sprint(value))
+	})
+	sort.Strings(values)
+	result := "{"
+	for i, value := range values {
+		if i > 0 {
 			result += ", "
-		} else {
-			first = false
 		}
-		result += fmt.Sprint(value)
-	})
+		result += value
+	}
 	result += "}"
 	return result
 }
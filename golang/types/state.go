@@ -1,14 +1,35 @@
 package cav
 
+import (
+	"sort"
+	"strings"
+)
+
 type IState interface {
 	GetKripkeStructure() IKripkeStructure
 	GetName() string
 	AddLabel(label ILabel)
+	RemoveLabel(label ILabel)
 	HasLabel(label ILabel) bool
 	GetLabels() ISet[ILabel]
 	AddChildren(child ...IState)
+	AddWeightedChild(child IState, weight int)
+	GetWeight(child IState) int
+	AddProbabilisticChild(child IState, probability float64)
+	GetProbability(child IState) float64
+	SetReward(reward float64)
+	GetReward() float64
+	SetTransitionReward(child IState, reward float64)
+	GetTransitionReward(child IState) float64
+	AddChoice(action string, child IState, probability float64)
+	GetActions() []string
+	GetChoice(action string) map[IState]float64
+	AddMove(move []string, child IState)
+	GetMoves() [][]string
+	GetSuccessor(move []string) IState
 	HasChild(child IState) bool
 	GetChildren() ISet[IState]
+	GetParents() ISet[IState]
 	DetailString() string
 	String() string
 }
@@ -19,6 +40,18 @@ type State struct {
 	labels          ISet[ILabel]
 	children        ISet[IState]
 	parents         ISet[IState]
+	weights         map[IState]int
+	probabilities   map[IState]float64
+	reward          float64
+	rewards         map[IState]float64
+	choices         map[string]map[IState]float64
+	moves           map[string]move
+}
+
+// move is a joint move of the agents of a concurrent game structure, one action per agent
+type move struct {
+	actions []string
+	child   IState
 }
 
 func (s *State) GetKripkeStructure() IKripkeStructure {
@@ -33,6 +66,10 @@ func (s *State) AddLabel(label ILabel) {
 	s.labels.Add(label)
 }
 
+func (s *State) RemoveLabel(label ILabel) {
+	s.labels.Remove(label)
+}
+
 func (s *State) HasLabel(label ILabel) bool {
 	return s.labels.Contains(label)
 }
@@ -52,6 +89,118 @@ func (s *State) AddChildren(children ...IState) {
 	}
 }
 
+// AddWeightedChild adds a transition to child which costs the given weight instead of 1
+func (s *State) AddWeightedChild(child IState, weight int) {
+	s.AddChildren(child)
+	if s.weights == nil {
+		s.weights = map[IState]int{}
+	}
+	s.weights[child] = weight
+}
+
+// GetWeight returns the cost of the transition to child, 1 unless a weight has been given
+func (s *State) GetWeight(child IState) int {
+	if weight, ok := s.weights[child]; ok {
+		return weight
+	}
+	return 1
+}
+
+// AddProbabilisticChild adds a transition to child which is taken with the given probability in a Markov chain
+func (s *State) AddProbabilisticChild(child IState, probability float64) {
+	s.AddChildren(child)
+	if s.probabilities == nil {
+		s.probabilities = map[IState]float64{}
+	}
+	s.probabilities[child] = probability
+}
+
+// GetProbability returns the probability of the transition to child, 0 unless one has been given
+func (s *State) GetProbability(child IState) float64 {
+	return s.probabilities[child]
+}
+
+// SetReward sets the reward earned for every step spent in the state
+func (s *State) SetReward(reward float64) {
+	s.reward = reward
+}
+
+func (s *State) GetReward() float64 {
+	return s.reward
+}
+
+// SetTransitionReward sets the reward earned when taking the transition to child
+func (s *State) SetTransitionReward(child IState, reward float64) {
+	if s.rewards == nil {
+		s.rewards = map[IState]float64{}
+	}
+	s.rewards[child] = reward
+}
+
+// GetTransitionReward returns the reward of the transition to child, 0 unless one has been given
+func (s *State) GetTransitionReward(child IState) float64 {
+	return s.rewards[child]
+}
+
+// AddChoice adds child with the given probability to the distribution of the action in a Markov decision process
+func (s *State) AddChoice(action string, child IState, probability float64) {
+	s.AddChildren(child)
+	if s.choices == nil {
+		s.choices = map[string]map[IState]float64{}
+	}
+	if s.choices[action] == nil {
+		s.choices[action] = map[IState]float64{}
+	}
+	s.choices[action][child] = probability
+}
+
+// GetActions returns the actions which can be chosen in the state, sorted by name
+func (s *State) GetActions() []string {
+	actions := make([]string, 0, len(s.choices))
+	for action := range s.choices {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// GetChoice returns the distribution over the successors of the action, nil if there is no such action
+func (s *State) GetChoice(action string) map[IState]float64 {
+	return s.choices[action]
+}
+
+// AddMove adds the transition to child taken when the agents of a concurrent game structure choose the actions of
+// the move, replacing the successor of the move if it already has one
+func (s *State) AddMove(actions []string, child IState) {
+	s.AddChildren(child)
+	if s.moves == nil {
+		s.moves = map[string]move{}
+	}
+	s.moves[strings.Join(actions, "\x00")] = move{append([]string{}, actions...), child}
+}
+
+// GetMoves returns the moves which can be chosen in the state, sorted by the actions of the agents in their order
+func (s *State) GetMoves() [][]string {
+	moves := make([][]string, 0, len(s.moves))
+	for _, m := range s.moves {
+		moves = append(moves, m.actions)
+	}
+	sort.Slice(moves, func(i, j int) bool {
+		for k := 0; k < len(moves[i]) && k < len(moves[j]); k++ {
+			if moves[i][k] != moves[j][k] {
+				return moves[i][k] < moves[j][k]
+			}
+		}
+		return len(moves[i]) < len(moves[j])
+	})
+	return moves
+}
+
+// GetSuccessor returns the state the move leads to, nil if there is no such move
+func (s *State) GetSuccessor(actions []string) IState {
+	return s.moves[strings.Join(actions, "\x00")].child
+}
+
 func (s *State) HasChild(child IState) bool {
 	return s.children.Contains(child)
 }
@@ -60,6 +209,10 @@ func (s *State) GetChildren() ISet[IState] {
 	return s.children
 }
 
+func (s *State) GetParents() ISet[IState] {
+	return s.parents
+}
+
 func (s *State) AddParent(parent IState) {
 	s.parents.Add(parent)
 }
@@ -81,3 +234,25 @@ func (s *State) DetailString() string {
 func (s *State) String() string {
 	return s.name
 }
+
+// SortedStates returns the given states ordered lexicographically by name, to iterate over them deterministically
+func SortedStates(states ISet[IState]) []IState {
+	result := make([]IState, 0, states.Size())
+	states.ForEach(func(state IState) {
+		result = append(result, state)
+	})
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].GetName() < result[j].GetName()
+	})
+	return result
+}
+
+// SortedNames returns the names of the given states in lexicographic order
+func SortedNames(states ISet[IState]) []string {
+	names := make([]string, 0, states.Size())
+	states.ForEach(func(state IState) {
+		names = append(names, state.GetName())
+	})
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"cav/golang/ast"
+	"cav/golang/types"
+	"cav/golang/vacuity"
+	"fmt"
+)
+
+func printVacuity(node *ast.Node, ks cav.IKripkeStructure) {
+	if node == nil {
+		return
+	}
+	occurrences, err := vacuity.Check(node, ks)
+	if err != nil {
+		fmt.Println("Vacuity check failed: " + err.Error())
+		return
+	}
+	if occurrences == nil {
+		// formulas which do not hold cannot be vacuous
+		return
+	}
+	if len(occurrences) <= 0 {
+		fmt.Println("Not vacuous")
+		return
+	}
+	for _, o := range occurrences {
+		fmt.Printf("Vacuous: %s at %s does not affect the result, %s holds as well\n", o.Node.String(), o.Node.Pos.String(), o.Formula.String())
+	}
+}
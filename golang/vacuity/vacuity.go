@@ -0,0 +1,81 @@
+package vacuity
+
+import (
+	"cav/golang/ast"
+	"cav/golang/types"
+)
+
+// Occurrence is an atomic subformula which does not affect whether the formula holds
+type Occurrence struct {
+	Node        *ast.Node // the label in the original formula, including its position
+	Replacement *ast.Node // false for positive and true for negative occurrences
+	Formula     *ast.Node // the formula with the occurrence replaced, which still holds
+}
+
+type occurrence struct {
+	node     *ast.Node
+	negative bool
+}
+
+// occurrences returns all labels of the formula together with their polarity.
+// All CTL operators are monotone, so only NOT and the left side of IMPLIES flip it.
+func occurrences(n *ast.Node, negative bool, result []occurrence) []occurrence {
+	switch n.Kind {
+	case ast.Label:
+		return append(result, occurrence{n, negative})
+	case ast.Not:
+		return occurrences(n.Operands[0], !negative, result)
+	case ast.Implies:
+		result = occurrences(n.Operands[0], !negative, result)
+		return occurrences(n.Operands[1], negative, result)
+	}
+	for _, operand := range n.Operands {
+		result = occurrences(operand, negative, result)
+	}
+	return result
+}
+
+// replace returns a copy of the formula in which the given node, compared by identity, is replaced
+func replace(n *ast.Node, target *ast.Node, replacement *ast.Node) *ast.Node {
+	if n == target {
+		return replacement
+	}
+	replaced := *n
+	replaced.Operands = make([]*ast.Node, len(n.Operands))
+	for i, operand := range n.Operands {
+		replaced.Operands[i] = replace(operand, target, replacement)
+	}
+	return &replaced
+}
+
+// Check returns the label occurrences of the formula which can be replaced by true or false,
+// according to their polarity, without the formula failing to hold in the initial states.
+// A formula which does not hold is never vacuous, so nil is returned for it.
+func Check(node *ast.Node, ks cav.IKripkeStructure) ([]Occurrence, error) {
+	formula, err := ast.Bind(node, ks)
+	if err != nil {
+		return nil, err
+	}
+	if !cav.Holds(formula) {
+		return nil, nil
+	}
+
+	result := make([]Occurrence, 0)
+	for _, o := range occurrences(node, false, nil) {
+		replacement := ast.MakeNode(ast.False)
+		if o.negative {
+			replacement = ast.MakeNode(ast.True)
+		}
+		replacement.Pos = o.node.Pos
+
+		replaced := replace(node, o.node, replacement)
+		formula, err := ast.Bind(replaced, ks)
+		if err != nil {
+			return nil, err
+		}
+		if cav.Holds(formula) {
+			result = append(result, Occurrence{o.node, replacement, replaced})
+		}
+	}
+	return result, nil
+}